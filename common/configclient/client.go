@@ -0,0 +1,337 @@
+package configclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// requestTimeout 从配置服务读取配置的超时时间
+const requestTimeout = 5 * time.Second
+
+// 订阅断开后重新连接的等待时间
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// Dialer 建立到配置服务的连接，与transport.Dialer相同
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Handler 配置项变化时调用，value为新值，返回错误表示新值未能生效
+type Handler func(value string) error
+
+// Client 配置服务客户端
+// 启动时通过GetConfig读取服务使用的配置项，之后通过WatchConfig订阅变更：
+// 注册了Handler的配置项立即生效，标记为需要重启的配置项只记录并提示，其余配置项只更新缓存的值
+type Client struct {
+	conn   *grpc.ClientConn
+	client config.ConfigServiceClient
+	keys   []string
+
+	mu       sync.RWMutex
+	values   map[string]string
+	version  string
+	handlers map[string]Handler
+	restart  map[string]bool
+	pending  map[string]bool // 已变化但需要重启才能生效的配置项
+}
+
+// Dial 创建连接配置服务的客户端，keys为服务使用的配置项
+// 连接是惰性建立的，配置服务暂时不可用时不会返回错误
+func Dial(addr string, dialer Dialer, keys ...string) (*Client, error) {
+	conn, err := grpc.NewClient("passthrough:///"+addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network(addr), addr)
+		}))
+	if err != nil {
+		return nil, err
+	}
+	return newClient(conn, config.NewConfigServiceClient(conn), keys), nil
+}
+
+// newClient 使用已有的配置服务客户端创建Client
+func newClient(conn *grpc.ClientConn, client config.ConfigServiceClient, keys []string) *Client {
+	return &Client{
+		conn:     conn,
+		client:   client,
+		keys:     keys,
+		values:   make(map[string]string),
+		handlers: make(map[string]Handler),
+		restart:  make(map[string]bool),
+		pending:  make(map[string]bool),
+	}
+}
+
+// network 根据地址格式选择拨号的网络类型，host:port为TCP，其余为套接字或命名管道路径
+func network(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return "tcp"
+	}
+	return "unix"
+}
+
+// Close 关闭与配置服务的连接
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Load 从配置服务读取配置项，失败时保留已有的值
+func (c *Client) Load(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	resp, err := c.client.GetConfig(ctx, &config.GetConfigRequest{Keys: c.keys})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, item := range resp.Configs {
+		c.values[key] = item.Value
+	}
+	c.version = resp.Version
+	return nil
+}
+
+// OnChange 注册配置项变化时的处理函数，配置项在运行时生效
+func (c *Client) OnChange(key string, handler Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[key] = handler
+}
+
+// RequireRestart 标记只在启动时读取的配置项，运行时变化后提示需要重启服务
+func (c *Client) RequireRestart(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.restart[key] = true
+	}
+}
+
+// PendingRestart 返回已变化但需要重启服务才能生效的配置项
+func (c *Client) PendingRestart() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.pending))
+	for key := range c.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Version 返回当前配置的版本
+func (c *Client) Version() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// Lookup 返回配置项的值，配置服务没有返回该配置项时ok为false
+func (c *Client) Lookup(key string) (value string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok = c.values[key]
+	return value, ok
+}
+
+// String 返回字符串配置项，未设置或为空时返回def
+func (c *Client) String(key, def string) string {
+	if value, ok := c.Lookup(key); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+// Int 返回整数配置项，未设置或为空时返回def
+func (c *Client) Int(key string, def int) (int, error) {
+	value, ok := c.Lookup(key)
+	if !ok || value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// Int64 返回64位整数配置项，未设置或为空时返回def
+func (c *Client) Int64(key string, def int64) (int64, error) {
+	value, ok := c.Lookup(key)
+	if !ok || value == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// Bool 返回布尔配置项，未设置或为空时返回def
+func (c *Client) Bool(key string, def bool) (bool, error) {
+	value, ok := c.Lookup(key)
+	if !ok || value == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// Duration 返回时长配置项，未设置或为空时返回def
+func (c *Client) Duration(key string, def time.Duration) (time.Duration, error) {
+	value, ok := c.Lookup(key)
+	if !ok || value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// Strings 返回以逗号分隔的列表配置项，未设置或为空时返回def
+func (c *Client) Strings(key string, def []string) []string {
+	value, ok := c.Lookup(key)
+	if !ok || value == "" {
+		return def
+	}
+	return SplitList(value)
+}
+
+// SplitList 拆分以逗号分隔的列表配置值，去掉空白和空元素
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Watch 订阅配置变更直到ctx结束，订阅断开后自动重新连接
+// 重新连接时重新读取配置，断开期间发生的变化同样会被处理
+func (c *Client) Watch(ctx context.Context) {
+	delay := minRetryDelay
+	for {
+		connected, err := c.watchOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = minRetryDelay
+		}
+		if err != nil {
+			log.Printf("Config subscription lost, retrying in %v: %v", delay, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// watchOnce 建立一次订阅，先订阅再重新读取配置，避免错过两者之间的变化
+// connected表示订阅建立成功，之后才断开
+func (c *Client) watchOnce(ctx context.Context) (connected bool, err error) {
+	stream, err := c.client.WatchConfig(ctx, &config.WatchConfigRequest{Keys: c.keys})
+	if err != nil {
+		return false, err
+	}
+	if err := c.resync(ctx); err != nil {
+		return false, err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return true, err
+		}
+		value := ""
+		if event.NewValue != nil {
+			value = event.NewValue.Value
+		}
+		c.apply(event.Key, value, event.Version)
+	}
+}
+
+// resync 重新读取配置并处理与缓存不同的配置项
+func (c *Client) resync(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	resp, err := c.client.GetConfig(ctx, &config.GetConfigRequest{Keys: c.keys})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(resp.Configs))
+	for key := range resp.Configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c.apply(key, resp.Configs[key].Value, resp.Version)
+	}
+	return nil
+}
+
+// apply 更新配置项的值，值有变化时调用处理函数或标记需要重启
+// 启动时未能读取的配置项第一次读取到时同样视为变化
+func (c *Client) apply(key, value, version string) {
+	c.mu.Lock()
+	old, known := c.values[key]
+	changed := !known || old != value
+	c.values[key] = value
+	if version != "" {
+		c.version = version
+	}
+	handler := c.handlers[key]
+	restart := c.restart[key]
+	if changed && restart {
+		c.pending[key] = true
+	}
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	switch {
+	case handler != nil:
+		if err := handler(value); err != nil {
+			log.Printf("Failed to apply config %s: %v", key, err)
+			return
+		}
+		log.Printf("Applied config %s (version %s)", key, version)
+	case restart:
+		log.Printf("Config %s changed (version %s), restart the service to apply it", key, version)
+	}
+}
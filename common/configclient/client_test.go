@@ -0,0 +1,145 @@
+package configclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"google.golang.org/grpc"
+)
+
+// fakeConfigService 内存中的配置服务
+type fakeConfigService struct {
+	config.ConfigServiceClient
+
+	mu      sync.Mutex
+	configs map[string]string
+	streams chan *fakeWatchStream
+}
+
+func (s *fakeConfigService) GetConfig(ctx context.Context, req *config.GetConfigRequest, _ ...grpc.CallOption) (*config.GetConfigResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &config.GetConfigResponse{Configs: make(map[string]*config.ConfigItem), Version: "1"}
+	for _, key := range req.Keys {
+		if value, ok := s.configs[key]; ok {
+			resp.Configs[key] = &config.ConfigItem{Key: key, Value: value}
+		}
+	}
+	return resp, nil
+}
+
+func (s *fakeConfigService) WatchConfig(ctx context.Context, req *config.WatchConfigRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[config.ConfigChangeEvent], error) {
+	stream := &fakeWatchStream{ctx: ctx, events: make(chan *config.ConfigChangeEvent, 4)}
+	s.streams <- stream
+	return stream, nil
+}
+
+// set 修改配置项，模拟配置服务在订阅断开期间发生的变化
+func (s *fakeConfigService) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs[key] = value
+}
+
+// fakeWatchStream 由测试发送变更事件的订阅流，关闭events模拟连接断开
+type fakeWatchStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	events chan *config.ConfigChangeEvent
+}
+
+func (s *fakeWatchStream) Recv() (*config.ConfigChangeEvent, error) {
+	select {
+	case event, ok := <-s.events:
+		if !ok {
+			return nil, io.EOF
+		}
+		return event, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+func TestClientWatch(t *testing.T) {
+	service := &fakeConfigService{
+		configs: map[string]string{"log.level": "info", "server.port": "8080", "jwt.expire_hours": "24"},
+		streams: make(chan *fakeWatchStream, 1),
+	}
+	client := newClient(nil, service, []string{"log.level", "server.port", "jwt.expire_hours"})
+	if err := client.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if port, err := client.Int("server.port", 0); err != nil || port != 8080 {
+		t.Fatalf("Int(server.port) = %d, %v, want 8080", port, err)
+	}
+
+	applied := make(chan string, 4)
+	client.OnChange("log.level", func(value string) error {
+		applied <- value
+		return nil
+	})
+	client.OnChange("jwt.expire_hours", func(value string) error {
+		return errors.New("rejected")
+	})
+	client.RequireRestart("server.port")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Watch(ctx)
+	nextStream := func() *fakeWatchStream {
+		t.Helper()
+		select {
+		case stream := <-service.streams:
+			return stream
+		case <-time.After(3 * time.Second):
+			t.Fatal("client did not subscribe")
+			return nil
+		}
+	}
+	expectApplied := func(want string) {
+		t.Helper()
+		select {
+		case value := <-applied:
+			if value != want {
+				t.Errorf("log.level applied %q, want %q", value, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("log.level %q was not applied", want)
+		}
+	}
+	change := func(stream *fakeWatchStream, key, value string) {
+		service.set(key, value)
+		stream.events <- &config.ConfigChangeEvent{Key: key, NewValue: &config.ConfigItem{Key: key, Value: value}, Version: "2"}
+	}
+
+	// 订阅时重新读取的配置没有变化，不调用处理函数
+	stream := nextStream()
+	change(stream, "log.level", "debug")
+	expectApplied("debug")
+	change(stream, "server.port", "9090")
+	change(stream, "jwt.expire_hours", "48")
+	change(stream, "log.level", "debug")
+
+	// 值没有变化的事件被忽略，处理函数返回错误时仍更新缓存的值
+	// 订阅断开期间的变化在重新订阅时处理
+	service.set("log.level", "warn")
+	close(stream.events)
+	nextStream()
+	expectApplied("warn")
+	if pending := client.PendingRestart(); len(pending) != 1 || pending[0] != "server.port" {
+		t.Errorf("PendingRestart = %v, want [server.port]", pending)
+	}
+	if hours, _ := client.Int("jwt.expire_hours", 0); hours != 48 {
+		t.Errorf("jwt.expire_hours = %d, want 48", hours)
+	}
+	select {
+	case value := <-applied:
+		t.Errorf("unexpected log.level change to %q", value)
+	default:
+	}
+}
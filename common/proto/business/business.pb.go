@@ -10,6 +10,7 @@ import (
 	common "github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -32,6 +33,10 @@ type Ledger struct {
 	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     string                 `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ArchivedAt    string                 `protobuf:"bytes,8,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`  // 归档时间，未归档为空
+	DeletedAt     string                 `protobuf:"bytes,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`     // 移入回收站的时间，仅回收站列表返回
+	PurgeAt       string                 `protobuf:"bytes,10,opt,name=purge_at,json=purgeAt,proto3" json:"purge_at,omitempty"`          // 回收站中将被永久删除的时间
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,11,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"` // 仅用于更新请求，指定要修改的字段，为空时修改全部可修改字段
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -115,6 +120,34 @@ func (x *Ledger) GetUpdatedAt() string {
 	return ""
 }
 
+func (x *Ledger) GetArchivedAt() string {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return ""
+}
+
+func (x *Ledger) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *Ledger) GetPurgeAt() string {
+	if x != nil {
+		return x.PurgeAt
+	}
+	return ""
+}
+
+func (x *Ledger) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
 // 交易消息
 type Transaction struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -132,6 +165,9 @@ type Transaction struct {
 	CreatedAt       string                 `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt       string                 `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	Tags            map[string]string      `protobuf:"bytes,14,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	UpdatedBy       string                 `protobuf:"bytes,15,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`    // 最后修改交易的成员用户ID，user_id为创建者
+	UpdateMask      *fieldmaskpb.FieldMask `protobuf:"bytes,16,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"` // 仅用于更新请求，指定要修改的字段，为空时修改全部可修改字段
+	Attachments     []*Attachment          `protobuf:"bytes,17,rep,name=attachments,proto3" json:"attachments,omitempty"`                 // 交易附件，只读，通过AttachFile/DetachFile修改
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -264,6 +300,120 @@ func (x *Transaction) GetTags() map[string]string {
 	return nil
 }
 
+func (x *Transaction) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *Transaction) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *Transaction) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+// 附件消息
+type Attachment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FileId         string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"` // 存储服务中的文件ID
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 上传者，即存储文件的所有者
+	Filename       string                 `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType    string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size           int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt      string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TransactionIds []string               `protobuf:"bytes,7,rep,name=transaction_ids,json=transactionIds,proto3" json:"transaction_ids,omitempty"` // 引用该文件的交易
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_business_business_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Attachment) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *Attachment) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Attachment) GetTransactionIds() []string {
+	if x != nil {
+		return x.TransactionIds
+	}
+	return nil
+}
+
 // 同步请求
 type SyncRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -278,7 +428,7 @@ type SyncRequest struct {
 
 func (x *SyncRequest) Reset() {
 	*x = SyncRequest{}
-	mi := &file_business_business_proto_msgTypes[2]
+	mi := &file_business_business_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -290,7 +440,7 @@ func (x *SyncRequest) String() string {
 func (*SyncRequest) ProtoMessage() {}
 
 func (x *SyncRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_business_business_proto_msgTypes[2]
+	mi := &file_business_business_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +453,7 @@ func (x *SyncRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
 func (*SyncRequest) Descriptor() ([]byte, []int) {
-	return file_business_business_proto_rawDescGZIP(), []int{2}
+	return file_business_business_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SyncRequest) GetUserId() string {
@@ -349,13 +499,14 @@ type SyncResponse struct {
 	Ledgers               []*Ledger              `protobuf:"bytes,3,rep,name=ledgers,proto3" json:"ledgers,omitempty"`
 	DeletedTransactionIds []string               `protobuf:"bytes,4,rep,name=deleted_transaction_ids,json=deletedTransactionIds,proto3" json:"deleted_transaction_ids,omitempty"`
 	DeletedLedgerIds      []string               `protobuf:"bytes,5,rep,name=deleted_ledger_ids,json=deletedLedgerIds,proto3" json:"deleted_ledger_ids,omitempty"`
+	Rejected              []*SyncRejection       `protobuf:"bytes,6,rep,name=rejected,proto3" json:"rejected,omitempty"` // 因权限等原因未应用的客户端记录，不影响其他记录的同步
 	unknownFields         protoimpl.UnknownFields
 	sizeCache             protoimpl.SizeCache
 }
 
 func (x *SyncResponse) Reset() {
 	*x = SyncResponse{}
-	mi := &file_business_business_proto_msgTypes[3]
+	mi := &file_business_business_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -367,7 +518,7 @@ func (x *SyncResponse) String() string {
 func (*SyncResponse) ProtoMessage() {}
 
 func (x *SyncResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_business_business_proto_msgTypes[3]
+	mi := &file_business_business_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -380,7 +531,7 @@ func (x *SyncResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
 func (*SyncResponse) Descriptor() ([]byte, []int) {
-	return file_business_business_proto_rawDescGZIP(), []int{3}
+	return file_business_business_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SyncResponse) GetSyncTime() int64 {
@@ -418,19 +569,96 @@ func (x *SyncResponse) GetDeletedLedgerIds() []string {
 	return nil
 }
 
-// 获取账本列表请求
-type GetLedgersRequest struct {
+func (x *SyncResponse) GetRejected() []*SyncRejection {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+// 同步中被拒绝的记录
+type SyncRejection struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`  // ledger或transaction
+	Code          int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"` // gRPC状态码
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *SyncRejection) Reset() {
+	*x = SyncRejection{}
+	mi := &file_business_business_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRejection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRejection) ProtoMessage() {}
+
+func (x *SyncRejection) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRejection.ProtoReflect.Descriptor instead.
+func (*SyncRejection) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SyncRejection) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SyncRejection) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *SyncRejection) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SyncRejection) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 获取账本列表请求
+type GetLedgersRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page            int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize        int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	IncludeArchived bool                   `protobuf:"varint,4,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"` // 是否包含已归档账本
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *GetLedgersRequest) Reset() {
 	*x = GetLedgersRequest{}
-	mi := &file_business_business_proto_msgTypes[4]
+	mi := &file_business_business_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -442,7 +670,7 @@ func (x *GetLedgersRequest) String() string {
 func (*GetLedgersRequest) ProtoMessage() {}
 
 func (x *GetLedgersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_business_business_proto_msgTypes[4]
+	mi := &file_business_business_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -455,7 +683,7 @@ func (x *GetLedgersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLedgersRequest.ProtoReflect.Descriptor instead.
 func (*GetLedgersRequest) Descriptor() ([]byte, []int) {
-	return file_business_business_proto_rawDescGZIP(), []int{4}
+	return file_business_business_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetLedgersRequest) GetUserId() string {
@@ -479,6 +707,13 @@ func (x *GetLedgersRequest) GetPageSize() int32 {
 	return 0
 }
 
+func (x *GetLedgersRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
 // 获取账本列表响应
 type GetLedgersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -492,7 +727,7 @@ type GetLedgersResponse struct {
 
 func (x *GetLedgersResponse) Reset() {
 	*x = GetLedgersResponse{}
-	mi := &file_business_business_proto_msgTypes[5]
+	mi := &file_business_business_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -504,7 +739,7 @@ func (x *GetLedgersResponse) String() string {
 func (*GetLedgersResponse) ProtoMessage() {}
 
 func (x *GetLedgersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_business_business_proto_msgTypes[5]
+	mi := &file_business_business_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -517,7 +752,7 @@ func (x *GetLedgersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLedgersResponse.ProtoReflect.Descriptor instead.
 func (*GetLedgersResponse) Descriptor() ([]byte, []int) {
-	return file_business_business_proto_rawDescGZIP(), []int{5}
+	return file_business_business_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetLedgersResponse) GetLedgers() []*Ledger {
@@ -548,75 +783,2740 @@ func (x *GetLedgersResponse) GetPageSize() int32 {
 	return 0
 }
 
-var File_business_business_proto protoreflect.FileDescriptor
+// 导入列映射
+// 每个字段填写源文件中的列名（有表头时）或从1开始的列序号
+type ImportColumnMapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Amount        string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Subcategory   string                 `protobuf:"bytes,5,opt,name=subcategory,proto3" json:"subcategory,omitempty"`
+	Account       string                 `protobuf:"bytes,6,opt,name=account,proto3" json:"account,omitempty"`
+	TargetAccount string                 `protobuf:"bytes,7,opt,name=target_account,json=targetAccount,proto3" json:"target_account,omitempty"`
+	Description   string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          string                 `protobuf:"bytes,9,opt,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_business_business_proto_rawDesc = "" +
-	"\n" +
-	"\x17business/business.proto\x12\bbeecount\x1a\x13common/common.proto\"\xc1\x01\n" +
-	"\x06Ledger\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x17\n" +
-	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x1a\n" +
-	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\a \x01(\tR\tupdatedAt\"\xf4\x03\n" +
-	"\vTransaction\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
-	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x12\n" +
-	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1f\n" +
-	"\vcategory_id\x18\x05 \x01(\tR\n" +
-	"categoryId\x12%\n" +
-	"\x0esubcategory_id\x18\x06 \x01(\tR\rsubcategoryId\x12\x1d\n" +
-	"\n" +
-	"account_id\x18\a \x01(\tR\taccountId\x12*\n" +
-	"\x11target_account_id\x18\b \x01(\tR\x0ftargetAccountId\x12\x16\n" +
-	"\x06amount\x18\t \x01(\tR\x06amount\x12 \n" +
-	"\vdescription\x18\n" +
-	" \x01(\tR\vdescription\x12\x12\n" +
-	"\x04date\x18\v \x01(\tR\x04date\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\f \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\r \x01(\tR\tupdatedAt\x123\n" +
-	"\x04tags\x18\x0e \x03(\v2\x1f.beecount.Transaction.TagsEntryR\x04tags\x1a7\n" +
-	"\tTagsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd0\x01\n" +
-	"\vSyncRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
-	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12$\n" +
-	"\x0elast_sync_time\x18\x03 \x01(\x03R\flastSyncTime\x129\n" +
-	"\ftransactions\x18\x04 \x03(\v2\x15.beecount.TransactionR\ftransactions\x12*\n" +
-	"\aledgers\x18\x05 \x03(\v2\x10.beecount.LedgerR\aledgers\"\xf8\x01\n" +
-	"\fSyncResponse\x12\x1b\n" +
-	"\tsync_time\x18\x01 \x01(\x03R\bsyncTime\x129\n" +
-	"\ftransactions\x18\x02 \x03(\v2\x15.beecount.TransactionR\ftransactions\x12*\n" +
-	"\aledgers\x18\x03 \x03(\v2\x10.beecount.LedgerR\aledgers\x126\n" +
-	"\x17deleted_transaction_ids\x18\x04 \x03(\tR\x15deletedTransactionIds\x12,\n" +
-	"\x12deleted_ledger_ids\x18\x05 \x03(\tR\x10deletedLedgerIds\"]\n" +
-	"\x11GetLedgersRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
-	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x87\x01\n" +
-	"\x12GetLedgersResponse\x12*\n" +
-	"\aledgers\x18\x01 \x03(\v2\x10.beecount.LedgerR\aledgers\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
-	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x04 \x01(\x05R\bpageSize2\xf1\x03\n" +
-	"\x0fBusinessService\x125\n" +
-	"\x04Sync\x12\x15.beecount.SyncRequest\x1a\x16.beecount.SyncResponse\x12G\n" +
-	"\n" +
-	"GetLedgers\x12\x1b.beecount.GetLedgersRequest\x1a\x1c.beecount.GetLedgersResponse\x122\n" +
-	"\fCreateLedger\x12\x10.beecount.Ledger\x1a\x10.beecount.Ledger\x122\n" +
-	"\fUpdateLedger\x12\x10.beecount.Ledger\x1a\x10.beecount.Ledger\x122\n" +
-	"\fDeleteLedger\x12\x10.beecount.Ledger\x1a\x10.common.Response\x12A\n" +
-	"\x11CreateTransaction\x12\x15.beecount.Transaction\x1a\x15.beecount.Transaction\x12A\n" +
-	"\x11UpdateTransaction\x12\x15.beecount.Transaction\x1a\x15.beecount.Transaction\x12<\n" +
-	"\x11DeleteTransaction\x12\x15.beecount.Transaction\x1a\x10.common.ResponseB>Z<github.com/fishdivinity/BeeCount-Cloud/common/proto/businessb\x06proto3"
+func (x *ImportColumnMapping) Reset() {
+	*x = ImportColumnMapping{}
+	mi := &file_business_business_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportColumnMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportColumnMapping) ProtoMessage() {}
+
+func (x *ImportColumnMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportColumnMapping.ProtoReflect.Descriptor instead.
+func (*ImportColumnMapping) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ImportColumnMapping) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetSubcategory() string {
+	if x != nil {
+		return x.Subcategory
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetTargetAccount() string {
+	if x != nil {
+		return x.TargetAccount
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ImportColumnMapping) GetTags() string {
+	if x != nil {
+		return x.Tags
+	}
+	return ""
+}
+
+// 导入交易请求
+type ImportTransactionsRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserId           string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId         string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	DeviceId         string                 `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Format           string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // csv, xlsx
+	Data             []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	Mapping          *ImportColumnMapping   `protobuf:"bytes,6,opt,name=mapping,proto3" json:"mapping,omitempty"`
+	NoHeader         bool                   `protobuf:"varint,7,opt,name=no_header,json=noHeader,proto3" json:"no_header,omitempty"`                                                                                                // 源文件第一行不是表头
+	Sheet            string                 `protobuf:"bytes,8,opt,name=sheet,proto3" json:"sheet,omitempty"`                                                                                                                       // xlsx工作表名称，为空则使用第一个工作表
+	DateFormat       string                 `protobuf:"bytes,9,opt,name=date_format,json=dateFormat,proto3" json:"date_format,omitempty"`                                                                                           // Go时间格式，为空则自动检测
+	CategoryMapping  map[string]string      `protobuf:"bytes,10,rep,name=category_mapping,json=categoryMapping,proto3" json:"category_mapping,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 分类名称 -> 分类ID
+	AccountMapping   map[string]string      `protobuf:"bytes,11,rep,name=account_mapping,json=accountMapping,proto3" json:"account_mapping,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`    // 账户名称 -> 账户ID
+	DefaultAccountId string                 `protobuf:"bytes,12,opt,name=default_account_id,json=defaultAccountId,proto3" json:"default_account_id,omitempty"`
+	DefaultType      string                 `protobuf:"bytes,13,opt,name=default_type,json=defaultType,proto3" json:"default_type,omitempty"` // 未映射类型列时使用，为空则按金额正负推断
+	DryRun           bool                   `protobuf:"varint,14,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`               // 仅预览，不写入数据库
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ImportTransactionsRequest) Reset() {
+	*x = ImportTransactionsRequest{}
+	mi := &file_business_business_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTransactionsRequest) ProtoMessage() {}
+
+func (x *ImportTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ImportTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ImportTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ImportTransactionsRequest) GetMapping() *ImportColumnMapping {
+	if x != nil {
+		return x.Mapping
+	}
+	return nil
+}
+
+func (x *ImportTransactionsRequest) GetNoHeader() bool {
+	if x != nil {
+		return x.NoHeader
+	}
+	return false
+}
+
+func (x *ImportTransactionsRequest) GetSheet() string {
+	if x != nil {
+		return x.Sheet
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetDateFormat() string {
+	if x != nil {
+		return x.DateFormat
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetCategoryMapping() map[string]string {
+	if x != nil {
+		return x.CategoryMapping
+	}
+	return nil
+}
+
+func (x *ImportTransactionsRequest) GetAccountMapping() map[string]string {
+	if x != nil {
+		return x.AccountMapping
+	}
+	return nil
+}
+
+func (x *ImportTransactionsRequest) GetDefaultAccountId() string {
+	if x != nil {
+		return x.DefaultAccountId
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetDefaultType() string {
+	if x != nil {
+		return x.DefaultType
+	}
+	return ""
+}
+
+func (x *ImportTransactionsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// 导入单行结果
+type ImportRowResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`      // 源文件中的行号（从1开始）
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // ok, duplicate, error
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,4,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRowResult) Reset() {
+	*x = ImportRowResult{}
+	mi := &file_business_business_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRowResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRowResult) ProtoMessage() {}
+
+func (x *ImportRowResult) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRowResult.ProtoReflect.Descriptor instead.
+func (*ImportRowResult) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ImportRowResult) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportRowResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ImportRowResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ImportRowResult) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+// 导入交易响应
+type ImportTransactionsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DryRun           bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	TotalRows        int32                  `protobuf:"varint,2,opt,name=total_rows,json=totalRows,proto3" json:"total_rows,omitempty"`
+	Imported         int32                  `protobuf:"varint,3,opt,name=imported,proto3" json:"imported,omitempty"` // 预览模式下为可导入的行数
+	Duplicates       int32                  `protobuf:"varint,4,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Failed           int32                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	DateFormat       string                 `protobuf:"bytes,6,opt,name=date_format,json=dateFormat,proto3" json:"date_format,omitempty"`                   // 实际使用的日期格式
+	DecimalSeparator string                 `protobuf:"bytes,7,opt,name=decimal_separator,json=decimalSeparator,proto3" json:"decimal_separator,omitempty"` // 实际使用的金额小数分隔符
+	Rows             []*ImportRowResult     `protobuf:"bytes,8,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ImportTransactionsResponse) Reset() {
+	*x = ImportTransactionsResponse{}
+	mi := &file_business_business_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTransactionsResponse) ProtoMessage() {}
+
+func (x *ImportTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ImportTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ImportTransactionsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportTransactionsResponse) GetTotalRows() int32 {
+	if x != nil {
+		return x.TotalRows
+	}
+	return 0
+}
+
+func (x *ImportTransactionsResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportTransactionsResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *ImportTransactionsResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ImportTransactionsResponse) GetDateFormat() string {
+	if x != nil {
+		return x.DateFormat
+	}
+	return ""
+}
+
+func (x *ImportTransactionsResponse) GetDecimalSeparator() string {
+	if x != nil {
+		return x.DecimalSeparator
+	}
+	return ""
+}
+
+func (x *ImportTransactionsResponse) GetRows() []*ImportRowResult {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+// 导出账本请求
+type ExportLedgerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`                        // csv, xlsx, json, qif, ofx
+	StartDate     string                 `protobuf:"bytes,4,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"` // 可选，YYYY-MM-DD，包含
+	EndDate       string                 `protobuf:"bytes,5,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`       // 可选，YYYY-MM-DD，包含
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportLedgerRequest) Reset() {
+	*x = ExportLedgerRequest{}
+	mi := &file_business_business_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportLedgerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLedgerRequest) ProtoMessage() {}
+
+func (x *ExportLedgerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLedgerRequest.ProtoReflect.Descriptor instead.
+func (*ExportLedgerRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ExportLedgerRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ExportLedgerRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *ExportLedgerRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ExportLedgerRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *ExportLedgerRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+// 导出账本响应（流式）
+// 第一条消息携带文件名、类型和交易数量，后续消息携带文件内容
+type ExportLedgerResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Filename         string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType      string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	TransactionCount int64                  `protobuf:"varint,3,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	Chunk            []byte                 `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	IsLastChunk      bool                   `protobuf:"varint,5,opt,name=is_last_chunk,json=isLastChunk,proto3" json:"is_last_chunk,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExportLedgerResponse) Reset() {
+	*x = ExportLedgerResponse{}
+	mi := &file_business_business_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportLedgerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLedgerResponse) ProtoMessage() {}
+
+func (x *ExportLedgerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLedgerResponse.ProtoReflect.Descriptor instead.
+func (*ExportLedgerResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ExportLedgerResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportLedgerResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportLedgerResponse) GetTransactionCount() int64 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *ExportLedgerResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *ExportLedgerResponse) GetIsLastChunk() bool {
+	if x != nil {
+		return x.IsLastChunk
+	}
+	return false
+}
+
+// 搜索交易请求
+type SearchTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`                                // 全文检索关键词，匹配描述和标签
+	LedgerId      string                 `protobuf:"bytes,3,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`          // 可选，为空则搜索用户的全部账本
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`                                  // 可选，income, expense, transfer
+	CategoryIds   []string               `protobuf:"bytes,5,rep,name=category_ids,json=categoryIds,proto3" json:"category_ids,omitempty"` // 可选，匹配分类或子分类
+	MinAmount     string                 `protobuf:"bytes,6,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`       // 可选，包含
+	MaxAmount     string                 `protobuf:"bytes,7,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`       // 可选，包含
+	StartDate     string                 `protobuf:"bytes,8,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`       // 可选，YYYY-MM-DD，包含
+	EndDate       string                 `protobuf:"bytes,9,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`             // 可选，YYYY-MM-DD，包含
+	Page          int32                  `protobuf:"varint,10,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,11,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTransactionsRequest) Reset() {
+	*x = SearchTransactionsRequest{}
+	mi := &file_business_business_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTransactionsRequest) ProtoMessage() {}
+
+func (x *SearchTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*SearchTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetCategoryIds() []string {
+	if x != nil {
+		return x.CategoryIds
+	}
+	return nil
+}
+
+func (x *SearchTransactionsRequest) GetMinAmount() string {
+	if x != nil {
+		return x.MinAmount
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetMaxAmount() string {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *SearchTransactionsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchTransactionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// 搜索交易响应
+type SearchTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTransactionsResponse) Reset() {
+	*x = SearchTransactionsResponse{}
+	mi := &file_business_business_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTransactionsResponse) ProtoMessage() {}
+
+func (x *SearchTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*SearchTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SearchTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *SearchTransactionsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchTransactionsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchTransactionsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// 账本成员
+type LedgerMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LedgerId      string                 `protobuf:"bytes,1,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`     // owner, editor, viewer
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // pending, active, removed
+	InvitedBy     string                 `protobuf:"bytes,5,opt,name=invited_by,json=invitedBy,proto3" json:"invited_by,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	LedgerName    string                 `protobuf:"bytes,8,opt,name=ledger_name,json=ledgerName,proto3" json:"ledger_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerMember) Reset() {
+	*x = LedgerMember{}
+	mi := &file_business_business_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerMember) ProtoMessage() {}
+
+func (x *LedgerMember) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerMember.ProtoReflect.Descriptor instead.
+func (*LedgerMember) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LedgerMember) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetInvitedBy() string {
+	if x != nil {
+		return x.InvitedBy
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *LedgerMember) GetLedgerName() string {
+	if x != nil {
+		return x.LedgerName
+	}
+	return ""
+}
+
+// 邀请成员请求
+type InviteMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 发起邀请的用户（必须是账本所有者）
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	MemberUserId  string                 `protobuf:"bytes,3,opt,name=member_user_id,json=memberUserId,proto3" json:"member_user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"` // editor, viewer
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InviteMemberRequest) Reset() {
+	*x = InviteMemberRequest{}
+	mi := &file_business_business_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InviteMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InviteMemberRequest) ProtoMessage() {}
+
+func (x *InviteMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InviteMemberRequest.ProtoReflect.Descriptor instead.
+func (*InviteMemberRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *InviteMemberRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *InviteMemberRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *InviteMemberRequest) GetMemberUserId() string {
+	if x != nil {
+		return x.MemberUserId
+	}
+	return ""
+}
+
+func (x *InviteMemberRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// 接受邀请请求
+type AcceptInvitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptInvitationRequest) Reset() {
+	*x = AcceptInvitationRequest{}
+	mi := &file_business_business_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptInvitationRequest) ProtoMessage() {}
+
+func (x *AcceptInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptInvitationRequest.ProtoReflect.Descriptor instead.
+func (*AcceptInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AcceptInvitationRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AcceptInvitationRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+// 移除成员请求
+type RemoveMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 执行移除的用户（必须是账本所有者）
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	MemberUserId  string                 `protobuf:"bytes,3,opt,name=member_user_id,json=memberUserId,proto3" json:"member_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMemberRequest) Reset() {
+	*x = RemoveMemberRequest{}
+	mi := &file_business_business_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMemberRequest) ProtoMessage() {}
+
+func (x *RemoveMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMemberRequest.ProtoReflect.Descriptor instead.
+func (*RemoveMemberRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RemoveMemberRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RemoveMemberRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *RemoveMemberRequest) GetMemberUserId() string {
+	if x != nil {
+		return x.MemberUserId
+	}
+	return ""
+}
+
+// 退出账本请求（待接受的邀请也通过此接口拒绝）
+type LeaveLedgerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveLedgerRequest) Reset() {
+	*x = LeaveLedgerRequest{}
+	mi := &file_business_business_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveLedgerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveLedgerRequest) ProtoMessage() {}
+
+func (x *LeaveLedgerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveLedgerRequest.ProtoReflect.Descriptor instead.
+func (*LeaveLedgerRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LeaveLedgerRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LeaveLedgerRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+// 获取账本成员请求
+type ListMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersRequest) Reset() {
+	*x = ListMembersRequest{}
+	mi := &file_business_business_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersRequest) ProtoMessage() {}
+
+func (x *ListMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListMembersRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListMembersRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListMembersRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+// 获取账本成员响应
+type ListMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*LedgerMember        `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersResponse) Reset() {
+	*x = ListMembersResponse{}
+	mi := &file_business_business_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersResponse) ProtoMessage() {}
+
+func (x *ListMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListMembersResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListMembersResponse) GetMembers() []*LedgerMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// 获取待接受邀请请求
+type ListInvitationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvitationsRequest) Reset() {
+	*x = ListInvitationsRequest{}
+	mi := &file_business_business_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvitationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvitationsRequest) ProtoMessage() {}
+
+func (x *ListInvitationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvitationsRequest.ProtoReflect.Descriptor instead.
+func (*ListInvitationsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListInvitationsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 获取待接受邀请响应
+type ListInvitationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitations   []*LedgerMember        `protobuf:"bytes,1,rep,name=invitations,proto3" json:"invitations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvitationsResponse) Reset() {
+	*x = ListInvitationsResponse{}
+	mi := &file_business_business_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvitationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvitationsResponse) ProtoMessage() {}
+
+func (x *ListInvitationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvitationsResponse.ProtoReflect.Descriptor instead.
+func (*ListInvitationsResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListInvitationsResponse) GetInvitations() []*LedgerMember {
+	if x != nil {
+		return x.Invitations
+	}
+	return nil
+}
+
+// 变更记录
+type ChangeRevision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EntityType    string                 `protobuf:"bytes,2,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // ledger, transaction
+	EntityId      string                 `protobuf:"bytes,3,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,4,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	Revision      int32                  `protobuf:"varint,5,opt,name=revision,proto3" json:"revision,omitempty"`  // 记录内从1开始递增的版本号
+	Operation     string                 `protobuf:"bytes,6,opt,name=operation,proto3" json:"operation,omitempty"` // create, update, delete, restore
+	ActorId       string                 `protobuf:"bytes,7,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,8,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Before        string                 `protobuf:"bytes,9,opt,name=before,proto3" json:"before,omitempty"` // 变更前的JSON快照，创建时为空
+	After         string                 `protobuf:"bytes,10,opt,name=after,proto3" json:"after,omitempty"`  // 变更后的JSON快照，删除时为空
+	CreatedAt     string                 `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeRevision) Reset() {
+	*x = ChangeRevision{}
+	mi := &file_business_business_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeRevision) ProtoMessage() {}
+
+func (x *ChangeRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeRevision.ProtoReflect.Descriptor instead.
+func (*ChangeRevision) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ChangeRevision) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ChangeRevision) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *ChangeRevision) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetActorId() string {
+	if x != nil {
+		return x.ActorId
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *ChangeRevision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// 获取变更历史请求
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	EntityType    string                 `protobuf:"bytes,2,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // ledger, transaction
+	EntityId      string                 `protobuf:"bytes,3,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Page          int32                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_business_business_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetHistoryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// 获取变更历史响应
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*ChangeRevision      `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"` // 按版本号倒序
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_business_business_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetHistoryResponse) GetRevisions() []*ChangeRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetHistoryResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetHistoryResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// 恢复版本请求
+// 将记录恢复为指定版本变更后的状态；撤销某次修改时恢复其上一个版本
+type RestoreRevisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	EntityType    string                 `protobuf:"bytes,3,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // ledger, transaction
+	EntityId      string                 `protobuf:"bytes,4,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Revision      int32                  `protobuf:"varint,5,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRevisionRequest) Reset() {
+	*x = RestoreRevisionRequest{}
+	mi := &file_business_business_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRevisionRequest) ProtoMessage() {}
+
+func (x *RestoreRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRevisionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestoreRevisionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// 账本操作请求（归档、取消归档、从回收站恢复、永久删除）
+type LedgerActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LedgerId      string                 `protobuf:"bytes,2,opt,name=ledger_id,json=ledgerId,proto3" json:"ledger_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerActionRequest) Reset() {
+	*x = LedgerActionRequest{}
+	mi := &file_business_business_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerActionRequest) ProtoMessage() {}
+
+func (x *LedgerActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerActionRequest.ProtoReflect.Descriptor instead.
+func (*LedgerActionRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LedgerActionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LedgerActionRequest) GetLedgerId() string {
+	if x != nil {
+		return x.LedgerId
+	}
+	return ""
+}
+
+// 获取回收站请求
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_business_business_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListTrashRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 获取回收站响应
+type ListTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ledgers       []*Ledger              `protobuf:"bytes,1,rep,name=ledgers,proto3" json:"ledgers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_business_business_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashResponse) ProtoMessage() {}
+
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListTrashResponse) GetLedgers() []*Ledger {
+	if x != nil {
+		return x.Ledgers
+	}
+	return nil
+}
+
+// 批量创建交易请求
+type BatchCreateTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Transactions  []*Transaction         `protobuf:"bytes,3,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateTransactionsRequest) Reset() {
+	*x = BatchCreateTransactionsRequest{}
+	mi := &file_business_business_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateTransactionsRequest) ProtoMessage() {}
+
+func (x *BatchCreateTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BatchCreateTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchCreateTransactionsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *BatchCreateTransactionsRequest) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+// 批量更新中的单条交易更新
+type TransactionUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`                 // 必须包含id
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"` // 为空时更新全部可修改字段
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionUpdate) Reset() {
+	*x = TransactionUpdate{}
+	mi := &file_business_business_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionUpdate) ProtoMessage() {}
+
+func (x *TransactionUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionUpdate.ProtoReflect.Descriptor instead.
+func (*TransactionUpdate) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TransactionUpdate) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *TransactionUpdate) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+// 批量更新交易请求
+type BatchUpdateTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Updates       []*TransactionUpdate   `protobuf:"bytes,3,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpdateTransactionsRequest) Reset() {
+	*x = BatchUpdateTransactionsRequest{}
+	mi := &file_business_business_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpdateTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpdateTransactionsRequest) ProtoMessage() {}
+
+func (x *BatchUpdateTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpdateTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchUpdateTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *BatchUpdateTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchUpdateTransactionsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *BatchUpdateTransactionsRequest) GetUpdates() []*TransactionUpdate {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// 批量删除交易请求
+type BatchDeleteTransactionsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId       string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	TransactionIds []string               `protobuf:"bytes,3,rep,name=transaction_ids,json=transactionIds,proto3" json:"transaction_ids,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BatchDeleteTransactionsRequest) Reset() {
+	*x = BatchDeleteTransactionsRequest{}
+	mi := &file_business_business_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteTransactionsRequest) ProtoMessage() {}
+
+func (x *BatchDeleteTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *BatchDeleteTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchDeleteTransactionsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *BatchDeleteTransactionsRequest) GetTransactionIds() []string {
+	if x != nil {
+		return x.TransactionIds
+	}
+	return nil
+}
+
+// 批量操作中单条记录的结果
+type BatchTransactionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // 在请求中的位置，从0开始
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Code          int32                  `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"` // 失败时的gRPC状态码
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,6,opt,name=transaction,proto3" json:"transaction,omitempty"` // 创建或更新成功时返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchTransactionResult) Reset() {
+	*x = BatchTransactionResult{}
+	mi := &file_business_business_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchTransactionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchTransactionResult) ProtoMessage() {}
+
+func (x *BatchTransactionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchTransactionResult.ProtoReflect.Descriptor instead.
+func (*BatchTransactionResult) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BatchTransactionResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchTransactionResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BatchTransactionResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BatchTransactionResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchTransactionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BatchTransactionResult) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+// 批量操作交易响应
+// 批量操作在一个数据库事务中执行，任一记录失败时全部回滚，committed为false
+type BatchTransactionsResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Committed     bool                      `protobuf:"varint,1,opt,name=committed,proto3" json:"committed,omitempty"`
+	Succeeded     int32                     `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int32                     `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	Results       []*BatchTransactionResult `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchTransactionsResponse) Reset() {
+	*x = BatchTransactionsResponse{}
+	mi := &file_business_business_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchTransactionsResponse) ProtoMessage() {}
+
+func (x *BatchTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*BatchTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *BatchTransactionsResponse) GetCommitted() bool {
+	if x != nil {
+		return x.Committed
+	}
+	return false
+}
+
+func (x *BatchTransactionsResponse) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *BatchTransactionsResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *BatchTransactionsResponse) GetResults() []*BatchTransactionResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// 关联附件请求
+// transaction_id为空时只登记文件，未被任何交易引用的文件会在保留期后被清理
+type AttachFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,4,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,5,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,6,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachFileRequest) Reset() {
+	*x = AttachFileRequest{}
+	mi := &file_business_business_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachFileRequest) ProtoMessage() {}
+
+func (x *AttachFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachFileRequest.ProtoReflect.Descriptor instead.
+func (*AttachFileRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AttachFileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *AttachFileRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// 取消关联附件请求
+type DetachFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,4,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetachFileRequest) Reset() {
+	*x = DetachFileRequest{}
+	mi := &file_business_business_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetachFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetachFileRequest) ProtoMessage() {}
+
+func (x *DetachFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetachFileRequest.ProtoReflect.Descriptor instead.
+func (*DetachFileRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *DetachFileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DetachFileRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DetachFileRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *DetachFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+// 附件请求
+type AttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentRequest) Reset() {
+	*x = AttachmentRequest{}
+	mi := &file_business_business_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentRequest) ProtoMessage() {}
+
+func (x *AttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_business_business_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentRequest.ProtoReflect.Descriptor instead.
+func (*AttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_business_business_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *AttachmentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AttachmentRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+var File_business_business_proto protoreflect.FileDescriptor
+
+const file_business_business_proto_rawDesc = "" +
+	"\n" +
+	"\x17business/business.proto\x12\bbeecount\x1a\x13common/common.proto\x1a google/protobuf/field_mask.proto\"\xd9\x02\n" +
+	"\x06Ledger\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\varchived_at\x18\b \x01(\tR\n" +
+	"archivedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\t \x01(\tR\tdeletedAt\x12\x19\n" +
+	"\bpurge_at\x18\n" +
+	" \x01(\tR\apurgeAt\x12;\n" +
+	"\vupdate_mask\x18\v \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"\x88\x05\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1f\n" +
+	"\vcategory_id\x18\x05 \x01(\tR\n" +
+	"categoryId\x12%\n" +
+	"\x0esubcategory_id\x18\x06 \x01(\tR\rsubcategoryId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\a \x01(\tR\taccountId\x12*\n" +
+	"\x11target_account_id\x18\b \x01(\tR\x0ftargetAccountId\x12\x16\n" +
+	"\x06amount\x18\t \x01(\tR\x06amount\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12\x12\n" +
+	"\x04date\x18\v \x01(\tR\x04date\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\tR\tupdatedAt\x123\n" +
+	"\x04tags\x18\x0e \x03(\v2\x1f.beecount.Transaction.TagsEntryR\x04tags\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x0f \x01(\tR\tupdatedBy\x12;\n" +
+	"\vupdate_mask\x18\x10 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x126\n" +
+	"\vattachments\x18\x11 \x03(\v2\x14.beecount.AttachmentR\vattachments\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd9\x01\n" +
+	"\n" +
+	"Attachment\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bfilename\x18\x03 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12'\n" +
+	"\x0ftransaction_ids\x18\a \x03(\tR\x0etransactionIds\"\xd0\x01\n" +
+	"\vSyncRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12$\n" +
+	"\x0elast_sync_time\x18\x03 \x01(\x03R\flastSyncTime\x129\n" +
+	"\ftransactions\x18\x04 \x03(\v2\x15.beecount.TransactionR\ftransactions\x12*\n" +
+	"\aledgers\x18\x05 \x03(\v2\x10.beecount.LedgerR\aledgers\"\xad\x02\n" +
+	"\fSyncResponse\x12\x1b\n" +
+	"\tsync_time\x18\x01 \x01(\x03R\bsyncTime\x129\n" +
+	"\ftransactions\x18\x02 \x03(\v2\x15.beecount.TransactionR\ftransactions\x12*\n" +
+	"\aledgers\x18\x03 \x03(\v2\x10.beecount.LedgerR\aledgers\x126\n" +
+	"\x17deleted_transaction_ids\x18\x04 \x03(\tR\x15deletedTransactionIds\x12,\n" +
+	"\x12deleted_ledger_ids\x18\x05 \x03(\tR\x10deletedLedgerIds\x123\n" +
+	"\brejected\x18\x06 \x03(\v2\x17.beecount.SyncRejectionR\brejected\"]\n" +
+	"\rSyncRejection\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x88\x01\n" +
+	"\x11GetLedgersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12)\n" +
+	"\x10include_archived\x18\x04 \x01(\bR\x0fincludeArchived\"\x87\x01\n" +
+	"\x12GetLedgersResponse\x12*\n" +
+	"\aledgers\x18\x01 \x03(\v2\x10.beecount.LedgerR\aledgers\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\x8a\x02\n" +
+	"\x13ImportColumnMapping\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12 \n" +
+	"\vsubcategory\x18\x05 \x01(\tR\vsubcategory\x12\x18\n" +
+	"\aaccount\x18\x06 \x01(\tR\aaccount\x12%\n" +
+	"\x0etarget_account\x18\a \x01(\tR\rtargetAccount\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\t \x01(\tR\x04tags\"\xdf\x05\n" +
+	"\x19ImportTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12\x1b\n" +
+	"\tdevice_id\x18\x03 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\fR\x04data\x127\n" +
+	"\amapping\x18\x06 \x01(\v2\x1d.beecount.ImportColumnMappingR\amapping\x12\x1b\n" +
+	"\tno_header\x18\a \x01(\bR\bnoHeader\x12\x14\n" +
+	"\x05sheet\x18\b \x01(\tR\x05sheet\x12\x1f\n" +
+	"\vdate_format\x18\t \x01(\tR\n" +
+	"dateFormat\x12c\n" +
+	"\x10category_mapping\x18\n" +
+	" \x03(\v28.beecount.ImportTransactionsRequest.CategoryMappingEntryR\x0fcategoryMapping\x12`\n" +
+	"\x0faccount_mapping\x18\v \x03(\v27.beecount.ImportTransactionsRequest.AccountMappingEntryR\x0eaccountMapping\x12,\n" +
+	"\x12default_account_id\x18\f \x01(\tR\x10defaultAccountId\x12!\n" +
+	"\fdefault_type\x18\r \x01(\tR\vdefaultType\x12\x17\n" +
+	"\adry_run\x18\x0e \x01(\bR\x06dryRun\x1aB\n" +
+	"\x14CategoryMappingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aA\n" +
+	"\x13AccountMappingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x01\n" +
+	"\x0fImportRowResult\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x127\n" +
+	"\vtransaction\x18\x04 \x01(\v2\x15.beecount.TransactionR\vtransaction\"\xa5\x02\n" +
+	"\x1aImportTransactionsResponse\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12\x1d\n" +
+	"\n" +
+	"total_rows\x18\x02 \x01(\x05R\ttotalRows\x12\x1a\n" +
+	"\bimported\x18\x03 \x01(\x05R\bimported\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x04 \x01(\x05R\n" +
+	"duplicates\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12\x1f\n" +
+	"\vdate_format\x18\x06 \x01(\tR\n" +
+	"dateFormat\x12+\n" +
+	"\x11decimal_separator\x18\a \x01(\tR\x10decimalSeparator\x12-\n" +
+	"\x04rows\x18\b \x03(\v2\x19.beecount.ImportRowResultR\x04rows\"\x9d\x01\n" +
+	"\x13ExportLedgerRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x04 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x05 \x01(\tR\aendDate\"\xbc\x01\n" +
+	"\x14ExportLedgerResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12+\n" +
+	"\x11transaction_count\x18\x03 \x01(\x03R\x10transactionCount\x12\x14\n" +
+	"\x05chunk\x18\x04 \x01(\fR\x05chunk\x12\"\n" +
+	"\ris_last_chunk\x18\x05 \x01(\bR\visLastChunk\"\xc7\x02\n" +
+	"\x19SearchTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1b\n" +
+	"\tledger_id\x18\x03 \x01(\tR\bledgerId\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12!\n" +
+	"\fcategory_ids\x18\x05 \x03(\tR\vcategoryIds\x12\x1d\n" +
+	"\n" +
+	"min_amount\x18\x06 \x01(\tR\tminAmount\x12\x1d\n" +
+	"\n" +
+	"max_amount\x18\a \x01(\tR\tmaxAmount\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\b \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\t \x01(\tR\aendDate\x12\x12\n" +
+	"\x04page\x18\n" +
+	" \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\v \x01(\x05R\bpageSize\"\x9e\x01\n" +
+	"\x1aSearchTransactionsResponse\x129\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x15.beecount.TransactionR\ftransactions\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xee\x01\n" +
+	"\fLedgerMember\x12\x1b\n" +
+	"\tledger_id\x18\x01 \x01(\tR\bledgerId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"invited_by\x18\x05 \x01(\tR\tinvitedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\vledger_name\x18\b \x01(\tR\n" +
+	"ledgerName\"\x85\x01\n" +
+	"\x13InviteMemberRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12$\n" +
+	"\x0emember_user_id\x18\x03 \x01(\tR\fmemberUserId\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\"O\n" +
+	"\x17AcceptInvitationRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\"q\n" +
+	"\x13RemoveMemberRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\x12$\n" +
+	"\x0emember_user_id\x18\x03 \x01(\tR\fmemberUserId\"J\n" +
+	"\x12LeaveLedgerRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\"J\n" +
+	"\x12ListMembersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\"G\n" +
+	"\x13ListMembersResponse\x120\n" +
+	"\amembers\x18\x01 \x03(\v2\x16.beecount.LedgerMemberR\amembers\"1\n" +
+	"\x16ListInvitationsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"S\n" +
+	"\x17ListInvitationsResponse\x128\n" +
+	"\vinvitations\x18\x01 \x03(\v2\x16.beecount.LedgerMemberR\vinvitations\"\xba\x02\n" +
+	"\x0eChangeRevision\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\ventity_type\x18\x02 \x01(\tR\n" +
+	"entityType\x12\x1b\n" +
+	"\tentity_id\x18\x03 \x01(\tR\bentityId\x12\x1b\n" +
+	"\tledger_id\x18\x04 \x01(\tR\bledgerId\x12\x1a\n" +
+	"\brevision\x18\x05 \x01(\x05R\brevision\x12\x1c\n" +
+	"\toperation\x18\x06 \x01(\tR\toperation\x12\x19\n" +
+	"\bactor_id\x18\a \x01(\tR\aactorId\x12\x1b\n" +
+	"\tdevice_id\x18\b \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06before\x18\t \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\n" +
+	" \x01(\tR\x05after\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\"\x9b\x01\n" +
+	"\x11GetHistoryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\ventity_type\x18\x02 \x01(\tR\n" +
+	"entityType\x12\x1b\n" +
+	"\tentity_id\x18\x03 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\"\x93\x01\n" +
+	"\x12GetHistoryResponse\x126\n" +
+	"\trevisions\x18\x01 \x03(\v2\x18.beecount.ChangeRevisionR\trevisions\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xa8\x01\n" +
+	"\x16RestoreRevisionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\ventity_type\x18\x03 \x01(\tR\n" +
+	"entityType\x12\x1b\n" +
+	"\tentity_id\x18\x04 \x01(\tR\bentityId\x12\x1a\n" +
+	"\brevision\x18\x05 \x01(\x05R\brevision\"K\n" +
+	"\x13LedgerActionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tledger_id\x18\x02 \x01(\tR\bledgerId\"+\n" +
+	"\x10ListTrashRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"?\n" +
+	"\x11ListTrashResponse\x12*\n" +
+	"\aledgers\x18\x01 \x03(\v2\x10.beecount.LedgerR\aledgers\"\x91\x01\n" +
+	"\x1eBatchCreateTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x129\n" +
+	"\ftransactions\x18\x03 \x03(\v2\x15.beecount.TransactionR\ftransactions\"\x89\x01\n" +
+	"\x11TransactionUpdate\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.beecount.TransactionR\vtransaction\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"\x8d\x01\n" +
+	"\x1eBatchUpdateTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x125\n" +
+	"\aupdates\x18\x03 \x03(\v2\x1b.beecount.TransactionUpdateR\aupdates\"\x7f\n" +
+	"\x1eBatchDeleteTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12'\n" +
+	"\x0ftransaction_ids\x18\x03 \x03(\tR\x0etransactionIds\"\xbb\x01\n" +
+	"\x16BatchTransactionResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x127\n" +
+	"\vtransaction\x18\x06 \x01(\v2\x15.beecount.TransactionR\vtransaction\"\xab\x01\n" +
+	"\x19BatchTransactionsResponse\x12\x1c\n" +
+	"\tcommitted\x18\x01 \x01(\bR\tcommitted\x12\x1c\n" +
+	"\tsucceeded\x18\x02 \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed\x12:\n" +
+	"\aresults\x18\x04 \x03(\v2 .beecount.BatchTransactionResultR\aresults\"\xdc\x01\n" +
+	"\x11AttachFileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12%\n" +
+	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\afile_id\x18\x04 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bfilename\x18\x05 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x06 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\a \x01(\x03R\x04size\"\x89\x01\n" +
+	"\x11DetachFileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12%\n" +
+	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\afile_id\x18\x04 \x01(\tR\x06fileId\"E\n" +
+	"\x11AttachmentRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId2\xe7\x11\n" +
+	"\x0fBusinessService\x125\n" +
+	"\x04Sync\x12\x15.beecount.SyncRequest\x1a\x16.beecount.SyncResponse\x12G\n" +
+	"\n" +
+	"GetLedgers\x12\x1b.beecount.GetLedgersRequest\x1a\x1c.beecount.GetLedgersResponse\x122\n" +
+	"\fCreateLedger\x12\x10.beecount.Ledger\x1a\x10.beecount.Ledger\x122\n" +
+	"\fUpdateLedger\x12\x10.beecount.Ledger\x1a\x10.beecount.Ledger\x122\n" +
+	"\fDeleteLedger\x12\x10.beecount.Ledger\x1a\x10.common.Response\x12A\n" +
+	"\x11CreateTransaction\x12\x15.beecount.Transaction\x1a\x15.beecount.Transaction\x12A\n" +
+	"\x11UpdateTransaction\x12\x15.beecount.Transaction\x1a\x15.beecount.Transaction\x12<\n" +
+	"\x11DeleteTransaction\x12\x15.beecount.Transaction\x1a\x10.common.Response\x12_\n" +
+	"\x12ImportTransactions\x12#.beecount.ImportTransactionsRequest\x1a$.beecount.ImportTransactionsResponse\x12O\n" +
+	"\fExportLedger\x12\x1d.beecount.ExportLedgerRequest\x1a\x1e.beecount.ExportLedgerResponse0\x01\x12_\n" +
+	"\x12SearchTransactions\x12#.beecount.SearchTransactionsRequest\x1a$.beecount.SearchTransactionsResponse\x12E\n" +
+	"\fInviteMember\x12\x1d.beecount.InviteMemberRequest\x1a\x16.beecount.LedgerMember\x12M\n" +
+	"\x10AcceptInvitation\x12!.beecount.AcceptInvitationRequest\x1a\x16.beecount.LedgerMember\x12?\n" +
+	"\fRemoveMember\x12\x1d.beecount.RemoveMemberRequest\x1a\x10.common.Response\x12=\n" +
+	"\vLeaveLedger\x12\x1c.beecount.LeaveLedgerRequest\x1a\x10.common.Response\x12J\n" +
+	"\vListMembers\x12\x1c.beecount.ListMembersRequest\x1a\x1d.beecount.ListMembersResponse\x12V\n" +
+	"\x0fListInvitations\x12 .beecount.ListInvitationsRequest\x1a!.beecount.ListInvitationsResponse\x12G\n" +
+	"\n" +
+	"GetHistory\x12\x1b.beecount.GetHistoryRequest\x1a\x1c.beecount.GetHistoryResponse\x12M\n" +
+	"\x0fRestoreRevision\x12 .beecount.RestoreRevisionRequest\x1a\x18.beecount.ChangeRevision\x12@\n" +
+	"\rArchiveLedger\x12\x1d.beecount.LedgerActionRequest\x1a\x10.beecount.Ledger\x12B\n" +
+	"\x0fUnarchiveLedger\x12\x1d.beecount.LedgerActionRequest\x1a\x10.beecount.Ledger\x12D\n" +
+	"\tListTrash\x12\x1a.beecount.ListTrashRequest\x1a\x1b.beecount.ListTrashResponse\x12@\n" +
+	"\rRestoreLedger\x12\x1d.beecount.LedgerActionRequest\x1a\x10.beecount.Ledger\x12>\n" +
+	"\vPurgeLedger\x12\x1d.beecount.LedgerActionRequest\x1a\x10.common.Response\x12h\n" +
+	"\x17BatchCreateTransactions\x12(.beecount.BatchCreateTransactionsRequest\x1a#.beecount.BatchTransactionsResponse\x12h\n" +
+	"\x17BatchUpdateTransactions\x12(.beecount.BatchUpdateTransactionsRequest\x1a#.beecount.BatchTransactionsResponse\x12h\n" +
+	"\x17BatchDeleteTransactions\x12(.beecount.BatchDeleteTransactionsRequest\x1a#.beecount.BatchTransactionsResponse\x12?\n" +
+	"\n" +
+	"AttachFile\x12\x1b.beecount.AttachFileRequest\x1a\x14.beecount.Attachment\x12;\n" +
+	"\n" +
+	"DetachFile\x12\x1b.beecount.DetachFileRequest\x1a\x10.common.Response\x12B\n" +
+	"\rGetAttachment\x12\x1b.beecount.AttachmentRequest\x1a\x14.beecount.Attachment\x12A\n" +
+	"\x10DeleteAttachment\x12\x1b.beecount.AttachmentRequest\x1a\x10.common.ResponseB>Z<github.com/fishdivinity/BeeCount-Cloud/common/proto/businessb\x06proto3"
 
 var (
 	file_business_business_proto_rawDescOnce sync.Once
@@ -630,45 +3530,149 @@ func file_business_business_proto_rawDescGZIP() []byte {
 	return file_business_business_proto_rawDescData
 }
 
-var file_business_business_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_business_business_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
 var file_business_business_proto_goTypes = []any{
-	(*Ledger)(nil),             // 0: beecount.Ledger
-	(*Transaction)(nil),        // 1: beecount.Transaction
-	(*SyncRequest)(nil),        // 2: beecount.SyncRequest
-	(*SyncResponse)(nil),       // 3: beecount.SyncResponse
-	(*GetLedgersRequest)(nil),  // 4: beecount.GetLedgersRequest
-	(*GetLedgersResponse)(nil), // 5: beecount.GetLedgersResponse
-	nil,                        // 6: beecount.Transaction.TagsEntry
-	(*common.Response)(nil),    // 7: common.Response
+	(*Ledger)(nil),                         // 0: beecount.Ledger
+	(*Transaction)(nil),                    // 1: beecount.Transaction
+	(*Attachment)(nil),                     // 2: beecount.Attachment
+	(*SyncRequest)(nil),                    // 3: beecount.SyncRequest
+	(*SyncResponse)(nil),                   // 4: beecount.SyncResponse
+	(*SyncRejection)(nil),                  // 5: beecount.SyncRejection
+	(*GetLedgersRequest)(nil),              // 6: beecount.GetLedgersRequest
+	(*GetLedgersResponse)(nil),             // 7: beecount.GetLedgersResponse
+	(*ImportColumnMapping)(nil),            // 8: beecount.ImportColumnMapping
+	(*ImportTransactionsRequest)(nil),      // 9: beecount.ImportTransactionsRequest
+	(*ImportRowResult)(nil),                // 10: beecount.ImportRowResult
+	(*ImportTransactionsResponse)(nil),     // 11: beecount.ImportTransactionsResponse
+	(*ExportLedgerRequest)(nil),            // 12: beecount.ExportLedgerRequest
+	(*ExportLedgerResponse)(nil),           // 13: beecount.ExportLedgerResponse
+	(*SearchTransactionsRequest)(nil),      // 14: beecount.SearchTransactionsRequest
+	(*SearchTransactionsResponse)(nil),     // 15: beecount.SearchTransactionsResponse
+	(*LedgerMember)(nil),                   // 16: beecount.LedgerMember
+	(*InviteMemberRequest)(nil),            // 17: beecount.InviteMemberRequest
+	(*AcceptInvitationRequest)(nil),        // 18: beecount.AcceptInvitationRequest
+	(*RemoveMemberRequest)(nil),            // 19: beecount.RemoveMemberRequest
+	(*LeaveLedgerRequest)(nil),             // 20: beecount.LeaveLedgerRequest
+	(*ListMembersRequest)(nil),             // 21: beecount.ListMembersRequest
+	(*ListMembersResponse)(nil),            // 22: beecount.ListMembersResponse
+	(*ListInvitationsRequest)(nil),         // 23: beecount.ListInvitationsRequest
+	(*ListInvitationsResponse)(nil),        // 24: beecount.ListInvitationsResponse
+	(*ChangeRevision)(nil),                 // 25: beecount.ChangeRevision
+	(*GetHistoryRequest)(nil),              // 26: beecount.GetHistoryRequest
+	(*GetHistoryResponse)(nil),             // 27: beecount.GetHistoryResponse
+	(*RestoreRevisionRequest)(nil),         // 28: beecount.RestoreRevisionRequest
+	(*LedgerActionRequest)(nil),            // 29: beecount.LedgerActionRequest
+	(*ListTrashRequest)(nil),               // 30: beecount.ListTrashRequest
+	(*ListTrashResponse)(nil),              // 31: beecount.ListTrashResponse
+	(*BatchCreateTransactionsRequest)(nil), // 32: beecount.BatchCreateTransactionsRequest
+	(*TransactionUpdate)(nil),              // 33: beecount.TransactionUpdate
+	(*BatchUpdateTransactionsRequest)(nil), // 34: beecount.BatchUpdateTransactionsRequest
+	(*BatchDeleteTransactionsRequest)(nil), // 35: beecount.BatchDeleteTransactionsRequest
+	(*BatchTransactionResult)(nil),         // 36: beecount.BatchTransactionResult
+	(*BatchTransactionsResponse)(nil),      // 37: beecount.BatchTransactionsResponse
+	(*AttachFileRequest)(nil),              // 38: beecount.AttachFileRequest
+	(*DetachFileRequest)(nil),              // 39: beecount.DetachFileRequest
+	(*AttachmentRequest)(nil),              // 40: beecount.AttachmentRequest
+	nil,                                    // 41: beecount.Transaction.TagsEntry
+	nil,                                    // 42: beecount.ImportTransactionsRequest.CategoryMappingEntry
+	nil,                                    // 43: beecount.ImportTransactionsRequest.AccountMappingEntry
+	(*fieldmaskpb.FieldMask)(nil),          // 44: google.protobuf.FieldMask
+	(*common.Response)(nil),                // 45: common.Response
 }
 var file_business_business_proto_depIdxs = []int32{
-	6,  // 0: beecount.Transaction.tags:type_name -> beecount.Transaction.TagsEntry
-	1,  // 1: beecount.SyncRequest.transactions:type_name -> beecount.Transaction
-	0,  // 2: beecount.SyncRequest.ledgers:type_name -> beecount.Ledger
-	1,  // 3: beecount.SyncResponse.transactions:type_name -> beecount.Transaction
-	0,  // 4: beecount.SyncResponse.ledgers:type_name -> beecount.Ledger
-	0,  // 5: beecount.GetLedgersResponse.ledgers:type_name -> beecount.Ledger
-	2,  // 6: beecount.BusinessService.Sync:input_type -> beecount.SyncRequest
-	4,  // 7: beecount.BusinessService.GetLedgers:input_type -> beecount.GetLedgersRequest
-	0,  // 8: beecount.BusinessService.CreateLedger:input_type -> beecount.Ledger
-	0,  // 9: beecount.BusinessService.UpdateLedger:input_type -> beecount.Ledger
-	0,  // 10: beecount.BusinessService.DeleteLedger:input_type -> beecount.Ledger
-	1,  // 11: beecount.BusinessService.CreateTransaction:input_type -> beecount.Transaction
-	1,  // 12: beecount.BusinessService.UpdateTransaction:input_type -> beecount.Transaction
-	1,  // 13: beecount.BusinessService.DeleteTransaction:input_type -> beecount.Transaction
-	3,  // 14: beecount.BusinessService.Sync:output_type -> beecount.SyncResponse
-	5,  // 15: beecount.BusinessService.GetLedgers:output_type -> beecount.GetLedgersResponse
-	0,  // 16: beecount.BusinessService.CreateLedger:output_type -> beecount.Ledger
-	0,  // 17: beecount.BusinessService.UpdateLedger:output_type -> beecount.Ledger
-	7,  // 18: beecount.BusinessService.DeleteLedger:output_type -> common.Response
-	1,  // 19: beecount.BusinessService.CreateTransaction:output_type -> beecount.Transaction
-	1,  // 20: beecount.BusinessService.UpdateTransaction:output_type -> beecount.Transaction
-	7,  // 21: beecount.BusinessService.DeleteTransaction:output_type -> common.Response
-	14, // [14:22] is the sub-list for method output_type
-	6,  // [6:14] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	44, // 0: beecount.Ledger.update_mask:type_name -> google.protobuf.FieldMask
+	41, // 1: beecount.Transaction.tags:type_name -> beecount.Transaction.TagsEntry
+	44, // 2: beecount.Transaction.update_mask:type_name -> google.protobuf.FieldMask
+	2,  // 3: beecount.Transaction.attachments:type_name -> beecount.Attachment
+	1,  // 4: beecount.SyncRequest.transactions:type_name -> beecount.Transaction
+	0,  // 5: beecount.SyncRequest.ledgers:type_name -> beecount.Ledger
+	1,  // 6: beecount.SyncResponse.transactions:type_name -> beecount.Transaction
+	0,  // 7: beecount.SyncResponse.ledgers:type_name -> beecount.Ledger
+	5,  // 8: beecount.SyncResponse.rejected:type_name -> beecount.SyncRejection
+	0,  // 9: beecount.GetLedgersResponse.ledgers:type_name -> beecount.Ledger
+	8,  // 10: beecount.ImportTransactionsRequest.mapping:type_name -> beecount.ImportColumnMapping
+	42, // 11: beecount.ImportTransactionsRequest.category_mapping:type_name -> beecount.ImportTransactionsRequest.CategoryMappingEntry
+	43, // 12: beecount.ImportTransactionsRequest.account_mapping:type_name -> beecount.ImportTransactionsRequest.AccountMappingEntry
+	1,  // 13: beecount.ImportRowResult.transaction:type_name -> beecount.Transaction
+	10, // 14: beecount.ImportTransactionsResponse.rows:type_name -> beecount.ImportRowResult
+	1,  // 15: beecount.SearchTransactionsResponse.transactions:type_name -> beecount.Transaction
+	16, // 16: beecount.ListMembersResponse.members:type_name -> beecount.LedgerMember
+	16, // 17: beecount.ListInvitationsResponse.invitations:type_name -> beecount.LedgerMember
+	25, // 18: beecount.GetHistoryResponse.revisions:type_name -> beecount.ChangeRevision
+	0,  // 19: beecount.ListTrashResponse.ledgers:type_name -> beecount.Ledger
+	1,  // 20: beecount.BatchCreateTransactionsRequest.transactions:type_name -> beecount.Transaction
+	1,  // 21: beecount.TransactionUpdate.transaction:type_name -> beecount.Transaction
+	44, // 22: beecount.TransactionUpdate.update_mask:type_name -> google.protobuf.FieldMask
+	33, // 23: beecount.BatchUpdateTransactionsRequest.updates:type_name -> beecount.TransactionUpdate
+	1,  // 24: beecount.BatchTransactionResult.transaction:type_name -> beecount.Transaction
+	36, // 25: beecount.BatchTransactionsResponse.results:type_name -> beecount.BatchTransactionResult
+	3,  // 26: beecount.BusinessService.Sync:input_type -> beecount.SyncRequest
+	6,  // 27: beecount.BusinessService.GetLedgers:input_type -> beecount.GetLedgersRequest
+	0,  // 28: beecount.BusinessService.CreateLedger:input_type -> beecount.Ledger
+	0,  // 29: beecount.BusinessService.UpdateLedger:input_type -> beecount.Ledger
+	0,  // 30: beecount.BusinessService.DeleteLedger:input_type -> beecount.Ledger
+	1,  // 31: beecount.BusinessService.CreateTransaction:input_type -> beecount.Transaction
+	1,  // 32: beecount.BusinessService.UpdateTransaction:input_type -> beecount.Transaction
+	1,  // 33: beecount.BusinessService.DeleteTransaction:input_type -> beecount.Transaction
+	9,  // 34: beecount.BusinessService.ImportTransactions:input_type -> beecount.ImportTransactionsRequest
+	12, // 35: beecount.BusinessService.ExportLedger:input_type -> beecount.ExportLedgerRequest
+	14, // 36: beecount.BusinessService.SearchTransactions:input_type -> beecount.SearchTransactionsRequest
+	17, // 37: beecount.BusinessService.InviteMember:input_type -> beecount.InviteMemberRequest
+	18, // 38: beecount.BusinessService.AcceptInvitation:input_type -> beecount.AcceptInvitationRequest
+	19, // 39: beecount.BusinessService.RemoveMember:input_type -> beecount.RemoveMemberRequest
+	20, // 40: beecount.BusinessService.LeaveLedger:input_type -> beecount.LeaveLedgerRequest
+	21, // 41: beecount.BusinessService.ListMembers:input_type -> beecount.ListMembersRequest
+	23, // 42: beecount.BusinessService.ListInvitations:input_type -> beecount.ListInvitationsRequest
+	26, // 43: beecount.BusinessService.GetHistory:input_type -> beecount.GetHistoryRequest
+	28, // 44: beecount.BusinessService.RestoreRevision:input_type -> beecount.RestoreRevisionRequest
+	29, // 45: beecount.BusinessService.ArchiveLedger:input_type -> beecount.LedgerActionRequest
+	29, // 46: beecount.BusinessService.UnarchiveLedger:input_type -> beecount.LedgerActionRequest
+	30, // 47: beecount.BusinessService.ListTrash:input_type -> beecount.ListTrashRequest
+	29, // 48: beecount.BusinessService.RestoreLedger:input_type -> beecount.LedgerActionRequest
+	29, // 49: beecount.BusinessService.PurgeLedger:input_type -> beecount.LedgerActionRequest
+	32, // 50: beecount.BusinessService.BatchCreateTransactions:input_type -> beecount.BatchCreateTransactionsRequest
+	34, // 51: beecount.BusinessService.BatchUpdateTransactions:input_type -> beecount.BatchUpdateTransactionsRequest
+	35, // 52: beecount.BusinessService.BatchDeleteTransactions:input_type -> beecount.BatchDeleteTransactionsRequest
+	38, // 53: beecount.BusinessService.AttachFile:input_type -> beecount.AttachFileRequest
+	39, // 54: beecount.BusinessService.DetachFile:input_type -> beecount.DetachFileRequest
+	40, // 55: beecount.BusinessService.GetAttachment:input_type -> beecount.AttachmentRequest
+	40, // 56: beecount.BusinessService.DeleteAttachment:input_type -> beecount.AttachmentRequest
+	4,  // 57: beecount.BusinessService.Sync:output_type -> beecount.SyncResponse
+	7,  // 58: beecount.BusinessService.GetLedgers:output_type -> beecount.GetLedgersResponse
+	0,  // 59: beecount.BusinessService.CreateLedger:output_type -> beecount.Ledger
+	0,  // 60: beecount.BusinessService.UpdateLedger:output_type -> beecount.Ledger
+	45, // 61: beecount.BusinessService.DeleteLedger:output_type -> common.Response
+	1,  // 62: beecount.BusinessService.CreateTransaction:output_type -> beecount.Transaction
+	1,  // 63: beecount.BusinessService.UpdateTransaction:output_type -> beecount.Transaction
+	45, // 64: beecount.BusinessService.DeleteTransaction:output_type -> common.Response
+	11, // 65: beecount.BusinessService.ImportTransactions:output_type -> beecount.ImportTransactionsResponse
+	13, // 66: beecount.BusinessService.ExportLedger:output_type -> beecount.ExportLedgerResponse
+	15, // 67: beecount.BusinessService.SearchTransactions:output_type -> beecount.SearchTransactionsResponse
+	16, // 68: beecount.BusinessService.InviteMember:output_type -> beecount.LedgerMember
+	16, // 69: beecount.BusinessService.AcceptInvitation:output_type -> beecount.LedgerMember
+	45, // 70: beecount.BusinessService.RemoveMember:output_type -> common.Response
+	45, // 71: beecount.BusinessService.LeaveLedger:output_type -> common.Response
+	22, // 72: beecount.BusinessService.ListMembers:output_type -> beecount.ListMembersResponse
+	24, // 73: beecount.BusinessService.ListInvitations:output_type -> beecount.ListInvitationsResponse
+	27, // 74: beecount.BusinessService.GetHistory:output_type -> beecount.GetHistoryResponse
+	25, // 75: beecount.BusinessService.RestoreRevision:output_type -> beecount.ChangeRevision
+	0,  // 76: beecount.BusinessService.ArchiveLedger:output_type -> beecount.Ledger
+	0,  // 77: beecount.BusinessService.UnarchiveLedger:output_type -> beecount.Ledger
+	31, // 78: beecount.BusinessService.ListTrash:output_type -> beecount.ListTrashResponse
+	0,  // 79: beecount.BusinessService.RestoreLedger:output_type -> beecount.Ledger
+	45, // 80: beecount.BusinessService.PurgeLedger:output_type -> common.Response
+	37, // 81: beecount.BusinessService.BatchCreateTransactions:output_type -> beecount.BatchTransactionsResponse
+	37, // 82: beecount.BusinessService.BatchUpdateTransactions:output_type -> beecount.BatchTransactionsResponse
+	37, // 83: beecount.BusinessService.BatchDeleteTransactions:output_type -> beecount.BatchTransactionsResponse
+	2,  // 84: beecount.BusinessService.AttachFile:output_type -> beecount.Attachment
+	45, // 85: beecount.BusinessService.DetachFile:output_type -> common.Response
+	2,  // 86: beecount.BusinessService.GetAttachment:output_type -> beecount.Attachment
+	45, // 87: beecount.BusinessService.DeleteAttachment:output_type -> common.Response
+	57, // [57:88] is the sub-list for method output_type
+	26, // [26:57] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_business_business_proto_init() }
@@ -682,7 +3686,7 @@ func file_business_business_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_business_business_proto_rawDesc), len(file_business_business_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,14 +20,37 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BusinessService_Sync_FullMethodName              = "/beecount.BusinessService/Sync"
-	BusinessService_GetLedgers_FullMethodName        = "/beecount.BusinessService/GetLedgers"
-	BusinessService_CreateLedger_FullMethodName      = "/beecount.BusinessService/CreateLedger"
-	BusinessService_UpdateLedger_FullMethodName      = "/beecount.BusinessService/UpdateLedger"
-	BusinessService_DeleteLedger_FullMethodName      = "/beecount.BusinessService/DeleteLedger"
-	BusinessService_CreateTransaction_FullMethodName = "/beecount.BusinessService/CreateTransaction"
-	BusinessService_UpdateTransaction_FullMethodName = "/beecount.BusinessService/UpdateTransaction"
-	BusinessService_DeleteTransaction_FullMethodName = "/beecount.BusinessService/DeleteTransaction"
+	BusinessService_Sync_FullMethodName                    = "/beecount.BusinessService/Sync"
+	BusinessService_GetLedgers_FullMethodName              = "/beecount.BusinessService/GetLedgers"
+	BusinessService_CreateLedger_FullMethodName            = "/beecount.BusinessService/CreateLedger"
+	BusinessService_UpdateLedger_FullMethodName            = "/beecount.BusinessService/UpdateLedger"
+	BusinessService_DeleteLedger_FullMethodName            = "/beecount.BusinessService/DeleteLedger"
+	BusinessService_CreateTransaction_FullMethodName       = "/beecount.BusinessService/CreateTransaction"
+	BusinessService_UpdateTransaction_FullMethodName       = "/beecount.BusinessService/UpdateTransaction"
+	BusinessService_DeleteTransaction_FullMethodName       = "/beecount.BusinessService/DeleteTransaction"
+	BusinessService_ImportTransactions_FullMethodName      = "/beecount.BusinessService/ImportTransactions"
+	BusinessService_ExportLedger_FullMethodName            = "/beecount.BusinessService/ExportLedger"
+	BusinessService_SearchTransactions_FullMethodName      = "/beecount.BusinessService/SearchTransactions"
+	BusinessService_InviteMember_FullMethodName            = "/beecount.BusinessService/InviteMember"
+	BusinessService_AcceptInvitation_FullMethodName        = "/beecount.BusinessService/AcceptInvitation"
+	BusinessService_RemoveMember_FullMethodName            = "/beecount.BusinessService/RemoveMember"
+	BusinessService_LeaveLedger_FullMethodName             = "/beecount.BusinessService/LeaveLedger"
+	BusinessService_ListMembers_FullMethodName             = "/beecount.BusinessService/ListMembers"
+	BusinessService_ListInvitations_FullMethodName         = "/beecount.BusinessService/ListInvitations"
+	BusinessService_GetHistory_FullMethodName              = "/beecount.BusinessService/GetHistory"
+	BusinessService_RestoreRevision_FullMethodName         = "/beecount.BusinessService/RestoreRevision"
+	BusinessService_ArchiveLedger_FullMethodName           = "/beecount.BusinessService/ArchiveLedger"
+	BusinessService_UnarchiveLedger_FullMethodName         = "/beecount.BusinessService/UnarchiveLedger"
+	BusinessService_ListTrash_FullMethodName               = "/beecount.BusinessService/ListTrash"
+	BusinessService_RestoreLedger_FullMethodName           = "/beecount.BusinessService/RestoreLedger"
+	BusinessService_PurgeLedger_FullMethodName             = "/beecount.BusinessService/PurgeLedger"
+	BusinessService_BatchCreateTransactions_FullMethodName = "/beecount.BusinessService/BatchCreateTransactions"
+	BusinessService_BatchUpdateTransactions_FullMethodName = "/beecount.BusinessService/BatchUpdateTransactions"
+	BusinessService_BatchDeleteTransactions_FullMethodName = "/beecount.BusinessService/BatchDeleteTransactions"
+	BusinessService_AttachFile_FullMethodName              = "/beecount.BusinessService/AttachFile"
+	BusinessService_DetachFile_FullMethodName              = "/beecount.BusinessService/DetachFile"
+	BusinessService_GetAttachment_FullMethodName           = "/beecount.BusinessService/GetAttachment"
+	BusinessService_DeleteAttachment_FullMethodName        = "/beecount.BusinessService/DeleteAttachment"
 )
 
 // BusinessServiceClient is the client API for BusinessService service.
@@ -42,16 +65,62 @@ type BusinessServiceClient interface {
 	GetLedgers(ctx context.Context, in *GetLedgersRequest, opts ...grpc.CallOption) (*GetLedgersResponse, error)
 	// 创建账本
 	CreateLedger(ctx context.Context, in *Ledger, opts ...grpc.CallOption) (*Ledger, error)
-	// 更新账本
+	// 更新账本，支持按字段掩码部分更新
 	UpdateLedger(ctx context.Context, in *Ledger, opts ...grpc.CallOption) (*Ledger, error)
-	// 删除账本
+	// 删除账本（移入回收站）
 	DeleteLedger(ctx context.Context, in *Ledger, opts ...grpc.CallOption) (*common.Response, error)
 	// 创建交易
 	CreateTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Transaction, error)
-	// 更新交易
+	// 更新交易，支持按字段掩码部分更新
 	UpdateTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Transaction, error)
 	// 删除交易
 	DeleteTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*common.Response, error)
+	// 导入交易（CSV/XLSX）
+	ImportTransactions(ctx context.Context, in *ImportTransactionsRequest, opts ...grpc.CallOption) (*ImportTransactionsResponse, error)
+	// 导出账本（CSV/XLSX/JSON/QIF/OFX，流式）
+	ExportLedger(ctx context.Context, in *ExportLedgerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportLedgerResponse], error)
+	// 全文搜索交易
+	SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error)
+	// 邀请成员加入账本
+	InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*LedgerMember, error)
+	// 接受账本邀请
+	AcceptInvitation(ctx context.Context, in *AcceptInvitationRequest, opts ...grpc.CallOption) (*LedgerMember, error)
+	// 移除账本成员
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 退出账本
+	LeaveLedger(ctx context.Context, in *LeaveLedgerRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 获取账本成员列表
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+	// 获取待接受的邀请
+	ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (*ListInvitationsResponse, error)
+	// 获取记录的变更历史
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// 恢复记录到指定版本，返回新产生的变更记录
+	RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, opts ...grpc.CallOption) (*ChangeRevision, error)
+	// 归档账本
+	ArchiveLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error)
+	// 取消归档
+	UnarchiveLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error)
+	// 获取回收站中的账本
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	// 从回收站恢复账本
+	RestoreLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error)
+	// 永久删除回收站中的账本及其交易和附件
+	PurgeLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 批量创建交易
+	BatchCreateTransactions(ctx context.Context, in *BatchCreateTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error)
+	// 批量更新交易，支持按字段掩码部分更新
+	BatchUpdateTransactions(ctx context.Context, in *BatchUpdateTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error)
+	// 批量删除交易
+	BatchDeleteTransactions(ctx context.Context, in *BatchDeleteTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error)
+	// 登记附件文件并关联到交易
+	AttachFile(ctx context.Context, in *AttachFileRequest, opts ...grpc.CallOption) (*Attachment, error)
+	// 取消交易与附件的关联，不再被引用的文件将被删除
+	DetachFile(ctx context.Context, in *DetachFileRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 获取附件信息，上传者或可查看引用交易的账本成员可以访问
+	GetAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Attachment, error)
+	// 删除附件文件及其全部关联，仅上传者可以删除
+	DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*common.Response, error)
 }
 
 type businessServiceClient struct {
@@ -142,6 +211,245 @@ func (c *businessServiceClient) DeleteTransaction(ctx context.Context, in *Trans
 	return out, nil
 }
 
+func (c *businessServiceClient) ImportTransactions(ctx context.Context, in *ImportTransactionsRequest, opts ...grpc.CallOption) (*ImportTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportTransactionsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_ImportTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) ExportLedger(ctx context.Context, in *ExportLedgerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportLedgerResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BusinessService_ServiceDesc.Streams[0], BusinessService_ExportLedger_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportLedgerRequest, ExportLedgerResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BusinessService_ExportLedgerClient = grpc.ServerStreamingClient[ExportLedgerResponse]
+
+func (c *businessServiceClient) SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchTransactionsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_SearchTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*LedgerMember, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LedgerMember)
+	err := c.cc.Invoke(ctx, BusinessService_InviteMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) AcceptInvitation(ctx context.Context, in *AcceptInvitationRequest, opts ...grpc.CallOption) (*LedgerMember, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LedgerMember)
+	err := c.cc.Invoke(ctx, BusinessService_AcceptInvitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, BusinessService_RemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) LeaveLedger(ctx context.Context, in *LeaveLedgerRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, BusinessService_LeaveLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMembersResponse)
+	err := c.cc.Invoke(ctx, BusinessService_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (*ListInvitationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInvitationsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_ListInvitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, BusinessService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, opts ...grpc.CallOption) (*ChangeRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeRevision)
+	err := c.cc.Invoke(ctx, BusinessService_RestoreRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) ArchiveLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, BusinessService_ArchiveLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) UnarchiveLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, BusinessService_UnarchiveLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, BusinessService_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) RestoreLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, BusinessService_RestoreLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) PurgeLedger(ctx context.Context, in *LedgerActionRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, BusinessService_PurgeLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) BatchCreateTransactions(ctx context.Context, in *BatchCreateTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchTransactionsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_BatchCreateTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) BatchUpdateTransactions(ctx context.Context, in *BatchUpdateTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchTransactionsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_BatchUpdateTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) BatchDeleteTransactions(ctx context.Context, in *BatchDeleteTransactionsRequest, opts ...grpc.CallOption) (*BatchTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchTransactionsResponse)
+	err := c.cc.Invoke(ctx, BusinessService_BatchDeleteTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) AttachFile(ctx context.Context, in *AttachFileRequest, opts ...grpc.CallOption) (*Attachment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attachment)
+	err := c.cc.Invoke(ctx, BusinessService_AttachFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) DetachFile(ctx context.Context, in *DetachFileRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, BusinessService_DetachFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) GetAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Attachment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attachment)
+	err := c.cc.Invoke(ctx, BusinessService_GetAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *businessServiceClient) DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, BusinessService_DeleteAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BusinessServiceServer is the server API for BusinessService service.
 // All implementations must embed UnimplementedBusinessServiceServer
 // for forward compatibility.
@@ -154,16 +462,62 @@ type BusinessServiceServer interface {
 	GetLedgers(context.Context, *GetLedgersRequest) (*GetLedgersResponse, error)
 	// 创建账本
 	CreateLedger(context.Context, *Ledger) (*Ledger, error)
-	// 更新账本
+	// 更新账本，支持按字段掩码部分更新
 	UpdateLedger(context.Context, *Ledger) (*Ledger, error)
-	// 删除账本
+	// 删除账本（移入回收站）
 	DeleteLedger(context.Context, *Ledger) (*common.Response, error)
 	// 创建交易
 	CreateTransaction(context.Context, *Transaction) (*Transaction, error)
-	// 更新交易
+	// 更新交易，支持按字段掩码部分更新
 	UpdateTransaction(context.Context, *Transaction) (*Transaction, error)
 	// 删除交易
 	DeleteTransaction(context.Context, *Transaction) (*common.Response, error)
+	// 导入交易（CSV/XLSX）
+	ImportTransactions(context.Context, *ImportTransactionsRequest) (*ImportTransactionsResponse, error)
+	// 导出账本（CSV/XLSX/JSON/QIF/OFX，流式）
+	ExportLedger(*ExportLedgerRequest, grpc.ServerStreamingServer[ExportLedgerResponse]) error
+	// 全文搜索交易
+	SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error)
+	// 邀请成员加入账本
+	InviteMember(context.Context, *InviteMemberRequest) (*LedgerMember, error)
+	// 接受账本邀请
+	AcceptInvitation(context.Context, *AcceptInvitationRequest) (*LedgerMember, error)
+	// 移除账本成员
+	RemoveMember(context.Context, *RemoveMemberRequest) (*common.Response, error)
+	// 退出账本
+	LeaveLedger(context.Context, *LeaveLedgerRequest) (*common.Response, error)
+	// 获取账本成员列表
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+	// 获取待接受的邀请
+	ListInvitations(context.Context, *ListInvitationsRequest) (*ListInvitationsResponse, error)
+	// 获取记录的变更历史
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// 恢复记录到指定版本，返回新产生的变更记录
+	RestoreRevision(context.Context, *RestoreRevisionRequest) (*ChangeRevision, error)
+	// 归档账本
+	ArchiveLedger(context.Context, *LedgerActionRequest) (*Ledger, error)
+	// 取消归档
+	UnarchiveLedger(context.Context, *LedgerActionRequest) (*Ledger, error)
+	// 获取回收站中的账本
+	ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error)
+	// 从回收站恢复账本
+	RestoreLedger(context.Context, *LedgerActionRequest) (*Ledger, error)
+	// 永久删除回收站中的账本及其交易和附件
+	PurgeLedger(context.Context, *LedgerActionRequest) (*common.Response, error)
+	// 批量创建交易
+	BatchCreateTransactions(context.Context, *BatchCreateTransactionsRequest) (*BatchTransactionsResponse, error)
+	// 批量更新交易，支持按字段掩码部分更新
+	BatchUpdateTransactions(context.Context, *BatchUpdateTransactionsRequest) (*BatchTransactionsResponse, error)
+	// 批量删除交易
+	BatchDeleteTransactions(context.Context, *BatchDeleteTransactionsRequest) (*BatchTransactionsResponse, error)
+	// 登记附件文件并关联到交易
+	AttachFile(context.Context, *AttachFileRequest) (*Attachment, error)
+	// 取消交易与附件的关联，不再被引用的文件将被删除
+	DetachFile(context.Context, *DetachFileRequest) (*common.Response, error)
+	// 获取附件信息，上传者或可查看引用交易的账本成员可以访问
+	GetAttachment(context.Context, *AttachmentRequest) (*Attachment, error)
+	// 删除附件文件及其全部关联，仅上传者可以删除
+	DeleteAttachment(context.Context, *AttachmentRequest) (*common.Response, error)
 	mustEmbedUnimplementedBusinessServiceServer()
 }
 
@@ -198,6 +552,75 @@ func (UnimplementedBusinessServiceServer) UpdateTransaction(context.Context, *Tr
 func (UnimplementedBusinessServiceServer) DeleteTransaction(context.Context, *Transaction) (*common.Response, error) {
 	return nil, status.Error(codes.Unimplemented, "method DeleteTransaction not implemented")
 }
+func (UnimplementedBusinessServiceServer) ImportTransactions(context.Context, *ImportTransactionsRequest) (*ImportTransactionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ImportTransactions not implemented")
+}
+func (UnimplementedBusinessServiceServer) ExportLedger(*ExportLedgerRequest, grpc.ServerStreamingServer[ExportLedgerResponse]) error {
+	return status.Error(codes.Unimplemented, "method ExportLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchTransactions not implemented")
+}
+func (UnimplementedBusinessServiceServer) InviteMember(context.Context, *InviteMemberRequest) (*LedgerMember, error) {
+	return nil, status.Error(codes.Unimplemented, "method InviteMember not implemented")
+}
+func (UnimplementedBusinessServiceServer) AcceptInvitation(context.Context, *AcceptInvitationRequest) (*LedgerMember, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcceptInvitation not implemented")
+}
+func (UnimplementedBusinessServiceServer) RemoveMember(context.Context, *RemoveMemberRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedBusinessServiceServer) LeaveLedger(context.Context, *LeaveLedgerRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method LeaveLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedBusinessServiceServer) ListInvitations(context.Context, *ListInvitationsRequest) (*ListInvitationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListInvitations not implemented")
+}
+func (UnimplementedBusinessServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedBusinessServiceServer) RestoreRevision(context.Context, *RestoreRevisionRequest) (*ChangeRevision, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreRevision not implemented")
+}
+func (UnimplementedBusinessServiceServer) ArchiveLedger(context.Context, *LedgerActionRequest) (*Ledger, error) {
+	return nil, status.Error(codes.Unimplemented, "method ArchiveLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) UnarchiveLedger(context.Context, *LedgerActionRequest) (*Ledger, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnarchiveLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedBusinessServiceServer) RestoreLedger(context.Context, *LedgerActionRequest) (*Ledger, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) PurgeLedger(context.Context, *LedgerActionRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method PurgeLedger not implemented")
+}
+func (UnimplementedBusinessServiceServer) BatchCreateTransactions(context.Context, *BatchCreateTransactionsRequest) (*BatchTransactionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchCreateTransactions not implemented")
+}
+func (UnimplementedBusinessServiceServer) BatchUpdateTransactions(context.Context, *BatchUpdateTransactionsRequest) (*BatchTransactionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchUpdateTransactions not implemented")
+}
+func (UnimplementedBusinessServiceServer) BatchDeleteTransactions(context.Context, *BatchDeleteTransactionsRequest) (*BatchTransactionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchDeleteTransactions not implemented")
+}
+func (UnimplementedBusinessServiceServer) AttachFile(context.Context, *AttachFileRequest) (*Attachment, error) {
+	return nil, status.Error(codes.Unimplemented, "method AttachFile not implemented")
+}
+func (UnimplementedBusinessServiceServer) DetachFile(context.Context, *DetachFileRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method DetachFile not implemented")
+}
+func (UnimplementedBusinessServiceServer) GetAttachment(context.Context, *AttachmentRequest) (*Attachment, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAttachment not implemented")
+}
+func (UnimplementedBusinessServiceServer) DeleteAttachment(context.Context, *AttachmentRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAttachment not implemented")
+}
 func (UnimplementedBusinessServiceServer) mustEmbedUnimplementedBusinessServiceServer() {}
 func (UnimplementedBusinessServiceServer) testEmbeddedByValue()                         {}
 
@@ -363,46 +786,547 @@ func _BusinessService_DeleteTransaction_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
-// BusinessService_ServiceDesc is the grpc.ServiceDesc for BusinessService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var BusinessService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "beecount.BusinessService",
-	HandlerType: (*BusinessServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Sync",
-			Handler:    _BusinessService_Sync_Handler,
-		},
-		{
-			MethodName: "GetLedgers",
-			Handler:    _BusinessService_GetLedgers_Handler,
-		},
-		{
-			MethodName: "CreateLedger",
-			Handler:    _BusinessService_CreateLedger_Handler,
-		},
-		{
-			MethodName: "UpdateLedger",
-			Handler:    _BusinessService_UpdateLedger_Handler,
-		},
-		{
-			MethodName: "DeleteLedger",
-			Handler:    _BusinessService_DeleteLedger_Handler,
-		},
-		{
-			MethodName: "CreateTransaction",
-			Handler:    _BusinessService_CreateTransaction_Handler,
-		},
-		{
-			MethodName: "UpdateTransaction",
-			Handler:    _BusinessService_UpdateTransaction_Handler,
-		},
-		{
-			MethodName: "DeleteTransaction",
-			Handler:    _BusinessService_DeleteTransaction_Handler,
+func _BusinessService_ImportTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).ImportTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_ImportTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).ImportTransactions(ctx, req.(*ImportTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_ExportLedger_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportLedgerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BusinessServiceServer).ExportLedger(m, &grpc.GenericServerStream[ExportLedgerRequest, ExportLedgerResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BusinessService_ExportLedgerServer = grpc.ServerStreamingServer[ExportLedgerResponse]
+
+func _BusinessService_SearchTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).SearchTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_SearchTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).SearchTransactions(ctx, req.(*SearchTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_InviteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).InviteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_InviteMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).InviteMember(ctx, req.(*InviteMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_AcceptInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptInvitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).AcceptInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_AcceptInvitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).AcceptInvitation(ctx, req.(*AcceptInvitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_LeaveLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveLedgerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).LeaveLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_LeaveLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).LeaveLedger(ctx, req.(*LeaveLedgerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_ListInvitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInvitationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).ListInvitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_ListInvitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).ListInvitations(ctx, req.(*ListInvitationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_RestoreRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).RestoreRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_RestoreRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).RestoreRevision(ctx, req.(*RestoreRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_ArchiveLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LedgerActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).ArchiveLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_ArchiveLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).ArchiveLedger(ctx, req.(*LedgerActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_UnarchiveLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LedgerActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).UnarchiveLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_UnarchiveLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).UnarchiveLedger(ctx, req.(*LedgerActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_RestoreLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LedgerActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).RestoreLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_RestoreLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).RestoreLedger(ctx, req.(*LedgerActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_PurgeLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LedgerActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).PurgeLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_PurgeLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).PurgeLedger(ctx, req.(*LedgerActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_BatchCreateTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).BatchCreateTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_BatchCreateTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).BatchCreateTransactions(ctx, req.(*BatchCreateTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_BatchUpdateTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).BatchUpdateTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_BatchUpdateTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).BatchUpdateTransactions(ctx, req.(*BatchUpdateTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_BatchDeleteTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).BatchDeleteTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_BatchDeleteTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).BatchDeleteTransactions(ctx, req.(*BatchDeleteTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_AttachFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).AttachFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_AttachFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).AttachFile(ctx, req.(*AttachFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_DetachFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetachFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).DetachFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_DetachFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).DetachFile(ctx, req.(*DetachFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_GetAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).GetAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_GetAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).GetAttachment(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusinessService_DeleteAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusinessServiceServer).DeleteAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusinessService_DeleteAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusinessServiceServer).DeleteAttachment(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BusinessService_ServiceDesc is the grpc.ServiceDesc for BusinessService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BusinessService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "beecount.BusinessService",
+	HandlerType: (*BusinessServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Sync",
+			Handler:    _BusinessService_Sync_Handler,
+		},
+		{
+			MethodName: "GetLedgers",
+			Handler:    _BusinessService_GetLedgers_Handler,
+		},
+		{
+			MethodName: "CreateLedger",
+			Handler:    _BusinessService_CreateLedger_Handler,
+		},
+		{
+			MethodName: "UpdateLedger",
+			Handler:    _BusinessService_UpdateLedger_Handler,
+		},
+		{
+			MethodName: "DeleteLedger",
+			Handler:    _BusinessService_DeleteLedger_Handler,
+		},
+		{
+			MethodName: "CreateTransaction",
+			Handler:    _BusinessService_CreateTransaction_Handler,
+		},
+		{
+			MethodName: "UpdateTransaction",
+			Handler:    _BusinessService_UpdateTransaction_Handler,
+		},
+		{
+			MethodName: "DeleteTransaction",
+			Handler:    _BusinessService_DeleteTransaction_Handler,
+		},
+		{
+			MethodName: "ImportTransactions",
+			Handler:    _BusinessService_ImportTransactions_Handler,
+		},
+		{
+			MethodName: "SearchTransactions",
+			Handler:    _BusinessService_SearchTransactions_Handler,
+		},
+		{
+			MethodName: "InviteMember",
+			Handler:    _BusinessService_InviteMember_Handler,
+		},
+		{
+			MethodName: "AcceptInvitation",
+			Handler:    _BusinessService_AcceptInvitation_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _BusinessService_RemoveMember_Handler,
+		},
+		{
+			MethodName: "LeaveLedger",
+			Handler:    _BusinessService_LeaveLedger_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _BusinessService_ListMembers_Handler,
+		},
+		{
+			MethodName: "ListInvitations",
+			Handler:    _BusinessService_ListInvitations_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _BusinessService_GetHistory_Handler,
+		},
+		{
+			MethodName: "RestoreRevision",
+			Handler:    _BusinessService_RestoreRevision_Handler,
+		},
+		{
+			MethodName: "ArchiveLedger",
+			Handler:    _BusinessService_ArchiveLedger_Handler,
+		},
+		{
+			MethodName: "UnarchiveLedger",
+			Handler:    _BusinessService_UnarchiveLedger_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _BusinessService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreLedger",
+			Handler:    _BusinessService_RestoreLedger_Handler,
+		},
+		{
+			MethodName: "PurgeLedger",
+			Handler:    _BusinessService_PurgeLedger_Handler,
+		},
+		{
+			MethodName: "BatchCreateTransactions",
+			Handler:    _BusinessService_BatchCreateTransactions_Handler,
+		},
+		{
+			MethodName: "BatchUpdateTransactions",
+			Handler:    _BusinessService_BatchUpdateTransactions_Handler,
+		},
+		{
+			MethodName: "BatchDeleteTransactions",
+			Handler:    _BusinessService_BatchDeleteTransactions_Handler,
+		},
+		{
+			MethodName: "AttachFile",
+			Handler:    _BusinessService_AttachFile_Handler,
+		},
+		{
+			MethodName: "DetachFile",
+			Handler:    _BusinessService_DetachFile_Handler,
+		},
+		{
+			MethodName: "GetAttachment",
+			Handler:    _BusinessService_GetAttachment_Handler,
+		},
+		{
+			MethodName: "DeleteAttachment",
+			Handler:    _BusinessService_DeleteAttachment_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportLedger",
+			Handler:       _BusinessService_ExportLedger_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "business/business.proto",
 }
@@ -132,7 +132,7 @@ func (x *GetConfigRequest) GetKeys() []string {
 type GetConfigResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Configs       map[string]*ConfigItem `protobuf:"bytes,1,rep,name=configs,proto3" json:"configs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"` // 当前配置版本，与变更通知的版本对应
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -184,7 +184,7 @@ func (x *GetConfigResponse) GetVersion() string {
 // 监听配置请求
 type WatchConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"` // 可选，指定要监听的配置项，为空则监听所有
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"` // 可选，指定要监听的配置项（与GetConfig返回的键相同），为空则监听所有
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -226,13 +226,13 @@ func (x *WatchConfigRequest) GetKeys() []string {
 	return nil
 }
 
-// 配置变更通知
+// 配置变更通知，每个变化的配置项一条
 type ConfigChangeEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	OldValue      *ConfigItem            `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
-	NewValue      *ConfigItem            `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
-	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	OldValue      *ConfigItem            `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"` // 变更前的值，新增的配置项为空
+	NewValue      *ConfigItem            `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"` // 变更后的值，删除的配置项为空
+	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`                   // 变更后的配置版本，单调递增
 	Timestamp     string                 `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -306,6 +306,7 @@ func (x *ConfigChangeEvent) GetTimestamp() string {
 // 重新加载配置请求
 type ReloadConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"` // 可选，记录在新配置版本中的操作者
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -340,6 +341,331 @@ func (*ReloadConfigRequest) Descriptor() ([]byte, []int) {
 	return file_config_config_proto_rawDescGZIP(), []int{5}
 }
 
+func (x *ReloadConfigRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+// 配置版本信息
+type RevisionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Author        string                 `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"` // 配置来源：file, env, grpc
+	Timestamp     string                 `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	RollbackOf    int64                  `protobuf:"varint,5,opt,name=rollback_of,json=rollbackOf,proto3" json:"rollback_of,omitempty"` // 由回滚产生的版本为回滚到的版本号，否则为0
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevisionInfo) Reset() {
+	*x = RevisionInfo{}
+	mi := &file_config_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevisionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevisionInfo) ProtoMessage() {}
+
+func (x *RevisionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevisionInfo.ProtoReflect.Descriptor instead.
+func (*RevisionInfo) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RevisionInfo) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RevisionInfo) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *RevisionInfo) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RevisionInfo) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *RevisionInfo) GetRollbackOf() int64 {
+	if x != nil {
+		return x.RollbackOf
+	}
+	return 0
+}
+
+// 列出配置版本请求
+type ListRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // 可选，最多返回的版本数，为0则返回所有
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsRequest) Reset() {
+	*x = ListRevisionsRequest{}
+	mi := &file_config_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsRequest) ProtoMessage() {}
+
+func (x *ListRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListRevisionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 列出配置版本响应
+type ListRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*RevisionInfo        `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"` // 按版本号降序排列
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsResponse) Reset() {
+	*x = ListRevisionsResponse{}
+	mi := &file_config_config_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsResponse) ProtoMessage() {}
+
+func (x *ListRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListRevisionsResponse) GetRevisions() []*RevisionInfo {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+// 比较配置版本请求
+type DiffRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   int64                  `protobuf:"varint,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     int64                  `protobuf:"varint,2,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRevisionsRequest) Reset() {
+	*x = DiffRevisionsRequest{}
+	mi := &file_config_config_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRevisionsRequest) ProtoMessage() {}
+
+func (x *DiffRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*DiffRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DiffRevisionsRequest) GetFromVersion() int64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *DiffRevisionsRequest) GetToVersion() int64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+// 比较配置版本响应
+type DiffRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*ConfigChangeEvent   `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"` // 从from_version到to_version变化的配置项，按键排序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRevisionsResponse) Reset() {
+	*x = DiffRevisionsResponse{}
+	mi := &file_config_config_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRevisionsResponse) ProtoMessage() {}
+
+func (x *DiffRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*DiffRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DiffRevisionsResponse) GetChanges() []*ConfigChangeEvent {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// 回滚配置请求
+type RollbackConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // 回滚到的版本
+	Author        string                 `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackConfigRequest) Reset() {
+	*x = RollbackConfigRequest{}
+	mi := &file_config_config_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackConfigRequest) ProtoMessage() {}
+
+func (x *RollbackConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_config_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackConfigRequest.ProtoReflect.Descriptor instead.
+func (*RollbackConfigRequest) Descriptor() ([]byte, []int) {
+	return file_config_config_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RollbackConfigRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RollbackConfigRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
 // 启动服务请求
 type StartServiceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -351,7 +677,7 @@ type StartServiceRequest struct {
 
 func (x *StartServiceRequest) Reset() {
 	*x = StartServiceRequest{}
-	mi := &file_config_config_proto_msgTypes[6]
+	mi := &file_config_config_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -363,7 +689,7 @@ func (x *StartServiceRequest) String() string {
 func (*StartServiceRequest) ProtoMessage() {}
 
 func (x *StartServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_config_config_proto_msgTypes[6]
+	mi := &file_config_config_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -376,7 +702,7 @@ func (x *StartServiceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartServiceRequest.ProtoReflect.Descriptor instead.
 func (*StartServiceRequest) Descriptor() ([]byte, []int) {
-	return file_config_config_proto_rawDescGZIP(), []int{6}
+	return file_config_config_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *StartServiceRequest) GetServiceName() string {
@@ -405,7 +731,7 @@ type StartServiceResponse struct {
 
 func (x *StartServiceResponse) Reset() {
 	*x = StartServiceResponse{}
-	mi := &file_config_config_proto_msgTypes[7]
+	mi := &file_config_config_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -417,7 +743,7 @@ func (x *StartServiceResponse) String() string {
 func (*StartServiceResponse) ProtoMessage() {}
 
 func (x *StartServiceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_config_config_proto_msgTypes[7]
+	mi := &file_config_config_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -430,7 +756,7 @@ func (x *StartServiceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartServiceResponse.ProtoReflect.Descriptor instead.
 func (*StartServiceResponse) Descriptor() ([]byte, []int) {
-	return file_config_config_proto_rawDescGZIP(), []int{7}
+	return file_config_config_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *StartServiceResponse) GetSuccess() bool {
@@ -479,8 +805,29 @@ const file_config_config_proto_rawDesc = "" +
 	"\told_value\x18\x02 \x01(\v2\x12.config.ConfigItemR\boldValue\x12/\n" +
 	"\tnew_value\x18\x03 \x01(\v2\x12.config.ConfigItemR\bnewValue\x12\x18\n" +
 	"\aversion\x18\x04 \x01(\tR\aversion\x12\x1c\n" +
-	"\ttimestamp\x18\x05 \x01(\tR\ttimestamp\"\x15\n" +
-	"\x13ReloadConfigRequest\"\xb4\x01\n" +
+	"\ttimestamp\x18\x05 \x01(\tR\ttimestamp\"-\n" +
+	"\x13ReloadConfigRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\"\x97\x01\n" +
+	"\fRevisionInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\tR\ttimestamp\x12\x1f\n" +
+	"\vrollback_of\x18\x05 \x01(\x03R\n" +
+	"rollbackOf\",\n" +
+	"\x14ListRevisionsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"K\n" +
+	"\x15ListRevisionsResponse\x122\n" +
+	"\trevisions\x18\x01 \x03(\v2\x14.config.RevisionInfoR\trevisions\"X\n" +
+	"\x14DiffRevisionsRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\x03R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x02 \x01(\x03R\ttoVersion\"L\n" +
+	"\x15DiffRevisionsResponse\x123\n" +
+	"\achanges\x18\x01 \x03(\v2\x19.config.ConfigChangeEventR\achanges\"I\n" +
+	"\x15RollbackConfigRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\"\xb4\x01\n" +
 	"\x13StartServiceRequest\x12!\n" +
 	"\fservice_name\x18\x01 \x01(\tR\vserviceName\x12?\n" +
 	"\x06params\x18\x02 \x03(\v2'.config.StartServiceRequest.ParamsEntryR\x06params\x1a9\n" +
@@ -490,11 +837,14 @@ const file_config_config_proto_rawDesc = "" +
 	"\x14StartServiceResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x10\n" +
-	"\x03pid\x18\x03 \x01(\x05R\x03pid2\xa3\x02\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid2\x86\x04\n" +
 	"\rConfigService\x12@\n" +
 	"\tGetConfig\x12\x18.config.GetConfigRequest\x1a\x19.config.GetConfigResponse\x12F\n" +
 	"\vWatchConfig\x12\x1a.config.WatchConfigRequest\x1a\x19.config.ConfigChangeEvent0\x01\x12=\n" +
-	"\fReloadConfig\x12\x1b.config.ReloadConfigRequest\x1a\x10.common.Response\x12I\n" +
+	"\fReloadConfig\x12\x1b.config.ReloadConfigRequest\x1a\x10.common.Response\x12L\n" +
+	"\rListRevisions\x12\x1c.config.ListRevisionsRequest\x1a\x1d.config.ListRevisionsResponse\x12L\n" +
+	"\rDiffRevisions\x12\x1c.config.DiffRevisionsRequest\x1a\x1d.config.DiffRevisionsResponse\x12E\n" +
+	"\x0eRollbackConfig\x12\x1d.config.RollbackConfigRequest\x1a\x14.config.RevisionInfo\x12I\n" +
 	"\fStartService\x12\x1b.config.StartServiceRequest\x1a\x1c.config.StartServiceResponseB<Z:github.com/fishdivinity/BeeCount-Cloud/common/proto/configb\x06proto3"
 
 var (
@@ -509,39 +859,53 @@ func file_config_config_proto_rawDescGZIP() []byte {
 	return file_config_config_proto_rawDescData
 }
 
-var file_config_config_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_config_config_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_config_config_proto_goTypes = []any{
-	(*ConfigItem)(nil),           // 0: config.ConfigItem
-	(*GetConfigRequest)(nil),     // 1: config.GetConfigRequest
-	(*GetConfigResponse)(nil),    // 2: config.GetConfigResponse
-	(*WatchConfigRequest)(nil),   // 3: config.WatchConfigRequest
-	(*ConfigChangeEvent)(nil),    // 4: config.ConfigChangeEvent
-	(*ReloadConfigRequest)(nil),  // 5: config.ReloadConfigRequest
-	(*StartServiceRequest)(nil),  // 6: config.StartServiceRequest
-	(*StartServiceResponse)(nil), // 7: config.StartServiceResponse
-	nil,                          // 8: config.GetConfigResponse.ConfigsEntry
-	nil,                          // 9: config.StartServiceRequest.ParamsEntry
-	(*common.Response)(nil),      // 10: common.Response
+	(*ConfigItem)(nil),            // 0: config.ConfigItem
+	(*GetConfigRequest)(nil),      // 1: config.GetConfigRequest
+	(*GetConfigResponse)(nil),     // 2: config.GetConfigResponse
+	(*WatchConfigRequest)(nil),    // 3: config.WatchConfigRequest
+	(*ConfigChangeEvent)(nil),     // 4: config.ConfigChangeEvent
+	(*ReloadConfigRequest)(nil),   // 5: config.ReloadConfigRequest
+	(*RevisionInfo)(nil),          // 6: config.RevisionInfo
+	(*ListRevisionsRequest)(nil),  // 7: config.ListRevisionsRequest
+	(*ListRevisionsResponse)(nil), // 8: config.ListRevisionsResponse
+	(*DiffRevisionsRequest)(nil),  // 9: config.DiffRevisionsRequest
+	(*DiffRevisionsResponse)(nil), // 10: config.DiffRevisionsResponse
+	(*RollbackConfigRequest)(nil), // 11: config.RollbackConfigRequest
+	(*StartServiceRequest)(nil),   // 12: config.StartServiceRequest
+	(*StartServiceResponse)(nil),  // 13: config.StartServiceResponse
+	nil,                           // 14: config.GetConfigResponse.ConfigsEntry
+	nil,                           // 15: config.StartServiceRequest.ParamsEntry
+	(*common.Response)(nil),       // 16: common.Response
 }
 var file_config_config_proto_depIdxs = []int32{
-	8,  // 0: config.GetConfigResponse.configs:type_name -> config.GetConfigResponse.ConfigsEntry
+	14, // 0: config.GetConfigResponse.configs:type_name -> config.GetConfigResponse.ConfigsEntry
 	0,  // 1: config.ConfigChangeEvent.old_value:type_name -> config.ConfigItem
 	0,  // 2: config.ConfigChangeEvent.new_value:type_name -> config.ConfigItem
-	9,  // 3: config.StartServiceRequest.params:type_name -> config.StartServiceRequest.ParamsEntry
-	0,  // 4: config.GetConfigResponse.ConfigsEntry.value:type_name -> config.ConfigItem
-	1,  // 5: config.ConfigService.GetConfig:input_type -> config.GetConfigRequest
-	3,  // 6: config.ConfigService.WatchConfig:input_type -> config.WatchConfigRequest
-	5,  // 7: config.ConfigService.ReloadConfig:input_type -> config.ReloadConfigRequest
-	6,  // 8: config.ConfigService.StartService:input_type -> config.StartServiceRequest
-	2,  // 9: config.ConfigService.GetConfig:output_type -> config.GetConfigResponse
-	4,  // 10: config.ConfigService.WatchConfig:output_type -> config.ConfigChangeEvent
-	10, // 11: config.ConfigService.ReloadConfig:output_type -> common.Response
-	7,  // 12: config.ConfigService.StartService:output_type -> config.StartServiceResponse
-	9,  // [9:13] is the sub-list for method output_type
-	5,  // [5:9] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	6,  // 3: config.ListRevisionsResponse.revisions:type_name -> config.RevisionInfo
+	4,  // 4: config.DiffRevisionsResponse.changes:type_name -> config.ConfigChangeEvent
+	15, // 5: config.StartServiceRequest.params:type_name -> config.StartServiceRequest.ParamsEntry
+	0,  // 6: config.GetConfigResponse.ConfigsEntry.value:type_name -> config.ConfigItem
+	1,  // 7: config.ConfigService.GetConfig:input_type -> config.GetConfigRequest
+	3,  // 8: config.ConfigService.WatchConfig:input_type -> config.WatchConfigRequest
+	5,  // 9: config.ConfigService.ReloadConfig:input_type -> config.ReloadConfigRequest
+	7,  // 10: config.ConfigService.ListRevisions:input_type -> config.ListRevisionsRequest
+	9,  // 11: config.ConfigService.DiffRevisions:input_type -> config.DiffRevisionsRequest
+	11, // 12: config.ConfigService.RollbackConfig:input_type -> config.RollbackConfigRequest
+	12, // 13: config.ConfigService.StartService:input_type -> config.StartServiceRequest
+	2,  // 14: config.ConfigService.GetConfig:output_type -> config.GetConfigResponse
+	4,  // 15: config.ConfigService.WatchConfig:output_type -> config.ConfigChangeEvent
+	16, // 16: config.ConfigService.ReloadConfig:output_type -> common.Response
+	8,  // 17: config.ConfigService.ListRevisions:output_type -> config.ListRevisionsResponse
+	10, // 18: config.ConfigService.DiffRevisions:output_type -> config.DiffRevisionsResponse
+	6,  // 19: config.ConfigService.RollbackConfig:output_type -> config.RevisionInfo
+	13, // 20: config.ConfigService.StartService:output_type -> config.StartServiceResponse
+	14, // [14:21] is the sub-list for method output_type
+	7,  // [7:14] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_config_config_proto_init() }
@@ -555,7 +919,7 @@ func file_config_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_config_config_proto_rawDesc), len(file_config_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
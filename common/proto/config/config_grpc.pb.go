@@ -20,10 +20,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ConfigService_GetConfig_FullMethodName    = "/config.ConfigService/GetConfig"
-	ConfigService_WatchConfig_FullMethodName  = "/config.ConfigService/WatchConfig"
-	ConfigService_ReloadConfig_FullMethodName = "/config.ConfigService/ReloadConfig"
-	ConfigService_StartService_FullMethodName = "/config.ConfigService/StartService"
+	ConfigService_GetConfig_FullMethodName      = "/config.ConfigService/GetConfig"
+	ConfigService_WatchConfig_FullMethodName    = "/config.ConfigService/WatchConfig"
+	ConfigService_ReloadConfig_FullMethodName   = "/config.ConfigService/ReloadConfig"
+	ConfigService_ListRevisions_FullMethodName  = "/config.ConfigService/ListRevisions"
+	ConfigService_DiffRevisions_FullMethodName  = "/config.ConfigService/DiffRevisions"
+	ConfigService_RollbackConfig_FullMethodName = "/config.ConfigService/RollbackConfig"
+	ConfigService_StartService_FullMethodName   = "/config.ConfigService/StartService"
 )
 
 // ConfigServiceClient is the client API for ConfigService service.
@@ -38,6 +41,12 @@ type ConfigServiceClient interface {
 	WatchConfig(ctx context.Context, in *WatchConfigRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConfigChangeEvent], error)
 	// 重新加载配置
 	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 列出配置版本
+	ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*ListRevisionsResponse, error)
+	// 比较两个配置版本
+	DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, opts ...grpc.CallOption) (*DiffRevisionsResponse, error)
+	// 回滚到指定的配置版本，回滚后的配置保存为新版本
+	RollbackConfig(ctx context.Context, in *RollbackConfigRequest, opts ...grpc.CallOption) (*RevisionInfo, error)
 	// 启动服务
 	StartService(ctx context.Context, in *StartServiceRequest, opts ...grpc.CallOption) (*StartServiceResponse, error)
 }
@@ -89,6 +98,36 @@ func (c *configServiceClient) ReloadConfig(ctx context.Context, in *ReloadConfig
 	return out, nil
 }
 
+func (c *configServiceClient) ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*ListRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRevisionsResponse)
+	err := c.cc.Invoke(ctx, ConfigService_ListRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *configServiceClient) DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, opts ...grpc.CallOption) (*DiffRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffRevisionsResponse)
+	err := c.cc.Invoke(ctx, ConfigService_DiffRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *configServiceClient) RollbackConfig(ctx context.Context, in *RollbackConfigRequest, opts ...grpc.CallOption) (*RevisionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevisionInfo)
+	err := c.cc.Invoke(ctx, ConfigService_RollbackConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *configServiceClient) StartService(ctx context.Context, in *StartServiceRequest, opts ...grpc.CallOption) (*StartServiceResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StartServiceResponse)
@@ -111,6 +150,12 @@ type ConfigServiceServer interface {
 	WatchConfig(*WatchConfigRequest, grpc.ServerStreamingServer[ConfigChangeEvent]) error
 	// 重新加载配置
 	ReloadConfig(context.Context, *ReloadConfigRequest) (*common.Response, error)
+	// 列出配置版本
+	ListRevisions(context.Context, *ListRevisionsRequest) (*ListRevisionsResponse, error)
+	// 比较两个配置版本
+	DiffRevisions(context.Context, *DiffRevisionsRequest) (*DiffRevisionsResponse, error)
+	// 回滚到指定的配置版本，回滚后的配置保存为新版本
+	RollbackConfig(context.Context, *RollbackConfigRequest) (*RevisionInfo, error)
 	// 启动服务
 	StartService(context.Context, *StartServiceRequest) (*StartServiceResponse, error)
 	mustEmbedUnimplementedConfigServiceServer()
@@ -132,6 +177,15 @@ func (UnimplementedConfigServiceServer) WatchConfig(*WatchConfigRequest, grpc.Se
 func (UnimplementedConfigServiceServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*common.Response, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReloadConfig not implemented")
 }
+func (UnimplementedConfigServiceServer) ListRevisions(context.Context, *ListRevisionsRequest) (*ListRevisionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRevisions not implemented")
+}
+func (UnimplementedConfigServiceServer) DiffRevisions(context.Context, *DiffRevisionsRequest) (*DiffRevisionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffRevisions not implemented")
+}
+func (UnimplementedConfigServiceServer) RollbackConfig(context.Context, *RollbackConfigRequest) (*RevisionInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method RollbackConfig not implemented")
+}
 func (UnimplementedConfigServiceServer) StartService(context.Context, *StartServiceRequest) (*StartServiceResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method StartService not implemented")
 }
@@ -203,6 +257,60 @@ func _ConfigService_ReloadConfig_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConfigService_ListRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServiceServer).ListRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConfigService_ListRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServiceServer).ListRevisions(ctx, req.(*ListRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfigService_DiffRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServiceServer).DiffRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConfigService_DiffRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServiceServer).DiffRevisions(ctx, req.(*DiffRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfigService_RollbackConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServiceServer).RollbackConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConfigService_RollbackConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServiceServer).RollbackConfig(ctx, req.(*RollbackConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ConfigService_StartService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartServiceRequest)
 	if err := dec(in); err != nil {
@@ -236,6 +344,18 @@ var ConfigService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReloadConfig",
 			Handler:    _ConfigService_ReloadConfig_Handler,
 		},
+		{
+			MethodName: "ListRevisions",
+			Handler:    _ConfigService_ListRevisions_Handler,
+		},
+		{
+			MethodName: "DiffRevisions",
+			Handler:    _ConfigService_DiffRevisions_Handler,
+		},
+		{
+			MethodName: "RollbackConfig",
+			Handler:    _ConfigService_RollbackConfig_Handler,
+		},
 		{
 			MethodName: "StartService",
 			Handler:    _ConfigService_StartService_Handler,
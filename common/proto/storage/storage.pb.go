@@ -33,6 +33,7 @@ type FileInfo struct {
 	StoragePath   string                 `protobuf:"bytes,6,opt,name=storage_path,json=storagePath,proto3" json:"storage_path,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Checksum      string                 `protobuf:"bytes,9,opt,name=checksum,proto3" json:"checksum,omitempty"` // 文件内容的SHA-256，十六进制
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -123,6 +124,13 @@ func (x *FileInfo) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *FileInfo) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
 // 上传文件请求
 type UploadFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -132,6 +140,7 @@ type UploadFileRequest struct {
 	Chunk         []byte                 `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	IsLastChunk   bool                   `protobuf:"varint,5,opt,name=is_last_chunk,json=isLastChunk,proto3" json:"is_last_chunk,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Export        bool                   `protobuf:"varint,7,opt,name=export,proto3" json:"export,omitempty"` // 账本导出文件，只由网关的导出接口设置，不受上传大小、类型和存储配额限制
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -208,6 +217,13 @@ func (x *UploadFileRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *UploadFileRequest) GetExport() bool {
+	if x != nil {
+		return x.Export
+	}
+	return false
+}
+
 // 上传文件响应
 type UploadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -274,6 +290,9 @@ type DownloadFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`  // 起始字节位置
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`  // 读取的字节数，0表示读取到文件末尾
+	Variant       string                 `protobuf:"bytes,5,opt,name=variant,proto3" json:"variant,omitempty"` // 图片版本：thumbnail（缩略图）、medium（预览图），为空时下载原文件
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -322,6 +341,27 @@ func (x *DownloadFileRequest) GetUserId() string {
 	return ""
 }
 
+func (x *DownloadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
 // 下载文件响应
 type DownloadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -441,6 +481,7 @@ type GetFileInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Variant       string                 `protobuf:"bytes,3,opt,name=variant,proto3" json:"variant,omitempty"` // 图片版本，为空时返回原文件信息
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -489,58 +530,1128 @@ func (x *GetFileInfoRequest) GetUserId() string {
 	return ""
 }
 
-var File_storage_storage_proto protoreflect.FileDescriptor
+func (x *GetFileInfoRequest) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
 
-const file_storage_storage_proto_rawDesc = "" +
-	"\n" +
-	"\x15storage/storage.proto\x12\astorage\x1a\x13common/common.proto\"\xc2\x02\n" +
-	"\bFileInfo\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
-	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
-	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
-	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x17\n" +
-	"\auser_id\x18\x05 \x01(\tR\x06userId\x12!\n" +
-	"\fstorage_path\x18\x06 \x01(\tR\vstoragePath\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\a \x01(\tR\tcreatedAt\x12;\n" +
-	"\bmetadata\x18\b \x03(\v2\x1f.storage.FileInfo.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa8\x02\n" +
-	"\x11UploadFileRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
-	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
-	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x14\n" +
-	"\x05chunk\x18\x04 \x01(\fR\x05chunk\x12\"\n" +
-	"\ris_last_chunk\x18\x05 \x01(\bR\visLastChunk\x12D\n" +
-	"\bmetadata\x18\x06 \x03(\v2(.storage.UploadFileRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x01\n" +
-	"\x12UploadFileResponse\x12.\n" +
-	"\tfile_info\x18\x01 \x01(\v2\x11.storage.FileInfoR\bfileInfo\x12%\n" +
-	"\x0euploaded_bytes\x18\x02 \x01(\x03R\ruploadedBytes\x12\x1c\n" +
-	"\tcompleted\x18\x03 \x01(\bR\tcompleted\"G\n" +
-	"\x13DownloadFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x80\x01\n" +
-	"\x14DownloadFileResponse\x12\x14\n" +
-	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\"\n" +
-	"\ris_last_chunk\x18\x02 \x01(\bR\visLastChunk\x12.\n" +
-	"\tfile_info\x18\x03 \x01(\v2\x11.storage.FileInfoR\bfileInfo\"E\n" +
-	"\x11DeleteFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"F\n" +
-	"\x12GetFileInfoRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId2\xa3\x02\n" +
+// 上传会话，用于断点续传
+type UploadSession struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`     // 文件总大小
+	Offset        int64                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"` // 已提交的字节数，续传从该位置开始
+	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // 会话过期时间，每次写入后顺延
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSession) Reset() {
+	*x = UploadSession{}
+	mi := &file_storage_storage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSession) ProtoMessage() {}
+
+func (x *UploadSession) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSession.ProtoReflect.Descriptor instead.
+func (*UploadSession) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UploadSession) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UploadSession) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UploadSession) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadSession) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *UploadSession) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadSession) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UploadSession) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UploadSession) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *UploadSession) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+// 创建上传会话请求
+type CreateUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUploadSessionRequest) Reset() {
+	*x = CreateUploadSessionRequest{}
+	mi := &file_storage_storage_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUploadSessionRequest) ProtoMessage() {}
+
+func (x *CreateUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateUploadSessionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CreateUploadSessionRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// 写入上传会话请求，第一条消息需包含会话ID、用户ID和写入位置
+type UploadChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"` // 必须等于会话已提交的字节数
+	Chunk         []byte                 `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadChunkRequest) Reset() {
+	*x = UploadChunkRequest{}
+	mi := &file_storage_storage_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadChunkRequest) ProtoMessage() {}
+
+func (x *UploadChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadChunkRequest.ProtoReflect.Descriptor instead.
+func (*UploadChunkRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UploadChunkRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadChunkRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UploadChunkRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UploadChunkRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+// 上传会话请求
+type UploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionRequest) Reset() {
+	*x = UploadSessionRequest{}
+	mi := &file_storage_storage_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionRequest) ProtoMessage() {}
+
+func (x *UploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*UploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UploadSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadSessionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 完成上传会话请求
+type FinalizeUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"` // 完整文件内容的SHA-256，十六进制
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalizeUploadSessionRequest) Reset() {
+	*x = FinalizeUploadSessionRequest{}
+	mi := &file_storage_storage_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizeUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizeUploadSessionRequest) ProtoMessage() {}
+
+func (x *FinalizeUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizeUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*FinalizeUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FinalizeUploadSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *FinalizeUploadSessionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *FinalizeUploadSessionRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// 获取存储用量请求
+type GetUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageRequest) Reset() {
+	*x = GetUsageRequest{}
+	mi := &file_storage_storage_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageRequest) ProtoMessage() {}
+
+func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetUsageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 用户的存储用量，内容相同的文件在存储后端只保存一份，但分别计入各自所有者的用量
+type StorageUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UsedBytes     int64                  `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`    // 已使用的字节数
+	QuotaBytes    int64                  `protobuf:"varint,3,opt,name=quota_bytes,json=quotaBytes,proto3" json:"quota_bytes,omitempty"` // 存储配额（字节），0表示不限制
+	FileCount     int64                  `protobuf:"varint,4,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`    // 文件数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageUsage) Reset() {
+	*x = StorageUsage{}
+	mi := &file_storage_storage_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageUsage) ProtoMessage() {}
+
+func (x *StorageUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageUsage.ProtoReflect.Descriptor instead.
+func (*StorageUsage) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StorageUsage) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *StorageUsage) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *StorageUsage) GetQuotaBytes() int64 {
+	if x != nil {
+		return x.QuotaBytes
+	}
+	return 0
+}
+
+func (x *StorageUsage) GetFileCount() int64 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+// 生成签名URL请求
+type CreateSignedURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Variant       string                 `protobuf:"bytes,3,opt,name=variant,proto3" json:"variant,omitempty"`                       // 图片版本，为空时指向原文件
+	ExpiresIn     int64                  `protobuf:"varint,4,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"` // 有效期（秒），0表示使用配置的有效期，不能超过配置的有效期
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSignedURLRequest) Reset() {
+	*x = CreateSignedURLRequest{}
+	mi := &file_storage_storage_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSignedURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSignedURLRequest) ProtoMessage() {}
+
+func (x *CreateSignedURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSignedURLRequest.ProtoReflect.Descriptor instead.
+func (*CreateSignedURLRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateSignedURLRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *CreateSignedURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateSignedURLRequest) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *CreateSignedURLRequest) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+// 签名URL，无需认证即可在有效期内下载文件
+type SignedURL struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                              // 以storage.local.url_prefix开头的相对URL
+	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // 过期时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedURL) Reset() {
+	*x = SignedURL{}
+	mi := &file_storage_storage_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedURL) ProtoMessage() {}
+
+func (x *SignedURL) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedURL.ProtoReflect.Descriptor instead.
+func (*SignedURL) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SignedURL) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *SignedURL) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+// 校验签名URL请求，字段取自签名URL的路径和查询参数
+type VerifySignedURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Variant       string                 `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`
+	Expires       int64                  `protobuf:"varint,3,opt,name=expires,proto3" json:"expires,omitempty"` // 过期时间（Unix秒）
+	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifySignedURLRequest) Reset() {
+	*x = VerifySignedURLRequest{}
+	mi := &file_storage_storage_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifySignedURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifySignedURLRequest) ProtoMessage() {}
+
+func (x *VerifySignedURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifySignedURLRequest.ProtoReflect.Descriptor instead.
+func (*VerifySignedURLRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VerifySignedURLRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *VerifySignedURLRequest) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *VerifySignedURLRequest) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+func (x *VerifySignedURLRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+// 存储巡检请求
+type ScrubRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	DryRun          bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                            // 只报告发现的问题，不处理孤立对象
+	Action          string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`                                           // 孤立对象的处理方式：quarantine（移动到隔离目录，默认）或remove（删除）
+	VerifyChecksums bool                   `protobuf:"varint,3,opt,name=verify_checksums,json=verifyChecksums,proto3" json:"verify_checksums,omitempty"` // 读取对象内容校验SHA-256
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ScrubRequest) Reset() {
+	*x = ScrubRequest{}
+	mi := &file_storage_storage_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrubRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrubRequest) ProtoMessage() {}
+
+func (x *ScrubRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrubRequest.ProtoReflect.Descriptor instead.
+func (*ScrubRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ScrubRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ScrubRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScrubRequest) GetVerifyChecksums() bool {
+	if x != nil {
+		return x.VerifyChecksums
+	}
+	return false
+}
+
+// 获取巡检报告请求
+type GetScrubReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetScrubReportRequest) Reset() {
+	*x = GetScrubReportRequest{}
+	mi := &file_storage_storage_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetScrubReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScrubReportRequest) ProtoMessage() {}
+
+func (x *GetScrubReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScrubReportRequest.ProtoReflect.Descriptor instead.
+func (*GetScrubReportRequest) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{18}
+}
+
+// 巡检发现的问题
+type ScrubFinding struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 问题类型：orphan_object（没有元数据引用的对象）、missing_object（元数据引用的对象不存在）、
+	// checksum_mismatch（内容与记录的校验和不符）、unreadable_object（对象无法读取或解密）
+	Kind          string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Backend       string `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	StoragePath   string `protobuf:"bytes,3,opt,name=storage_path,json=storagePath,proto3" json:"storage_path,omitempty"`
+	Size          int64  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Reference     string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"` // 引用该对象的记录：blob:{checksum}、file:{id}或variant:{file_id}/{variant}
+	Detail        string `protobuf:"bytes,6,opt,name=detail,proto3" json:"detail,omitempty"`
+	Action        string `protobuf:"bytes,7,opt,name=action,proto3" json:"action,omitempty"` // 已执行的处理：quarantined、removed，为空表示未处理
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrubFinding) Reset() {
+	*x = ScrubFinding{}
+	mi := &file_storage_storage_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrubFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrubFinding) ProtoMessage() {}
+
+func (x *ScrubFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrubFinding.ProtoReflect.Descriptor instead.
+func (*ScrubFinding) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ScrubFinding) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ScrubFinding) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *ScrubFinding) GetStoragePath() string {
+	if x != nil {
+		return x.StoragePath
+	}
+	return ""
+}
+
+func (x *ScrubFinding) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ScrubFinding) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *ScrubFinding) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *ScrubFinding) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+// 巡检报告
+type ScrubReport struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	DryRun            bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Action            string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	StartedAt         string                 `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt        string                 `protobuf:"bytes,4,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	ObjectsScanned    int64                  `protobuf:"varint,5,opt,name=objects_scanned,json=objectsScanned,proto3" json:"objects_scanned,omitempty"`          // 存储后端中检查的对象数
+	ReferencesChecked int64                  `protobuf:"varint,6,opt,name=references_checked,json=referencesChecked,proto3" json:"references_checked,omitempty"` // 检查的元数据引用数
+	Findings          []*ScrubFinding        `protobuf:"bytes,7,rep,name=findings,proto3" json:"findings,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ScrubReport) Reset() {
+	*x = ScrubReport{}
+	mi := &file_storage_storage_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrubReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrubReport) ProtoMessage() {}
+
+func (x *ScrubReport) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_storage_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrubReport.ProtoReflect.Descriptor instead.
+func (*ScrubReport) Descriptor() ([]byte, []int) {
+	return file_storage_storage_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ScrubReport) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ScrubReport) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScrubReport) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *ScrubReport) GetFinishedAt() string {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return ""
+}
+
+func (x *ScrubReport) GetObjectsScanned() int64 {
+	if x != nil {
+		return x.ObjectsScanned
+	}
+	return 0
+}
+
+func (x *ScrubReport) GetReferencesChecked() int64 {
+	if x != nil {
+		return x.ReferencesChecked
+	}
+	return 0
+}
+
+func (x *ScrubReport) GetFindings() []*ScrubFinding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+var File_storage_storage_proto protoreflect.FileDescriptor
+
+const file_storage_storage_proto_rawDesc = "" +
+	"\n" +
+	"\x15storage/storage.proto\x12\astorage\x1a\x13common/common.proto\"\xde\x02\n" +
+	"\bFileInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12!\n" +
+	"\fstorage_path\x18\x06 \x01(\tR\vstoragePath\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12;\n" +
+	"\bmetadata\x18\b \x03(\v2\x1f.storage.FileInfo.MetadataEntryR\bmetadata\x12\x1a\n" +
+	"\bchecksum\x18\t \x01(\tR\bchecksum\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc0\x02\n" +
+	"\x11UploadFileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05chunk\x18\x04 \x01(\fR\x05chunk\x12\"\n" +
+	"\ris_last_chunk\x18\x05 \x01(\bR\visLastChunk\x12D\n" +
+	"\bmetadata\x18\x06 \x03(\v2(.storage.UploadFileRequest.MetadataEntryR\bmetadata\x12\x16\n" +
+	"\x06export\x18\a \x01(\bR\x06export\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x01\n" +
+	"\x12UploadFileResponse\x12.\n" +
+	"\tfile_info\x18\x01 \x01(\v2\x11.storage.FileInfoR\bfileInfo\x12%\n" +
+	"\x0euploaded_bytes\x18\x02 \x01(\x03R\ruploadedBytes\x12\x1c\n" +
+	"\tcompleted\x18\x03 \x01(\bR\tcompleted\"\x91\x01\n" +
+	"\x13DownloadFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\x12\x18\n" +
+	"\avariant\x18\x05 \x01(\tR\avariant\"\x80\x01\n" +
+	"\x14DownloadFileResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\"\n" +
+	"\ris_last_chunk\x18\x02 \x01(\bR\visLastChunk\x12.\n" +
+	"\tfile_info\x18\x03 \x01(\v2\x11.storage.FileInfoR\bfileInfo\"E\n" +
+	"\x11DeleteFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"`\n" +
+	"\x12GetFileInfoRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x18\n" +
+	"\avariant\x18\x03 \x01(\tR\avariant\"\xe0\x02\n" +
+	"\rUploadSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bfilename\x18\x03 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x03R\x06offset\x12@\n" +
+	"\bmetadata\x18\a \x03(\v2$.storage.UploadSession.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\tR\texpiresAt\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x94\x02\n" +
+	"\x1aCreateUploadSessionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12M\n" +
+	"\bmetadata\x18\x05 \x03(\v21.storage.CreateUploadSessionRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"z\n" +
+	"\x12UploadChunkRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x14\n" +
+	"\x05chunk\x18\x04 \x01(\fR\x05chunk\"N\n" +
+	"\x14UploadSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"n\n" +
+	"\x1cFinalizeUploadSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\"*\n" +
+	"\x0fGetUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x86\x01\n" +
+	"\fStorageUsage\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x03R\tusedBytes\x12\x1f\n" +
+	"\vquota_bytes\x18\x03 \x01(\x03R\n" +
+	"quotaBytes\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\x04 \x01(\x03R\tfileCount\"\x83\x01\n" +
+	"\x16CreateSignedURLRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x18\n" +
+	"\avariant\x18\x03 \x01(\tR\avariant\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x04 \x01(\x03R\texpiresIn\"<\n" +
+	"\tSignedURL\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"\x83\x01\n" +
+	"\x16VerifySignedURLRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\avariant\x18\x02 \x01(\tR\avariant\x12\x18\n" +
+	"\aexpires\x18\x03 \x01(\x03R\aexpires\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\"j\n" +
+	"\fScrubRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12)\n" +
+	"\x10verify_checksums\x18\x03 \x01(\bR\x0fverifyChecksums\"\x17\n" +
+	"\x15GetScrubReportRequest\"\xc1\x01\n" +
+	"\fScrubFinding\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x18\n" +
+	"\abackend\x18\x02 \x01(\tR\abackend\x12!\n" +
+	"\fstorage_path\x18\x03 \x01(\tR\vstoragePath\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\x12\x16\n" +
+	"\x06detail\x18\x06 \x01(\tR\x06detail\x12\x16\n" +
+	"\x06action\x18\a \x01(\tR\x06action\"\x89\x02\n" +
+	"\vScrubReport\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x03 \x01(\tR\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\x04 \x01(\tR\n" +
+	"finishedAt\x12'\n" +
+	"\x0fobjects_scanned\x18\x05 \x01(\x03R\x0eobjectsScanned\x12-\n" +
+	"\x12references_checked\x18\x06 \x01(\x03R\x11referencesChecked\x121\n" +
+	"\bfindings\x18\a \x03(\v2\x15.storage.ScrubFindingR\bfindings2\xf7\a\n" +
 	"\x0eStorageService\x12G\n" +
 	"\n" +
 	"UploadFile\x12\x1a.storage.UploadFileRequest\x1a\x1b.storage.UploadFileResponse(\x01\x12M\n" +
 	"\fDownloadFile\x12\x1c.storage.DownloadFileRequest\x1a\x1d.storage.DownloadFileResponse0\x01\x12:\n" +
 	"\n" +
 	"DeleteFile\x12\x1a.storage.DeleteFileRequest\x1a\x10.common.Response\x12=\n" +
-	"\vGetFileInfo\x12\x1b.storage.GetFileInfoRequest\x1a\x11.storage.FileInfoB=Z;github.com/fishdivinity/BeeCount-Cloud/common/proto/storageb\x06proto3"
+	"\vGetFileInfo\x12\x1b.storage.GetFileInfoRequest\x1a\x11.storage.FileInfo\x12R\n" +
+	"\x13CreateUploadSession\x12#.storage.CreateUploadSessionRequest\x1a\x16.storage.UploadSession\x12D\n" +
+	"\vUploadChunk\x12\x1b.storage.UploadChunkRequest\x1a\x16.storage.UploadSession(\x01\x12I\n" +
+	"\x10GetUploadSession\x12\x1d.storage.UploadSessionRequest\x1a\x16.storage.UploadSession\x12[\n" +
+	"\x15FinalizeUploadSession\x12%.storage.FinalizeUploadSessionRequest\x1a\x1b.storage.UploadFileResponse\x12F\n" +
+	"\x13CancelUploadSession\x12\x1d.storage.UploadSessionRequest\x1a\x10.common.Response\x12;\n" +
+	"\bGetUsage\x12\x18.storage.GetUsageRequest\x1a\x15.storage.StorageUsage\x12F\n" +
+	"\x0fCreateSignedURL\x12\x1f.storage.CreateSignedURLRequest\x1a\x12.storage.SignedURL\x12E\n" +
+	"\x0fVerifySignedURL\x12\x1f.storage.VerifySignedURLRequest\x1a\x11.storage.FileInfo\x124\n" +
+	"\x05Scrub\x12\x15.storage.ScrubRequest\x1a\x14.storage.ScrubReport\x12F\n" +
+	"\x0eGetScrubReport\x12\x1e.storage.GetScrubReportRequest\x1a\x14.storage.ScrubReportB=Z;github.com/fishdivinity/BeeCount-Cloud/common/proto/storageb\x06proto3"
 
 var (
 	file_storage_storage_proto_rawDescOnce sync.Once
@@ -554,37 +1665,76 @@ func file_storage_storage_proto_rawDescGZIP() []byte {
 	return file_storage_storage_proto_rawDescData
 }
 
-var file_storage_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_storage_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_storage_storage_proto_goTypes = []any{
-	(*FileInfo)(nil),             // 0: storage.FileInfo
-	(*UploadFileRequest)(nil),    // 1: storage.UploadFileRequest
-	(*UploadFileResponse)(nil),   // 2: storage.UploadFileResponse
-	(*DownloadFileRequest)(nil),  // 3: storage.DownloadFileRequest
-	(*DownloadFileResponse)(nil), // 4: storage.DownloadFileResponse
-	(*DeleteFileRequest)(nil),    // 5: storage.DeleteFileRequest
-	(*GetFileInfoRequest)(nil),   // 6: storage.GetFileInfoRequest
-	nil,                          // 7: storage.FileInfo.MetadataEntry
-	nil,                          // 8: storage.UploadFileRequest.MetadataEntry
-	(*common.Response)(nil),      // 9: common.Response
+	(*FileInfo)(nil),                     // 0: storage.FileInfo
+	(*UploadFileRequest)(nil),            // 1: storage.UploadFileRequest
+	(*UploadFileResponse)(nil),           // 2: storage.UploadFileResponse
+	(*DownloadFileRequest)(nil),          // 3: storage.DownloadFileRequest
+	(*DownloadFileResponse)(nil),         // 4: storage.DownloadFileResponse
+	(*DeleteFileRequest)(nil),            // 5: storage.DeleteFileRequest
+	(*GetFileInfoRequest)(nil),           // 6: storage.GetFileInfoRequest
+	(*UploadSession)(nil),                // 7: storage.UploadSession
+	(*CreateUploadSessionRequest)(nil),   // 8: storage.CreateUploadSessionRequest
+	(*UploadChunkRequest)(nil),           // 9: storage.UploadChunkRequest
+	(*UploadSessionRequest)(nil),         // 10: storage.UploadSessionRequest
+	(*FinalizeUploadSessionRequest)(nil), // 11: storage.FinalizeUploadSessionRequest
+	(*GetUsageRequest)(nil),              // 12: storage.GetUsageRequest
+	(*StorageUsage)(nil),                 // 13: storage.StorageUsage
+	(*CreateSignedURLRequest)(nil),       // 14: storage.CreateSignedURLRequest
+	(*SignedURL)(nil),                    // 15: storage.SignedURL
+	(*VerifySignedURLRequest)(nil),       // 16: storage.VerifySignedURLRequest
+	(*ScrubRequest)(nil),                 // 17: storage.ScrubRequest
+	(*GetScrubReportRequest)(nil),        // 18: storage.GetScrubReportRequest
+	(*ScrubFinding)(nil),                 // 19: storage.ScrubFinding
+	(*ScrubReport)(nil),                  // 20: storage.ScrubReport
+	nil,                                  // 21: storage.FileInfo.MetadataEntry
+	nil,                                  // 22: storage.UploadFileRequest.MetadataEntry
+	nil,                                  // 23: storage.UploadSession.MetadataEntry
+	nil,                                  // 24: storage.CreateUploadSessionRequest.MetadataEntry
+	(*common.Response)(nil),              // 25: common.Response
 }
 var file_storage_storage_proto_depIdxs = []int32{
-	7, // 0: storage.FileInfo.metadata:type_name -> storage.FileInfo.MetadataEntry
-	8, // 1: storage.UploadFileRequest.metadata:type_name -> storage.UploadFileRequest.MetadataEntry
-	0, // 2: storage.UploadFileResponse.file_info:type_name -> storage.FileInfo
-	0, // 3: storage.DownloadFileResponse.file_info:type_name -> storage.FileInfo
-	1, // 4: storage.StorageService.UploadFile:input_type -> storage.UploadFileRequest
-	3, // 5: storage.StorageService.DownloadFile:input_type -> storage.DownloadFileRequest
-	5, // 6: storage.StorageService.DeleteFile:input_type -> storage.DeleteFileRequest
-	6, // 7: storage.StorageService.GetFileInfo:input_type -> storage.GetFileInfoRequest
-	2, // 8: storage.StorageService.UploadFile:output_type -> storage.UploadFileResponse
-	4, // 9: storage.StorageService.DownloadFile:output_type -> storage.DownloadFileResponse
-	9, // 10: storage.StorageService.DeleteFile:output_type -> common.Response
-	0, // 11: storage.StorageService.GetFileInfo:output_type -> storage.FileInfo
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	21, // 0: storage.FileInfo.metadata:type_name -> storage.FileInfo.MetadataEntry
+	22, // 1: storage.UploadFileRequest.metadata:type_name -> storage.UploadFileRequest.MetadataEntry
+	0,  // 2: storage.UploadFileResponse.file_info:type_name -> storage.FileInfo
+	0,  // 3: storage.DownloadFileResponse.file_info:type_name -> storage.FileInfo
+	23, // 4: storage.UploadSession.metadata:type_name -> storage.UploadSession.MetadataEntry
+	24, // 5: storage.CreateUploadSessionRequest.metadata:type_name -> storage.CreateUploadSessionRequest.MetadataEntry
+	19, // 6: storage.ScrubReport.findings:type_name -> storage.ScrubFinding
+	1,  // 7: storage.StorageService.UploadFile:input_type -> storage.UploadFileRequest
+	3,  // 8: storage.StorageService.DownloadFile:input_type -> storage.DownloadFileRequest
+	5,  // 9: storage.StorageService.DeleteFile:input_type -> storage.DeleteFileRequest
+	6,  // 10: storage.StorageService.GetFileInfo:input_type -> storage.GetFileInfoRequest
+	8,  // 11: storage.StorageService.CreateUploadSession:input_type -> storage.CreateUploadSessionRequest
+	9,  // 12: storage.StorageService.UploadChunk:input_type -> storage.UploadChunkRequest
+	10, // 13: storage.StorageService.GetUploadSession:input_type -> storage.UploadSessionRequest
+	11, // 14: storage.StorageService.FinalizeUploadSession:input_type -> storage.FinalizeUploadSessionRequest
+	10, // 15: storage.StorageService.CancelUploadSession:input_type -> storage.UploadSessionRequest
+	12, // 16: storage.StorageService.GetUsage:input_type -> storage.GetUsageRequest
+	14, // 17: storage.StorageService.CreateSignedURL:input_type -> storage.CreateSignedURLRequest
+	16, // 18: storage.StorageService.VerifySignedURL:input_type -> storage.VerifySignedURLRequest
+	17, // 19: storage.StorageService.Scrub:input_type -> storage.ScrubRequest
+	18, // 20: storage.StorageService.GetScrubReport:input_type -> storage.GetScrubReportRequest
+	2,  // 21: storage.StorageService.UploadFile:output_type -> storage.UploadFileResponse
+	4,  // 22: storage.StorageService.DownloadFile:output_type -> storage.DownloadFileResponse
+	25, // 23: storage.StorageService.DeleteFile:output_type -> common.Response
+	0,  // 24: storage.StorageService.GetFileInfo:output_type -> storage.FileInfo
+	7,  // 25: storage.StorageService.CreateUploadSession:output_type -> storage.UploadSession
+	7,  // 26: storage.StorageService.UploadChunk:output_type -> storage.UploadSession
+	7,  // 27: storage.StorageService.GetUploadSession:output_type -> storage.UploadSession
+	2,  // 28: storage.StorageService.FinalizeUploadSession:output_type -> storage.UploadFileResponse
+	25, // 29: storage.StorageService.CancelUploadSession:output_type -> common.Response
+	13, // 30: storage.StorageService.GetUsage:output_type -> storage.StorageUsage
+	15, // 31: storage.StorageService.CreateSignedURL:output_type -> storage.SignedURL
+	0,  // 32: storage.StorageService.VerifySignedURL:output_type -> storage.FileInfo
+	20, // 33: storage.StorageService.Scrub:output_type -> storage.ScrubReport
+	20, // 34: storage.StorageService.GetScrubReport:output_type -> storage.ScrubReport
+	21, // [21:35] is the sub-list for method output_type
+	7,  // [7:21] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_storage_storage_proto_init() }
@@ -598,7 +1748,7 @@ func file_storage_storage_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_storage_storage_proto_rawDesc), len(file_storage_storage_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	StorageService_UploadFile_FullMethodName   = "/storage.StorageService/UploadFile"
-	StorageService_DownloadFile_FullMethodName = "/storage.StorageService/DownloadFile"
-	StorageService_DeleteFile_FullMethodName   = "/storage.StorageService/DeleteFile"
-	StorageService_GetFileInfo_FullMethodName  = "/storage.StorageService/GetFileInfo"
+	StorageService_UploadFile_FullMethodName            = "/storage.StorageService/UploadFile"
+	StorageService_DownloadFile_FullMethodName          = "/storage.StorageService/DownloadFile"
+	StorageService_DeleteFile_FullMethodName            = "/storage.StorageService/DeleteFile"
+	StorageService_GetFileInfo_FullMethodName           = "/storage.StorageService/GetFileInfo"
+	StorageService_CreateUploadSession_FullMethodName   = "/storage.StorageService/CreateUploadSession"
+	StorageService_UploadChunk_FullMethodName           = "/storage.StorageService/UploadChunk"
+	StorageService_GetUploadSession_FullMethodName      = "/storage.StorageService/GetUploadSession"
+	StorageService_FinalizeUploadSession_FullMethodName = "/storage.StorageService/FinalizeUploadSession"
+	StorageService_CancelUploadSession_FullMethodName   = "/storage.StorageService/CancelUploadSession"
+	StorageService_GetUsage_FullMethodName              = "/storage.StorageService/GetUsage"
+	StorageService_CreateSignedURL_FullMethodName       = "/storage.StorageService/CreateSignedURL"
+	StorageService_VerifySignedURL_FullMethodName       = "/storage.StorageService/VerifySignedURL"
+	StorageService_Scrub_FullMethodName                 = "/storage.StorageService/Scrub"
+	StorageService_GetScrubReport_FullMethodName        = "/storage.StorageService/GetScrubReport"
 )
 
 // StorageServiceClient is the client API for StorageService service.
@@ -40,6 +50,26 @@ type StorageServiceClient interface {
 	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*common.Response, error)
 	// 获取文件信息
 	GetFileInfo(ctx context.Context, in *GetFileInfoRequest, opts ...grpc.CallOption) (*FileInfo, error)
+	// 创建上传会话
+	CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*UploadSession, error)
+	// 向上传会话写入文件块（支持流式上传），中断时已接收的内容仍会提交
+	UploadChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadChunkRequest, UploadSession], error)
+	// 获取上传会话，用于查询已提交的字节数
+	GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*UploadSession, error)
+	// 完成上传会话，校验SHA-256后保存为文件
+	FinalizeUploadSession(ctx context.Context, in *FinalizeUploadSessionRequest, opts ...grpc.CallOption) (*UploadFileResponse, error)
+	// 取消上传会话
+	CancelUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// 获取用户的存储用量和配额
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*StorageUsage, error)
+	// 生成带HMAC签名、限时有效的下载URL
+	CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*SignedURL, error)
+	// 校验签名URL，通过时返回原文件信息
+	VerifySignedURL(ctx context.Context, in *VerifySignedURLRequest, opts ...grpc.CallOption) (*FileInfo, error)
+	// 巡检存储后端，比对对象与元数据并校验内容，处理孤立对象
+	Scrub(ctx context.Context, in *ScrubRequest, opts ...grpc.CallOption) (*ScrubReport, error)
+	// 获取最近一次巡检的报告
+	GetScrubReport(ctx context.Context, in *GetScrubReportRequest, opts ...grpc.CallOption) (*ScrubReport, error)
 }
 
 type storageServiceClient struct {
@@ -102,6 +132,109 @@ func (c *storageServiceClient) GetFileInfo(ctx context.Context, in *GetFileInfoR
 	return out, nil
 }
 
+func (c *storageServiceClient) CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*UploadSession, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSession)
+	err := c.cc.Invoke(ctx, StorageService_CreateUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) UploadChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadChunkRequest, UploadSession], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[2], StorageService_UploadChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadChunkRequest, UploadSession]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StorageService_UploadChunkClient = grpc.ClientStreamingClient[UploadChunkRequest, UploadSession]
+
+func (c *storageServiceClient) GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*UploadSession, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSession)
+	err := c.cc.Invoke(ctx, StorageService_GetUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) FinalizeUploadSession(ctx context.Context, in *FinalizeUploadSessionRequest, opts ...grpc.CallOption) (*UploadFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadFileResponse)
+	err := c.cc.Invoke(ctx, StorageService_FinalizeUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) CancelUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, StorageService_CancelUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*StorageUsage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StorageUsage)
+	err := c.cc.Invoke(ctx, StorageService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*SignedURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignedURL)
+	err := c.cc.Invoke(ctx, StorageService_CreateSignedURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) VerifySignedURL(ctx context.Context, in *VerifySignedURLRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, StorageService_VerifySignedURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) Scrub(ctx context.Context, in *ScrubRequest, opts ...grpc.CallOption) (*ScrubReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScrubReport)
+	err := c.cc.Invoke(ctx, StorageService_Scrub_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetScrubReport(ctx context.Context, in *GetScrubReportRequest, opts ...grpc.CallOption) (*ScrubReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScrubReport)
+	err := c.cc.Invoke(ctx, StorageService_GetScrubReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StorageServiceServer is the server API for StorageService service.
 // All implementations must embed UnimplementedStorageServiceServer
 // for forward compatibility.
@@ -116,6 +249,26 @@ type StorageServiceServer interface {
 	DeleteFile(context.Context, *DeleteFileRequest) (*common.Response, error)
 	// 获取文件信息
 	GetFileInfo(context.Context, *GetFileInfoRequest) (*FileInfo, error)
+	// 创建上传会话
+	CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*UploadSession, error)
+	// 向上传会话写入文件块（支持流式上传），中断时已接收的内容仍会提交
+	UploadChunk(grpc.ClientStreamingServer[UploadChunkRequest, UploadSession]) error
+	// 获取上传会话，用于查询已提交的字节数
+	GetUploadSession(context.Context, *UploadSessionRequest) (*UploadSession, error)
+	// 完成上传会话，校验SHA-256后保存为文件
+	FinalizeUploadSession(context.Context, *FinalizeUploadSessionRequest) (*UploadFileResponse, error)
+	// 取消上传会话
+	CancelUploadSession(context.Context, *UploadSessionRequest) (*common.Response, error)
+	// 获取用户的存储用量和配额
+	GetUsage(context.Context, *GetUsageRequest) (*StorageUsage, error)
+	// 生成带HMAC签名、限时有效的下载URL
+	CreateSignedURL(context.Context, *CreateSignedURLRequest) (*SignedURL, error)
+	// 校验签名URL，通过时返回原文件信息
+	VerifySignedURL(context.Context, *VerifySignedURLRequest) (*FileInfo, error)
+	// 巡检存储后端，比对对象与元数据并校验内容，处理孤立对象
+	Scrub(context.Context, *ScrubRequest) (*ScrubReport, error)
+	// 获取最近一次巡检的报告
+	GetScrubReport(context.Context, *GetScrubReportRequest) (*ScrubReport, error)
 	mustEmbedUnimplementedStorageServiceServer()
 }
 
@@ -138,6 +291,36 @@ func (UnimplementedStorageServiceServer) DeleteFile(context.Context, *DeleteFile
 func (UnimplementedStorageServiceServer) GetFileInfo(context.Context, *GetFileInfoRequest) (*FileInfo, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetFileInfo not implemented")
 }
+func (UnimplementedStorageServiceServer) CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*UploadSession, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateUploadSession not implemented")
+}
+func (UnimplementedStorageServiceServer) UploadChunk(grpc.ClientStreamingServer[UploadChunkRequest, UploadSession]) error {
+	return status.Error(codes.Unimplemented, "method UploadChunk not implemented")
+}
+func (UnimplementedStorageServiceServer) GetUploadSession(context.Context, *UploadSessionRequest) (*UploadSession, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUploadSession not implemented")
+}
+func (UnimplementedStorageServiceServer) FinalizeUploadSession(context.Context, *FinalizeUploadSessionRequest) (*UploadFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FinalizeUploadSession not implemented")
+}
+func (UnimplementedStorageServiceServer) CancelUploadSession(context.Context, *UploadSessionRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelUploadSession not implemented")
+}
+func (UnimplementedStorageServiceServer) GetUsage(context.Context, *GetUsageRequest) (*StorageUsage, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedStorageServiceServer) CreateSignedURL(context.Context, *CreateSignedURLRequest) (*SignedURL, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateSignedURL not implemented")
+}
+func (UnimplementedStorageServiceServer) VerifySignedURL(context.Context, *VerifySignedURLRequest) (*FileInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method VerifySignedURL not implemented")
+}
+func (UnimplementedStorageServiceServer) Scrub(context.Context, *ScrubRequest) (*ScrubReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method Scrub not implemented")
+}
+func (UnimplementedStorageServiceServer) GetScrubReport(context.Context, *GetScrubReportRequest) (*ScrubReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetScrubReport not implemented")
+}
 func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
 func (UnimplementedStorageServiceServer) testEmbeddedByValue()                        {}
 
@@ -213,6 +396,175 @@ func _StorageService_GetFileInfo_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_CreateUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).CreateUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_CreateUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).CreateUploadSession(ctx, req.(*CreateUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_UploadChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageServiceServer).UploadChunk(&grpc.GenericServerStream[UploadChunkRequest, UploadSession]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StorageService_UploadChunkServer = grpc.ClientStreamingServer[UploadChunkRequest, UploadSession]
+
+func _StorageService_GetUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetUploadSession(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_FinalizeUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinalizeUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).FinalizeUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_FinalizeUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).FinalizeUploadSession(ctx, req.(*FinalizeUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_CancelUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).CancelUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_CancelUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).CancelUploadSession(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_CreateSignedURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSignedURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).CreateSignedURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_CreateSignedURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).CreateSignedURL(ctx, req.(*CreateSignedURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_VerifySignedURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifySignedURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).VerifySignedURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_VerifySignedURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).VerifySignedURL(ctx, req.(*VerifySignedURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_Scrub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScrubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).Scrub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_Scrub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).Scrub(ctx, req.(*ScrubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetScrubReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScrubReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetScrubReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetScrubReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetScrubReport(ctx, req.(*GetScrubReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -228,6 +580,42 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFileInfo",
 			Handler:    _StorageService_GetFileInfo_Handler,
 		},
+		{
+			MethodName: "CreateUploadSession",
+			Handler:    _StorageService_CreateUploadSession_Handler,
+		},
+		{
+			MethodName: "GetUploadSession",
+			Handler:    _StorageService_GetUploadSession_Handler,
+		},
+		{
+			MethodName: "FinalizeUploadSession",
+			Handler:    _StorageService_FinalizeUploadSession_Handler,
+		},
+		{
+			MethodName: "CancelUploadSession",
+			Handler:    _StorageService_CancelUploadSession_Handler,
+		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _StorageService_GetUsage_Handler,
+		},
+		{
+			MethodName: "CreateSignedURL",
+			Handler:    _StorageService_CreateSignedURL_Handler,
+		},
+		{
+			MethodName: "VerifySignedURL",
+			Handler:    _StorageService_VerifySignedURL_Handler,
+		},
+		{
+			MethodName: "Scrub",
+			Handler:    _StorageService_Scrub_Handler,
+		},
+		{
+			MethodName: "GetScrubReport",
+			Handler:    _StorageService_GetScrubReport_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -240,6 +628,11 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _StorageService_DownloadFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "UploadChunk",
+			Handler:       _StorageService_UploadChunk_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "storage/storage.proto",
 }
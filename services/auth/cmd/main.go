@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/auth"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
@@ -24,15 +27,54 @@ func main() {
 	// 初始化认证服务
 	authService := internal.NewAuthService()
 
+	// 创建通信抽象层实例
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取配置，JWT密钥没有安全的默认值，配置服务不可用时无法启动
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(),
+		"jwt.secret", "jwt.expire_hours", "jwt.rotation_interval_days")
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	secret := configClient.String("jwt.secret", "")
+	if secret == "" {
+		log.Fatalf("jwt.secret is not configured")
+	}
+
+	expireHours, err := configClient.Int("jwt.expire_hours", 24)
+	if err != nil {
+		log.Fatalf("Failed to load JWT config: %v", err)
+	}
+	rotationIntervalDays, err := configClient.Int("jwt.rotation_interval_days", 7)
+	if err != nil {
+		log.Fatalf("Failed to load JWT config: %v", err)
+	}
+
 	// 配置JWT
 	if err := authService.ConfigureJWT(internal.JWTConfig{
-		Secret:               "your-secret-key",
-		ExpireHours:          24,
-		RotationIntervalDays: 7,
+		Secret:               secret,
+		ExpireHours:          expireHours,
+		RotationIntervalDays: rotationIntervalDays,
 	}); err != nil {
 		log.Fatalf("Failed to configure JWT: %v", err)
 	}
 
+	// 令牌有效期实时生效，更换密钥需要重启服务
+	configClient.OnChange("jwt.expire_hours", func(value string) error {
+		hours, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		return authService.SetExpireHours(hours)
+	})
+	configClient.RequireRestart("jwt.secret", "jwt.rotation_interval_days")
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
+
 	// 创建gRPC服务器
 	grpcServer := grpc.NewServer()
 
@@ -42,9 +84,6 @@ func main() {
 	// 注册健康检查服务
 	common.RegisterHealthCheckServiceServer(grpcServer, authService)
 
-	// 创建通信抽象层实例
-	trans := transport.NewTransportWithFallback()
-
 	// 确定服务地址
 	address := *socketPath
 	if address == "" {
@@ -81,6 +120,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down AuthService...")
+	cancelWatch()
 	grpcServer.GracefulStop()
 	log.Println("AuthService exited")
 }
@@ -56,6 +56,19 @@ func (s *AuthService) ConfigureJWT(config JWTConfig) error {
 	return nil
 }
 
+// SetExpireHours 修改新签发令牌的默认有效期，已签发的令牌不受影响
+func (s *AuthService) SetExpireHours(hours int) error {
+	if hours <= 0 {
+		return fmt.Errorf("invalid JWT expire hours: %d", hours)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jwtConfig.ExpireHours = hours
+	return nil
+}
+
 // GenerateToken 生成JWT令牌
 func (s *AuthService) GenerateToken(ctx context.Context, req *auth.GenerateTokenRequest) (*auth.GenerateTokenResponse, error) {
 	s.mu.RLock()
@@ -108,6 +108,7 @@ func init() {
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(restartCmd)
 	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(storageCmd)
 }
 
 // 设置命令的翻译字段
@@ -145,6 +146,18 @@ func setCommandTranslations() {
 	healthCmd.Short = i18n.T("health.short")
 	healthCmd.Long = i18n.T("health.long")
 	healthCmd.Example = i18n.T("health.example")
+
+	// 设置storage命令的翻译字段
+	storageCmd.Use = i18n.T("storage.use")
+	storageCmd.Short = i18n.T("storage.short")
+	storageCmd.Long = i18n.T("storage.long")
+	storageCmd.Example = i18n.T("storage.example")
+
+	// 设置scrub命令的翻译字段
+	scrubCmd.Use = i18n.T("scrub.use")
+	scrubCmd.Short = i18n.T("scrub.short")
+	scrubCmd.Long = i18n.T("scrub.long")
+	scrubCmd.Example = i18n.T("scrub.example")
 }
 
 // 初始化i18n
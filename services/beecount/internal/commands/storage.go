@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"github.com/fishdivinity/BeeCount-Cloud/services/beecount/internal"
+	"github.com/fishdivinity/BeeCount-Cloud/services/beecount/pkg/i18n"
+	"github.com/fishdivinity/BeeCount-Cloud/services/beecount/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// scrubTimeout 巡检请求的超时时间，校验内容需要读取全部对象
+const scrubTimeout = 2 * time.Hour
+
+// storageCmd 存储管理命令
+var storageCmd = &cobra.Command{
+	Run: func(cmd *cobra.Command, args []string) {
+		// 默认显示帮助信息
+		cmd.Help()
+	},
+}
+
+// scrubCmd 存储巡检命令
+var scrubCmd = &cobra.Command{
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取标志值
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		action, _ := cmd.Flags().GetString("action")
+		verify, _ := cmd.Flags().GetBool("verify")
+		last, _ := cmd.Flags().GetBool("last")
+		force, _ := cmd.Flags().GetBool("force")
+
+		// 处理孤立对象前二次确认
+		if !dryRun && !last {
+			if !Confirm("Orphaned objects will be "+scrubActionText(action)+". Continue?", force, false) {
+				logger.Info("Operation canceled.")
+				return
+			}
+		}
+
+		// 初始化服务管理器并连接存储服务
+		serviceManager := internal.NewServiceManager()
+		serviceManager.InitServices()
+		conn, err := serviceManager.DialService("storage")
+		if err != nil {
+			logger.Error("Failed to connect to storage service: %v", err)
+			return
+		}
+		defer conn.Close()
+		client := storage.NewStorageServiceClient(conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), scrubTimeout)
+		defer cancel()
+		var report *storage.ScrubReport
+		if last {
+			report, err = client.GetScrubReport(ctx, &storage.GetScrubReportRequest{})
+		} else {
+			logger.Info("Scrubbing storage...")
+			report, err = client.Scrub(ctx, &storage.ScrubRequest{
+				DryRun:          dryRun,
+				Action:          action,
+				VerifyChecksums: verify,
+			})
+		}
+		if err != nil {
+			logger.Error("Storage scrub failed: %v", err)
+			return
+		}
+
+		printScrubReport(report)
+	},
+}
+
+// scrubActionText 孤立对象处理方式的说明
+func scrubActionText(action string) string {
+	if action == "remove" {
+		return "removed"
+	}
+	return "moved to quarantine"
+}
+
+// printScrubReport 输出巡检报告
+func printScrubReport(report *storage.ScrubReport) {
+	mode := report.Action
+	if report.DryRun {
+		mode = "dry run"
+	}
+	fmt.Printf("Storage Scrub Report (%s)\n", mode)
+	fmt.Println("----------------------")
+	fmt.Printf("Started:    %s\n", report.StartedAt)
+	fmt.Printf("Finished:   %s\n", report.FinishedAt)
+	fmt.Printf("Objects:    %d\n", report.ObjectsScanned)
+	fmt.Printf("References: %d\n", report.ReferencesChecked)
+	fmt.Printf("Findings:   %d\n", len(report.Findings))
+
+	for _, finding := range report.Findings {
+		fmt.Printf("\n%-18s %s:%s\n", finding.Kind, finding.Backend, finding.StoragePath)
+		if finding.Reference != "" {
+			fmt.Printf("%18s referenced by %s\n", "", finding.Reference)
+		}
+		if finding.Action != "" {
+			fmt.Printf("%18s %s\n", "", finding.Action)
+		}
+		if finding.Detail != "" {
+			fmt.Printf("%18s %s\n", "", finding.Detail)
+		}
+	}
+}
+
+func init() {
+	// 添加巡检命令标志
+	scrubCmd.Flags().Bool("dry-run", false, i18n.T("flag.dry_run"))
+	scrubCmd.Flags().String("action", "quarantine", i18n.T("flag.scrub_action"))
+	scrubCmd.Flags().Bool("verify", true, i18n.T("flag.verify"))
+	scrubCmd.Flags().Bool("last", false, i18n.T("flag.last_report"))
+
+	storageCmd.AddCommand(scrubCmd)
+}
@@ -18,6 +18,17 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// serviceDefaultPorts 各服务无法使用通信抽象层的地址时监听的默认端口
+var serviceDefaultPorts = map[string]int{
+	"config":   50051,
+	"log":      50052,
+	"auth":     50053,
+	"business": 50054,
+	"storage":  50055,
+	"gateway":  8080,
+	"firewall": 50057,
+}
+
 // Service 服务定义
 type Service struct {
 	Name       string
@@ -467,19 +478,8 @@ func (sm *ServiceManager) CheckServiceHealth(serviceName string) bool {
 		var conn *grpc.ClientConn
 		var err error
 
-		// 为每个服务定义默认端口号
-		defaultPorts := map[string]int{
-			"config":   50051,
-			"log":      50052,
-			"auth":     50053,
-			"business": 50054,
-			"storage":  50055,
-			"gateway":  8080,
-			"firewall": 50057,
-		}
-
 		// 尝试使用服务的默认端口
-		if port, ok := defaultPorts[serviceName]; ok {
+		if port, ok := serviceDefaultPorts[serviceName]; ok {
 			addr := fmt.Sprintf(":%d", port)
 			conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 			if err == nil {
@@ -533,3 +533,32 @@ func (sm *ServiceManager) CheckServiceHealth(serviceName string) bool {
 
 	return false
 }
+
+// DialService 创建连接指定服务的gRPC客户端
+// 服务在默认端口上通过健康检查时使用该端口，否则使用通信抽象层生成的地址
+func (sm *ServiceManager) DialService(serviceName string) (*grpc.ClientConn, error) {
+	service, exists := sm.Services[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("unknown service: %s", serviceName)
+	}
+
+	if port, ok := serviceDefaultPorts[serviceName]; ok {
+		conn, err := grpc.NewClient(fmt.Sprintf(":%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			resp, err := common.NewHealthCheckServiceClient(conn).Check(ctx, &common.HealthCheckRequest{})
+			cancel()
+			if err == nil && resp.Status == common.HealthCheckResponse_SERVING {
+				return conn, nil
+			}
+			conn.Close()
+		}
+	}
+
+	dialer := sm.Transport.NewDialer()
+	return grpc.NewClient(service.SocketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "pipe", addr)
+		}))
+}
@@ -2,42 +2,54 @@ package i18n
 
 // 默认英文翻译映射
 var defaultEnTranslations = map[string]string{
-	"root.short":       "BeeCount Cloud microservice management tool",
-	"root.long":        "BeeCount Cloud microservice management tool, used to start, stop and monitor various microservices.",
-	"root.help.use":    "help [command]",
-	"root.help.short":  "Show help information",
-	"root.help.long":   "Show help information for the specified command.",
-	"flag.force":       "Force operation, skip confirmation",
-	"flag.background":  "Run in background mode",
-	"flag.all":         "Apply to all services",
-	"flag.gateway":     "Start gateway service",
-	"flag.config":      "Start config service",
-	"flag.auth":        "Start auth service",
-	"flag.business":    "Start business service",
-	"flag.storage":     "Start storage service",
-	"flag.log":         "Start log service",
-	"flag.firewall":    "Start firewall service",
-	"flag.lang":        "Specify language (e.g. en-US, zh-CN)",
-	"start.use":        "start [service]",
-	"start.short":      "Start specified service or all services",
-	"start.long":       "Start specified microservice or all microservices. Service name is optional, if not specified, all services will be started.",
-	"start.example":    "  BeeCount-Cloud start          # Start all services\n  BeeCount-Cloud start gateway    # Start gateway service\n  BeeCount-Cloud start --all      # Start all services\n  BeeCount-Cloud start --background # Start all services in background\n  BeeCount-Cloud start gateway --background # Start gateway service in background",
-	"stop.use":         "stop [service]",
-	"stop.short":       "Stop specified service or all services",
-	"stop.long":        "Stop specified microservice or all microservices. Service name is optional, if not specified, all services will be stopped. High-risk operation, requires confirmation.",
-	"stop.example":     "  BeeCount-Cloud stop          # Stop all services (requires confirmation)\n  BeeCount-Cloud stop gateway    # Stop gateway service (requires confirmation)\n  BeeCount-Cloud stop --all      # Stop all services (requires confirmation)\n  BeeCount-Cloud stop --force    # Force stop all services (skip confirmation)\n  BeeCount-Cloud stop gateway --force # Force stop gateway service (skip confirmation)",
-	"restart.use":      "restart [service]",
-	"restart.short":    "Restart specified service or all services",
-	"restart.long":     "Restart specified microservice or all microservices. Service name is optional, if not specified, all services will be restarted. High-risk operation, requires confirmation.",
-	"restart.example":  "  BeeCount-Cloud restart          # Restart all services (requires confirmation)\n  BeeCount-Cloud restart gateway    # Restart gateway service (requires confirmation)\n  BeeCount-Cloud restart --all      # Restart all services (requires confirmation)\n  BeeCount-Cloud restart --force    # Force restart all services (skip confirmation)\n  BeeCount-Cloud restart gateway --force # Force restart gateway service (skip confirmation)\n  BeeCount-Cloud restart --background # Restart all services in background",
-	"status.use":       "status [service]",
-	"status.short":     "Check service running status",
-	"status.long":      "Check the running status of specified microservice or all microservices. Service name is optional, if not specified, status of all services will be checked.",
-	"status.example":   "  BeeCount-Cloud status          # Check status of all services\n  BeeCount-Cloud status gateway    # Check status of gateway service",
-	"health.use":       "health [service]",
-	"health.short":     "Check service health status",
-	"health.long":      "Check the health status of specified microservice or all microservices. Service name is optional, if not specified, health status of all services will be checked.",
-	"health.example":   "  BeeCount-Cloud health          # Check health status of all services\n  BeeCount-Cloud health gateway    # Check health status of gateway service",
-	"completion.use":   "completion",
-	"completion.short": "Generate the autocompletion script for the specified shell",
+	"root.short":        "BeeCount Cloud microservice management tool",
+	"root.long":         "BeeCount Cloud microservice management tool, used to start, stop and monitor various microservices.",
+	"root.help.use":     "help [command]",
+	"root.help.short":   "Show help information",
+	"root.help.long":    "Show help information for the specified command.",
+	"flag.force":        "Force operation, skip confirmation",
+	"flag.background":   "Run in background mode",
+	"flag.all":          "Apply to all services",
+	"flag.gateway":      "Start gateway service",
+	"flag.config":       "Start config service",
+	"flag.auth":         "Start auth service",
+	"flag.business":     "Start business service",
+	"flag.storage":      "Start storage service",
+	"flag.log":          "Start log service",
+	"flag.firewall":     "Start firewall service",
+	"flag.lang":         "Specify language (e.g. en-US, zh-CN)",
+	"flag.dry_run":      "Only report problems, do not touch orphaned objects",
+	"flag.scrub_action": "What to do with orphaned objects: quarantine or remove",
+	"flag.verify":       "Read stored objects and verify their SHA-256 checksums",
+	"flag.last_report":  "Show the report of the last scrub instead of running a new one",
+	"start.use":         "start [service]",
+	"start.short":       "Start specified service or all services",
+	"start.long":        "Start specified microservice or all microservices. Service name is optional, if not specified, all services will be started.",
+	"start.example":     "  BeeCount-Cloud start          # Start all services\n  BeeCount-Cloud start gateway    # Start gateway service\n  BeeCount-Cloud start --all      # Start all services\n  BeeCount-Cloud start --background # Start all services in background\n  BeeCount-Cloud start gateway --background # Start gateway service in background",
+	"stop.use":          "stop [service]",
+	"stop.short":        "Stop specified service or all services",
+	"stop.long":         "Stop specified microservice or all microservices. Service name is optional, if not specified, all services will be stopped. High-risk operation, requires confirmation.",
+	"stop.example":      "  BeeCount-Cloud stop          # Stop all services (requires confirmation)\n  BeeCount-Cloud stop gateway    # Stop gateway service (requires confirmation)\n  BeeCount-Cloud stop --all      # Stop all services (requires confirmation)\n  BeeCount-Cloud stop --force    # Force stop all services (skip confirmation)\n  BeeCount-Cloud stop gateway --force # Force stop gateway service (skip confirmation)",
+	"restart.use":       "restart [service]",
+	"restart.short":     "Restart specified service or all services",
+	"restart.long":      "Restart specified microservice or all microservices. Service name is optional, if not specified, all services will be restarted. High-risk operation, requires confirmation.",
+	"restart.example":   "  BeeCount-Cloud restart          # Restart all services (requires confirmation)\n  BeeCount-Cloud restart gateway    # Restart gateway service (requires confirmation)\n  BeeCount-Cloud restart --all      # Restart all services (requires confirmation)\n  BeeCount-Cloud restart --force    # Force restart all services (skip confirmation)\n  BeeCount-Cloud restart gateway --force # Force restart gateway service (skip confirmation)\n  BeeCount-Cloud restart --background # Restart all services in background",
+	"status.use":        "status [service]",
+	"status.short":      "Check service running status",
+	"status.long":       "Check the running status of specified microservice or all microservices. Service name is optional, if not specified, status of all services will be checked.",
+	"status.example":    "  BeeCount-Cloud status          # Check status of all services\n  BeeCount-Cloud status gateway    # Check status of gateway service",
+	"health.use":        "health [service]",
+	"health.short":      "Check service health status",
+	"health.long":       "Check the health status of specified microservice or all microservices. Service name is optional, if not specified, health status of all services will be checked.",
+	"health.example":    "  BeeCount-Cloud health          # Check health status of all services\n  BeeCount-Cloud health gateway    # Check health status of gateway service",
+	"storage.use":       "storage",
+	"storage.short":     "Manage file storage",
+	"storage.long":      "Maintenance commands for the storage service.",
+	"storage.example":   "  BeeCount-Cloud storage scrub --dry-run   # Report storage problems without changing anything",
+	"scrub.use":         "scrub",
+	"scrub.short":       "Check stored files against metadata",
+	"scrub.long":        "Cross-check stored objects against file metadata and verify checksums. Reports orphaned objects that no file refers to, files whose content is missing, and objects whose content does not match the recorded checksum. Orphaned objects are moved to quarantine or removed unless --dry-run is given. Requires confirmation unless --dry-run or --force is given.",
+	"scrub.example":     "  BeeCount-Cloud storage scrub --dry-run          # Report problems only\n  BeeCount-Cloud storage scrub                    # Quarantine orphaned objects (requires confirmation)\n  BeeCount-Cloud storage scrub --action remove --force # Remove orphaned objects without confirmation\n  BeeCount-Cloud storage scrub --last             # Show the report of the last scrub",
+	"completion.use":    "completion",
+	"completion.short":  "Generate the autocompletion script for the specified shell",
 }
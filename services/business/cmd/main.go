@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
@@ -24,16 +26,33 @@ func main() {
 	// 初始化业务服务
 	businessService := internal.NewBusinessService()
 
-	// 配置数据库（SQLite3）
-	if err := businessService.ConfigureDatabase(internal.DatabaseConfig{
-		Type: "sqlite3",
-		SQLiteConfig: internal.SQLiteConfig{
-			Path: "./data/beecount.db",
-		},
-	}); err != nil {
+	// 创建通信抽象层实例
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取配置，配置服务不可用时使用默认的SQLite数据库
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(), databaseConfigKeys...)
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+	databaseConfig, err := loadDatabaseConfig(configClient)
+	if err != nil {
+		log.Fatalf("Failed to load database config: %v", err)
+	}
+
+	// 配置数据库
+	if err := businessService.ConfigureDatabase(databaseConfig); err != nil {
 		log.Fatalf("Failed to configure database: %v", err)
 	}
 
+	// 数据库配置只在启动时读取，变化后需要重启服务
+	configClient.RequireRestart(databaseConfigKeys...)
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
+
 	// 初始化数据库
 	if err := businessService.InitDatabase(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -48,8 +67,14 @@ func main() {
 	// 注册健康检查服务
 	common.RegisterHealthCheckServiceServer(grpcServer, businessService)
 
-	// 创建通信抽象层实例
-	trans := transport.NewTransportWithFallback()
+	// 连接存储服务，用于删除账本时清理附件
+	if err := businessService.ConfigureStorageClient(trans.DefaultAddress("storage")); err != nil {
+		log.Printf("Failed to configure storage client: %v", err)
+	}
+
+	// 启动后台任务（回收站清理、附件删除）
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	go businessService.RunBackgroundJobs(jobCtx)
 
 	// 确定服务地址
 	address := *socketPath
@@ -87,6 +112,45 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down BusinessService...")
+	cancelWatch()
+	cancelJobs()
 	grpcServer.GracefulStop()
 	log.Println("BusinessService exited")
 }
+
+// databaseConfigKeys 业务服务使用的数据库配置项
+var databaseConfigKeys = []string{
+	"database.active",
+	"database.sqlite.path",
+	"database.mysql.host",
+	"database.mysql.port",
+	"database.mysql.username",
+	"database.mysql.password",
+	"database.mysql.database",
+}
+
+// loadDatabaseConfig 从配置服务的配置生成数据库配置，未设置的配置项使用默认值
+func loadDatabaseConfig(client *configclient.Client) (internal.DatabaseConfig, error) {
+	cfg := internal.DatabaseConfig{
+		Type: client.String("database.active", "sqlite"),
+		SQLiteConfig: internal.SQLiteConfig{
+			Path: client.String("database.sqlite.path", "./data/beecount.db"),
+		},
+		MySQLConfig: internal.MySQLConfig{
+			Host:     client.String("database.mysql.host", "localhost"),
+			Username: client.String("database.mysql.username", "root"),
+			Password: client.String("database.mysql.password", ""),
+			Database: client.String("database.mysql.database", "beecount"),
+		},
+	}
+	// 配置服务中SQLite的类型名为sqlite
+	if cfg.Type == "sqlite" {
+		cfg.Type = "sqlite3"
+	}
+
+	var err error
+	if cfg.MySQLConfig.Port, err = client.Int("database.mysql.port", 3306); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
 	"github.com/glebarez/sqlite"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -51,13 +53,16 @@ type PostgresConfig struct {
 
 // Ledger 账本模型
 type Ledger struct {
-	ID          string    `gorm:"type:varchar(36);primaryKey"`
-	Name        string    `gorm:"type:varchar(255);not null"`
-	Description string    `gorm:"type:text"`
-	UserID      string    `gorm:"type:varchar(36);not null;index"`
-	Currency    string    `gorm:"type:varchar(10);default:'CNY'"`
-	CreatedAt   time.Time `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	ID          string         `gorm:"type:varchar(36);primaryKey"`
+	Name        string         `gorm:"type:varchar(255);not null"`
+	Description string         `gorm:"type:text"`
+	UserID      string         `gorm:"type:varchar(36);not null;index"`
+	Currency    string         `gorm:"type:varchar(10);default:'CNY'"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	ArchivedAt  *time.Time     `gorm:"index"`              // 归档时间，归档的账本不出现在列表和同步中
+	RestoredAt  int64          `gorm:"not null;default:0"` // 取消归档或从回收站恢复的时间，同步时据此返回账本的全部数据
+	DeletedAt   gorm.DeletedAt `gorm:"index"`              // 移入回收站的时间
 }
 
 // Transaction 交易模型
@@ -75,17 +80,20 @@ type Transaction struct {
 	Date            string            `gorm:"type:varchar(10);not null;index"`
 	CreatedAt       time.Time         `gorm:"autoCreateTime;index"`
 	UpdatedAt       time.Time         `gorm:"autoUpdateTime"`
-	Tags            map[string]string `gorm:"type:json"`
+	Tags            map[string]string `gorm:"type:json;serializer:json"`
 	SyncTime        int64             `gorm:"not null;index"`
 	DeviceID        string            `gorm:"type:varchar(36);not null"`
+	UpdatedBy       string            `gorm:"type:varchar(36)"` // 最后修改交易的成员
+	SearchText      string            `gorm:"type:text"`        // 全文检索文本，由BeforeSave根据描述和标签生成
 }
 
 // BusinessService 业务服务实现
 type BusinessService struct {
 	business.UnimplementedBusinessServiceServer
 	common.UnimplementedHealthCheckServiceServer
-	db     *gorm.DB
-	config DatabaseConfig
+	db            *gorm.DB
+	config        DatabaseConfig
+	storageClient storage.StorageServiceClient
 }
 
 // NewBusinessService 创建业务服务实例
@@ -161,7 +169,18 @@ func (s *BusinessService) ConfigureDatabase(config DatabaseConfig) error {
 // InitDatabase 初始化数据库
 func (s *BusinessService) InitDatabase() error {
 	// 自动迁移模型
-	if err := s.db.AutoMigrate(&Ledger{}, &Transaction{}); err != nil {
+	if err := s.db.AutoMigrate(&Ledger{}, &LedgerMember{}, &Transaction{}, &ChangeHistory{},
+		&Attachment{}, &TransactionAttachment{}, &PendingFileDeletion{}); err != nil {
+		return err
+	}
+
+	// 补充账本所有者
+	if err := s.initLedgerMembers(); err != nil {
+		return err
+	}
+
+	// 初始化全文索引
+	if err := s.initSearchIndex(); err != nil {
 		return err
 	}
 
@@ -184,12 +203,17 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 
 	// 同步时间
 	syncTime := time.Now().Unix()
+	actor := changeActor{userID: req.UserId, deviceID: req.DeviceId}
+
+	// 无权限的记录逐条拒绝，其余记录照常同步
+	var rejected []*business.SyncRejection
 
 	// 处理账本
 	var syncedLedgers []*business.Ledger
 	for _, ledger := range req.Ledgers {
+		// 包括回收站中的账本，避免以相同ID重复创建
 		var existingLedger Ledger
-		result := tx.First(&existingLedger, "id = ?", ledger.Id)
+		result := tx.Unscoped().First(&existingLedger, "id = ?", ledger.Id)
 
 		if result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
@@ -206,6 +230,14 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 					tx.Rollback()
 					return nil, status.Errorf(codes.Internal, "Failed to create ledger: %v", err)
 				}
+				if err := tx.Create(newOwnerMember(newLedger.ID, req.UserId, syncTime)).Error; err != nil {
+					tx.Rollback()
+					return nil, status.Errorf(codes.Internal, "Failed to create ledger owner: %v", err)
+				}
+				if err := recordLedgerChange(tx, actor, opCreate, nil, &newLedger); err != nil {
+					tx.Rollback()
+					return nil, status.Errorf(codes.Internal, "Failed to record ledger history: %v", err)
+				}
 
 				syncedLedgers = append(syncedLedgers, ledger)
 			} else {
@@ -213,7 +245,23 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 				return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", result.Error)
 			}
 		} else {
+			// 客户端回传未修改的账本时跳过，成员同步共享账本不需要所有者权限
+			if !ledgerChanged(&existingLedger, ledger) {
+				continue
+			}
+
+			// 只有所有者可以修改账本信息，回收站中的账本不能修改
+			if _, err := requireLedgerRole(tx, existingLedger.ID, req.UserId, roleOwner); err != nil {
+				if !isRejection(err) {
+					tx.Rollback()
+					return nil, err
+				}
+				rejected = append(rejected, syncRejection(ledger.Id, entityLedger, err))
+				continue
+			}
+
 			// 更新现有账本
+			before := existingLedger
 			existingLedger.Name = ledger.Name
 			existingLedger.Description = ledger.Description
 			existingLedger.Currency = ledger.Currency
@@ -222,11 +270,26 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 				tx.Rollback()
 				return nil, status.Errorf(codes.Internal, "Failed to update ledger: %v", err)
 			}
+			if err := recordLedgerChange(tx, actor, opUpdate, &before, &existingLedger); err != nil {
+				tx.Rollback()
+				return nil, status.Errorf(codes.Internal, "Failed to record ledger history: %v", err)
+			}
 
 			syncedLedgers = append(syncedLedgers, ledger)
 		}
 	}
 
+	// 校验编辑权限，同一账本只查询一次
+	permissions := make(map[string]error)
+	requireEditor := func(ledgerID string) error {
+		if err, ok := permissions[ledgerID]; ok {
+			return err
+		}
+		_, err := requireLedgerRole(tx, ledgerID, req.UserId, roleEditor)
+		permissions[ledgerID] = err
+		return err
+	}
+
 	// 处理交易
 	var syncedTransactions []*business.Transaction
 	for _, transaction := range req.Transactions {
@@ -235,6 +298,15 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 
 		if result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
+				if err := requireEditor(transaction.LedgerId); err != nil {
+					if !isRejection(err) {
+						tx.Rollback()
+						return nil, err
+					}
+					rejected = append(rejected, syncRejection(transaction.Id, entityTransaction, err))
+					continue
+				}
+
 				// 创建新交易
 				newTransaction := Transaction{
 					ID:              transaction.Id,
@@ -251,12 +323,17 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 					Tags:            transaction.Tags,
 					SyncTime:        syncTime,
 					DeviceID:        req.DeviceId,
+					UpdatedBy:       req.UserId,
 				}
 
 				if err := tx.Create(&newTransaction).Error; err != nil {
 					tx.Rollback()
 					return nil, status.Errorf(codes.Internal, "Failed to create transaction: %v", err)
 				}
+				if err := recordTransactionChange(tx, actor, opCreate, nil, &newTransaction); err != nil {
+					tx.Rollback()
+					return nil, status.Errorf(codes.Internal, "Failed to record transaction history: %v", err)
+				}
 
 				syncedTransactions = append(syncedTransactions, transaction)
 			} else {
@@ -264,7 +341,20 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 				return nil, status.Errorf(codes.Internal, "Failed to query transaction: %v", result.Error)
 			}
 		} else {
+			if !transactionChanged(&existingTransaction, transaction) {
+				continue
+			}
+			if err := requireEditor(existingTransaction.LedgerID); err != nil {
+				if !isRejection(err) {
+					tx.Rollback()
+					return nil, err
+				}
+				rejected = append(rejected, syncRejection(transaction.Id, entityTransaction, err))
+				continue
+			}
+
 			// 更新现有交易
+			before := existingTransaction
 			existingTransaction.Type = transaction.Type
 			existingTransaction.CategoryID = transaction.CategoryId
 			existingTransaction.SubcategoryID = transaction.SubcategoryId
@@ -275,30 +365,80 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 			existingTransaction.Date = transaction.Date
 			existingTransaction.Tags = transaction.Tags
 			existingTransaction.SyncTime = syncTime
+			existingTransaction.UpdatedBy = req.UserId
 
 			if err := tx.Save(&existingTransaction).Error; err != nil {
 				tx.Rollback()
 				return nil, status.Errorf(codes.Internal, "Failed to update transaction: %v", err)
 			}
+			if err := recordTransactionChange(tx, actor, opUpdate, &before, &existingTransaction); err != nil {
+				tx.Rollback()
+				return nil, status.Errorf(codes.Internal, "Failed to record transaction history: %v", err)
+			}
 
 			syncedTransactions = append(syncedTransactions, transaction)
 		}
 	}
 
-	// 查询需要同步的新增或更新的账本
+	// 上次同步后新加入或恢复的账本需要返回全部数据
+	activeLedgerIDs := tx.Model(&LedgerMember{}).Select("ledger_id").Where("user_id = ? AND status = ?", req.UserId, memberStatusActive)
+	var joinedLedgerIDs []string
+	if err := tx.Model(&LedgerMember{}).Where("user_id = ? AND status = ? AND joined_at > ?", req.UserId, memberStatusActive, req.LastSyncTime).
+		Pluck("ledger_id", &joinedLedgerIDs).Error; err != nil {
+		tx.Rollback()
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger members: %v", err)
+	}
+	var restoredLedgerIDs []string
+	if err := tx.Model(&Ledger{}).Where("id IN (?) AND restored_at > ?", activeLedgerIDs, req.LastSyncTime).
+		Pluck("id", &restoredLedgerIDs).Error; err != nil {
+		tx.Rollback()
+		return nil, status.Errorf(codes.Internal, "Failed to query ledgers: %v", err)
+	}
+	joinedLedgerIDs = append(joinedLedgerIDs, restoredLedgerIDs...)
+
+	// 查询需要同步的新增或更新的账本（包括共享给用户的账本）
 	var ledgers []Ledger
-	if err := tx.Where("user_id = ? AND updated_at > ?", req.UserId, time.Unix(req.LastSyncTime, 0)).Find(&ledgers).Error; err != nil {
+	if err := tx.Where("id IN (?) AND (updated_at > ? OR id IN ?)", memberLedgerIDs(tx, req.UserId, false), time.Unix(req.LastSyncTime, 0), joinedLedgerIDs).
+		Find(&ledgers).Error; err != nil {
 		tx.Rollback()
 		return nil, status.Errorf(codes.Internal, "Failed to query ledgers: %v", err)
 	}
 
 	// 查询需要同步的新增或更新的交易
 	var transactions []Transaction
-	if err := tx.Where("user_id = ? AND sync_time > ?", req.UserId, req.LastSyncTime).Find(&transactions).Error; err != nil {
+	if err := tx.Where("ledger_id IN (?) AND (sync_time > ? OR ledger_id IN ?)", memberLedgerIDs(tx, req.UserId, false), req.LastSyncTime, joinedLedgerIDs).
+		Find(&transactions).Error; err != nil {
 		tx.Rollback()
 		return nil, status.Errorf(codes.Internal, "Failed to query transactions: %v", err)
 	}
 
+	// 用户被移除或已退出的账本需要从客户端删除
+	deletedLedgerIDs := []string{}
+	if err := tx.Model(&LedgerMember{}).Where("user_id = ? AND status <> ? AND updated_at > ?", req.UserId, memberStatusActive, time.Unix(req.LastSyncTime, 0)).
+		Pluck("ledger_id", &deletedLedgerIDs).Error; err != nil {
+		tx.Rollback()
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger members: %v", err)
+	}
+
+	// 上次同步后归档或移入回收站的账本同样从客户端删除
+	var hiddenLedgerIDs []string
+	if err := tx.Unscoped().Model(&Ledger{}).Where("id IN (?) AND (archived_at > ? OR deleted_at > ?)", activeLedgerIDs, time.Unix(req.LastSyncTime, 0), time.Unix(req.LastSyncTime, 0)).
+		Pluck("id", &hiddenLedgerIDs).Error; err != nil {
+		tx.Rollback()
+		return nil, status.Errorf(codes.Internal, "Failed to query ledgers: %v", err)
+	}
+	deletedLedgerIDs = append(deletedLedgerIDs, hiddenLedgerIDs...)
+
+	// 上次同步后删除的交易，已恢复的交易会随交易列表返回，不再通知删除
+	deletedTransactionIDs := []string{}
+	if err := tx.Model(&ChangeHistory{}).Distinct("entity_id").
+		Where("entity_type = ? AND operation = ? AND created_at > ?", entityTransaction, opDelete, time.Unix(req.LastSyncTime, 0)).
+		Where("ledger_id IN (?) AND entity_id NOT IN (?)", memberLedgerIDs(tx, req.UserId, false), tx.Model(&Transaction{}).Select("id")).
+		Pluck("entity_id", &deletedTransactionIDs).Error; err != nil {
+		tx.Rollback()
+		return nil, status.Errorf(codes.Internal, "Failed to query deleted transactions: %v", err)
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to commit transaction: %v", err)
@@ -307,35 +447,15 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 	// 转换为proto响应格式
 	var responseLedgers []*business.Ledger
 	for _, ledger := range ledgers {
-		responseLedgers = append(responseLedgers, &business.Ledger{
-			Id:          ledger.ID,
-			Name:        ledger.Name,
-			Description: ledger.Description,
-			UserId:      ledger.UserID,
-			Currency:    ledger.Currency,
-			CreatedAt:   ledger.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   ledger.UpdatedAt.Format(time.RFC3339),
-		})
+		responseLedgers = append(responseLedgers, ledgerToProto(ledger))
 	}
 
 	var responseTransactions []*business.Transaction
 	for _, transaction := range transactions {
-		responseTransactions = append(responseTransactions, &business.Transaction{
-			Id:              transaction.ID,
-			LedgerId:        transaction.LedgerID,
-			UserId:          transaction.UserID,
-			Type:            transaction.Type,
-			CategoryId:      transaction.CategoryID,
-			SubcategoryId:   transaction.SubcategoryID,
-			AccountId:       transaction.AccountID,
-			TargetAccountId: transaction.TargetAccountID,
-			Amount:          transaction.Amount,
-			Description:     transaction.Description,
-			Date:            transaction.Date,
-			CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
-			Tags:            transaction.Tags,
-		})
+		responseTransactions = append(responseTransactions, transactionToProto(transaction))
+	}
+	if err := loadAttachments(s.db, responseTransactions); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query attachments: %v", err)
 	}
 
 	// 返回同步响应
@@ -343,11 +463,61 @@ func (s *BusinessService) Sync(ctx context.Context, req *business.SyncRequest) (
 		SyncTime:              syncTime,
 		Transactions:          responseTransactions,
 		Ledgers:               responseLedgers,
-		DeletedTransactionIds: []string{},
-		DeletedLedgerIds:      []string{},
+		DeletedTransactionIds: deletedTransactionIDs,
+		DeletedLedgerIds:      deletedLedgerIDs,
+		Rejected:              rejected,
 	}, nil
 }
 
+// ledgerChanged 判断客户端提交的账本与已保存的账本是否不同
+func ledgerChanged(existing *Ledger, ledger *business.Ledger) bool {
+	return existing.Name != ledger.Name ||
+		existing.Description != ledger.Description ||
+		existing.Currency != ledger.Currency
+}
+
+// transactionChanged 判断客户端提交的交易与已保存的交易是否不同
+func transactionChanged(existing *Transaction, transaction *business.Transaction) bool {
+	return existing.Type != transaction.Type ||
+		existing.CategoryID != transaction.CategoryId ||
+		existing.SubcategoryID != transaction.SubcategoryId ||
+		existing.AccountID != transaction.AccountId ||
+		existing.TargetAccountID != transaction.TargetAccountId ||
+		!sameAmount(existing.Amount, transaction.Amount) ||
+		existing.Description != transaction.Description ||
+		existing.Date != transaction.Date ||
+		!maps.Equal(existing.Tags, transaction.Tags)
+}
+
+// sameAmount 比较两个金额是否相等，忽略小数位数的差异
+func sameAmount(a, b string) bool {
+	if a == b {
+		return true
+	}
+	normalizedA, errA := normalizeAmount(a)
+	normalizedB, errB := normalizeAmount(b)
+	return errA == nil && errB == nil && normalizedA == normalizedB
+}
+
+// isRejection 判断同步中的错误是否只影响单条记录，内部错误仍使整个同步失败
+func isRejection(err error) bool {
+	switch status.Code(err) {
+	case codes.PermissionDenied, codes.NotFound, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+// syncRejection 构造同步中被拒绝记录的结果
+func syncRejection(id, kind string, err error) *business.SyncRejection {
+	return &business.SyncRejection{
+		Id:    id,
+		Kind:  kind,
+		Code:  int32(status.Code(err)),
+		Error: status.Convert(err).Message(),
+	}
+}
+
 // GetLedgers 获取账本列表
 func (s *BusinessService) GetLedgers(ctx context.Context, req *business.GetLedgersRequest) (*business.GetLedgersResponse, error) {
 	// 设置默认分页
@@ -367,28 +537,20 @@ func (s *BusinessService) GetLedgers(ctx context.Context, req *business.GetLedge
 	var ledgers []Ledger
 	var total int64
 
-	// 计算总数
-	if err := s.db.Model(&Ledger{}).Where("user_id = ?", req.UserId).Count(&total).Error; err != nil {
+	// 计算总数（包括共享给用户的账本）
+	if err := s.db.Model(&Ledger{}).Where("id IN (?)", memberLedgerIDs(s.db, req.UserId, req.IncludeArchived)).Count(&total).Error; err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to count ledgers: %v", err)
 	}
 
 	// 查询列表
-	if err := s.db.Where("user_id = ?", req.UserId).Offset(int(offset)).Limit(int(pageSize)).Order("created_at DESC").Find(&ledgers).Error; err != nil {
+	if err := s.db.Where("id IN (?)", memberLedgerIDs(s.db, req.UserId, req.IncludeArchived)).Offset(int(offset)).Limit(int(pageSize)).Order("created_at DESC").Find(&ledgers).Error; err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to query ledgers: %v", err)
 	}
 
 	// 转换为proto格式
 	var responseLedgers []*business.Ledger
 	for _, ledger := range ledgers {
-		responseLedgers = append(responseLedgers, &business.Ledger{
-			Id:          ledger.ID,
-			Name:        ledger.Name,
-			Description: ledger.Description,
-			UserId:      ledger.UserID,
-			Currency:    ledger.Currency,
-			CreatedAt:   ledger.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   ledger.UpdatedAt.Format(time.RFC3339),
-		})
+		responseLedgers = append(responseLedgers, ledgerToProto(ledger))
 	}
 
 	return &business.GetLedgersResponse{
@@ -415,25 +577,37 @@ func (s *BusinessService) CreateLedger(ctx context.Context, req *business.Ledger
 		UserID:      req.UserId,
 		Currency:    req.Currency,
 	}
+	if ledger.Currency == "" {
+		ledger.Currency = defaultCurrency
+	}
+	if err := validateFields(&ledger, ledgerRules, nil); err != nil {
+		return nil, err
+	}
 
-	if err := s.db.Create(&ledger).Error; err != nil {
+	// 创建者成为账本所有者
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ledger).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(newOwnerMember(ledger.ID, req.UserId, time.Now().Unix())).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actorFromContext(ctx, req.UserId), opCreate, nil, &ledger)
+	}); err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to create ledger: %v", err)
 	}
 
 	// 返回创建的账本
-	return &business.Ledger{
-		Id:          ledger.ID,
-		Name:        ledger.Name,
-		Description: ledger.Description,
-		UserId:      ledger.UserID,
-		Currency:    ledger.Currency,
-		CreatedAt:   ledger.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   ledger.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return ledgerToProto(ledger), nil
 }
 
 // UpdateLedger 更新账本
 func (s *BusinessService) UpdateLedger(ctx context.Context, req *business.Ledger) (*business.Ledger, error) {
+	// 只有所有者可以修改账本信息
+	if _, err := requireLedgerRole(s.db, req.Id, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
 	// 查询账本
 	var ledger Ledger
 	result := s.db.First(&ledger, "id = ?", req.Id)
@@ -444,59 +618,134 @@ func (s *BusinessService) UpdateLedger(ctx context.Context, req *business.Ledger
 		return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", result.Error)
 	}
 
-	// 更新账本
-	ledger.Name = req.Name
-	ledger.Description = req.Description
-	ledger.Currency = req.Currency
+	// 更新账本，只修改字段掩码中的字段
+	before := ledger
+	if err := applyLedgerMask(&ledger, req, req.UpdateMask.GetPaths()); err != nil {
+		return nil, err
+	}
 
-	if err := s.db.Save(&ledger).Error; err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ledger).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actorFromContext(ctx, req.UserId), opUpdate, &before, &ledger)
+	}); err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to update ledger: %v", err)
 	}
 
 	// 返回更新后的账本
-	return &business.Ledger{
-		Id:          ledger.ID,
-		Name:        ledger.Name,
-		Description: ledger.Description,
-		UserId:      ledger.UserID,
-		Currency:    ledger.Currency,
-		CreatedAt:   ledger.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   ledger.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return ledgerToProto(ledger), nil
 }
 
-// DeleteLedger 删除账本
+// DeleteLedger 删除账本，账本移入回收站，保留期后由后台任务永久删除
 func (s *BusinessService) DeleteLedger(ctx context.Context, req *business.Ledger) (*common.Response, error) {
-	// 删除账本
-	result := s.db.Delete(&Ledger{}, "id = ?", req.Id)
-	if result.Error != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to delete ledger: %v", result.Error)
+	// 只有所有者可以删除账本
+	if _, err := requireLedgerRole(s.db, req.Id, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var ledger Ledger
+	if err := s.db.First(&ledger, "id = ?", req.Id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Ledger not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", err)
 	}
 
-	if result.RowsAffected == 0 {
-		return nil, status.Errorf(codes.NotFound, "Ledger not found")
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 移入回收站，所有成员在下次同步时收到账本删除通知
+		if err := tx.Delete(&Ledger{}, "id = ?", ledger.ID).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actorFromContext(ctx, req.UserId), opDelete, &ledger, nil)
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to delete ledger: %v", err)
 	}
 
 	return &common.Response{
 		Success: true,
-		Message: "Ledger deleted successfully",
+		Message: "Ledger moved to trash",
 		Code:    200,
 	}, nil
 }
 
 // CreateTransaction 创建交易
 func (s *BusinessService) CreateTransaction(ctx context.Context, req *business.Transaction) (*business.Transaction, error) {
+	var transaction *Transaction
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = createTransaction(tx, actorFromContext(ctx, req.UserId), req)
+		return err
+	}); err != nil {
+		return nil, internalError(err, "Failed to create transaction")
+	}
+
+	// 返回创建的交易
+	return transactionToProto(*transaction), nil
+}
+
+// UpdateTransaction 更新交易
+func (s *BusinessService) UpdateTransaction(ctx context.Context, req *business.Transaction) (*business.Transaction, error) {
+	var transaction *Transaction
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = updateTransaction(tx, actorFromContext(ctx, req.UserId), req, req.UpdateMask.GetPaths())
+		return err
+	}); err != nil {
+		return nil, internalError(err, "Failed to update transaction")
+	}
+
+	// 返回更新后的交易
+	responseTransaction := transactionToProto(*transaction)
+	if err := loadAttachments(s.db, []*business.Transaction{responseTransaction}); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query attachments: %v", err)
+	}
+	return responseTransaction, nil
+}
+
+// DeleteTransaction 删除交易
+func (s *BusinessService) DeleteTransaction(ctx context.Context, req *business.Transaction) (*common.Response, error) {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return deleteTransaction(tx, actorFromContext(ctx, req.UserId), req.Id)
+	}); err != nil {
+		return nil, internalError(err, "Failed to delete transaction")
+	}
+
+	// 尽快清理不再被引用的附件文件，失败的文件由后台任务重试
+	s.processFileDeletions(ctx)
+
+	return &common.Response{
+		Success: true,
+		Message: "Transaction deleted successfully",
+		Code:    200,
+	}, nil
+}
+
+// createTransaction 校验权限后创建交易并记录变更历史，需在数据库事务中调用
+func createTransaction(tx *gorm.DB, actor changeActor, req *business.Transaction) (*Transaction, error) {
+	if _, err := requireLedgerRole(tx, req.LedgerId, actor.userID, roleEditor); err != nil {
+		return nil, err
+	}
+
 	// 生成UUID
 	transactionID := req.Id
 	if transactionID == "" {
 		transactionID = uuid.New().String()
+	} else {
+		var count int64
+		if err := tx.Model(&Transaction{}).Where("id = ?", transactionID).Count(&count).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to query transaction: %v", err)
+		}
+		if count > 0 {
+			return nil, status.Errorf(codes.AlreadyExists, "Transaction already exists")
+		}
 	}
 
 	// 创建交易
 	transaction := Transaction{
 		ID:              transactionID,
 		LedgerID:        req.LedgerId,
-		UserID:          req.UserId,
+		UserID:          actor.userID,
 		Type:            req.Type,
 		CategoryID:      req.CategoryId,
 		SubcategoryID:   req.SubcategoryId,
@@ -507,61 +756,112 @@ func (s *BusinessService) CreateTransaction(ctx context.Context, req *business.T
 		Date:            req.Date,
 		Tags:            req.Tags,
 		SyncTime:        time.Now().Unix(),
+		DeviceID:        actor.deviceID,
+		UpdatedBy:       actor.userID,
 	}
-
-	if err := s.db.Create(&transaction).Error; err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to create transaction: %v", err)
+	if err := validateFields(&transaction, transactionRules, nil); err != nil {
+		return nil, err
 	}
-
-	// 返回创建的交易
-	return &business.Transaction{
-		Id:              transaction.ID,
-		LedgerId:        transaction.LedgerID,
-		UserId:          transaction.UserID,
-		Type:            transaction.Type,
-		CategoryId:      transaction.CategoryID,
-		SubcategoryId:   transaction.SubcategoryID,
-		AccountId:       transaction.AccountID,
-		TargetAccountId: transaction.TargetAccountID,
-		Amount:          transaction.Amount,
-		Description:     transaction.Description,
-		Date:            transaction.Date,
-		CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
-		Tags:            transaction.Tags,
-	}, nil
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+	if err := recordTransactionChange(tx, actor, opCreate, nil, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
 }
 
-// UpdateTransaction 更新交易
-func (s *BusinessService) UpdateTransaction(ctx context.Context, req *business.Transaction) (*business.Transaction, error) {
+// updateTransaction 按字段掩码更新交易并记录变更历史，paths为空时更新全部可修改字段，需在数据库事务中调用
+func updateTransaction(tx *gorm.DB, actor changeActor, req *business.Transaction, paths []string) (*Transaction, error) {
 	// 查询交易
 	var transaction Transaction
-	result := s.db.First(&transaction, "id = ?", req.Id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	if err := tx.First(&transaction, "id = ?", req.Id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
 			return nil, status.Errorf(codes.NotFound, "Transaction not found")
 		}
-		return nil, status.Errorf(codes.Internal, "Failed to query transaction: %v", result.Error)
+		return nil, status.Errorf(codes.Internal, "Failed to query transaction: %v", err)
+	}
+
+	before := transaction
+	if err := applyTransactionMask(&transaction, req, paths); err != nil {
+		return nil, err
+	}
+
+	// 需要同时拥有原账本和目标账本的编辑权限
+	if _, err := requireLedgerRole(tx, before.LedgerID, actor.userID, roleEditor); err != nil {
+		return nil, err
+	}
+	if transaction.LedgerID != before.LedgerID {
+		if _, err := requireLedgerRole(tx, transaction.LedgerID, actor.userID, roleEditor); err != nil {
+			return nil, err
+		}
 	}
 
 	// 更新交易
-	transaction.LedgerID = req.LedgerId
-	transaction.Type = req.Type
-	transaction.CategoryID = req.CategoryId
-	transaction.SubcategoryID = req.SubcategoryId
-	transaction.AccountID = req.AccountId
-	transaction.TargetAccountID = req.TargetAccountId
-	transaction.Amount = req.Amount
-	transaction.Description = req.Description
-	transaction.Date = req.Date
-	transaction.Tags = req.Tags
 	transaction.SyncTime = time.Now().Unix()
+	transaction.UpdatedBy = actor.userID
+	if err := tx.Save(&transaction).Error; err != nil {
+		return nil, err
+	}
+	if err := recordTransactionChange(tx, actor, opUpdate, &before, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// deleteTransaction 校验权限后删除交易并释放其附件，记录删除前的快照，需在数据库事务中调用
+func deleteTransaction(tx *gorm.DB, actor changeActor, transactionID string) error {
+	// 查询交易
+	var transaction Transaction
+	if err := tx.First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return status.Errorf(codes.NotFound, "Transaction not found")
+		}
+		return status.Errorf(codes.Internal, "Failed to query transaction: %v", err)
+	}
+	if _, err := requireLedgerRole(tx, transaction.LedgerID, actor.userID, roleEditor); err != nil {
+		return err
+	}
 
-	if err := s.db.Save(&transaction).Error; err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to update transaction: %v", err)
+	if err := tx.Delete(&Transaction{}, "id = ?", transactionID).Error; err != nil {
+		return err
 	}
+	if err := releaseAttachments(tx, []string{transactionID}); err != nil {
+		return err
+	}
+	return recordTransactionChange(tx, actor, opDelete, &transaction, nil)
+}
 
-	// 返回更新后的交易
+// internalError 将数据库等非gRPC状态错误包装为Internal错误，已是gRPC状态的错误原样返回
+func internalError(err error, message string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+// ledgerToProto 将账本模型转换为proto消息
+func ledgerToProto(ledger Ledger) *business.Ledger {
+	responseLedger := &business.Ledger{
+		Id:          ledger.ID,
+		Name:        ledger.Name,
+		Description: ledger.Description,
+		UserId:      ledger.UserID,
+		Currency:    ledger.Currency,
+		CreatedAt:   ledger.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   ledger.UpdatedAt.Format(time.RFC3339),
+	}
+	if ledger.ArchivedAt != nil {
+		responseLedger.ArchivedAt = ledger.ArchivedAt.Format(time.RFC3339)
+	}
+	if ledger.DeletedAt.Valid {
+		responseLedger.DeletedAt = ledger.DeletedAt.Time.Format(time.RFC3339)
+	}
+	return responseLedger
+}
+
+// transactionToProto 将交易模型转换为proto消息
+func transactionToProto(transaction Transaction) *business.Transaction {
 	return &business.Transaction{
 		Id:              transaction.ID,
 		LedgerId:        transaction.LedgerID,
@@ -577,26 +877,8 @@ func (s *BusinessService) UpdateTransaction(ctx context.Context, req *business.T
 		CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
 		Tags:            transaction.Tags,
-	}, nil
-}
-
-// DeleteTransaction 删除交易
-func (s *BusinessService) DeleteTransaction(ctx context.Context, req *business.Transaction) (*common.Response, error) {
-	// 删除交易
-	result := s.db.Delete(&Transaction{}, "id = ?", req.Id)
-	if result.Error != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to delete transaction: %v", result.Error)
-	}
-
-	if result.RowsAffected == 0 {
-		return nil, status.Errorf(codes.NotFound, "Transaction not found")
+		UpdatedBy:       transaction.UpdatedBy,
 	}
-
-	return &common.Response{
-		Success: true,
-		Message: "Transaction deleted successfully",
-		Code:    200,
-	}, nil
 }
 
 // Check 健康检查
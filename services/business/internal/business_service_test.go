@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+)
+
+// newTestService 创建使用临时SQLite数据库的业务服务
+func newTestService(t *testing.T) *BusinessService {
+	t.Helper()
+	s := NewBusinessService()
+	if err := s.ConfigureDatabase(DatabaseConfig{
+		Type:         "sqlite3",
+		SQLiteConfig: SQLiteConfig{Path: filepath.Join(t.TempDir(), "business.db")},
+	}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := s.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := s.db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return s
+}
+
+// addTestMember 邀请用户加入账本并接受邀请
+func addTestMember(t *testing.T, s *BusinessService, ledgerID, ownerID, userID, role string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.InviteMember(ctx, &business.InviteMemberRequest{UserId: ownerID, LedgerId: ledgerID, MemberUserId: userID, Role: role}); err != nil {
+		t.Fatalf("InviteMember %s: %v", userID, err)
+	}
+	if _, err := s.AcceptInvitation(ctx, &business.AcceptInvitationRequest{UserId: userID, LedgerId: ledgerID}); err != nil {
+		t.Fatalf("AcceptInvitation %s: %v", userID, err)
+	}
+}
+
+func testTransaction(id, ledgerID, amount string) *business.Transaction {
+	return &business.Transaction{
+		Id:          id,
+		LedgerId:    ledgerID,
+		Type:        transactionTypeExpense,
+		AccountId:   "cash",
+		Amount:      amount,
+		Description: "lunch " + id,
+		Date:        "2024-03-01",
+	}
+}
+
+func TestSyncRejectsUnauthorizedItems(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	ledger := &business.Ledger{Id: "ledger-1", Name: "Family", Currency: "CNY"}
+	existing := testTransaction("tx-1", ledger.Id, "12.50")
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:       "owner",
+		DeviceId:     "device-owner",
+		Ledgers:      []*business.Ledger{ledger},
+		Transactions: []*business.Transaction{existing},
+	}); err != nil {
+		t.Fatalf("owner Sync: %v", err)
+	}
+	addTestMember(t, s, ledger.Id, "owner", "viewer", roleViewer)
+
+	// 查看者回传未修改的账本和交易，并推送一笔新交易和一次账本修改
+	renamed := &business.Ledger{Id: ledger.Id, Name: "Renamed", Currency: "CNY"}
+	resp, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:       "viewer",
+		DeviceId:     "device-viewer",
+		Ledgers:      []*business.Ledger{ledger, renamed},
+		Transactions: []*business.Transaction{testTransaction("tx-1", ledger.Id, "12.5"), testTransaction("tx-2", ledger.Id, "3.00")},
+	})
+	if err != nil {
+		t.Fatalf("viewer Sync: %v", err)
+	}
+
+	var rejected []string
+	for _, rejection := range resp.Rejected {
+		if rejection.Code != int32(codes.PermissionDenied) {
+			t.Errorf("rejection %s/%s code = %d, want PermissionDenied", rejection.Kind, rejection.Id, rejection.Code)
+		}
+		rejected = append(rejected, rejection.Kind+"/"+rejection.Id)
+	}
+	if want := []string{"ledger/ledger-1", "transaction/tx-2"}; !slices.Equal(rejected, want) {
+		t.Errorf("rejected = %v, want %v", rejected, want)
+	}
+	if len(resp.Ledgers) != 1 || resp.Ledgers[0].Name != "Family" {
+		t.Errorf("ledgers = %v, want the unchanged shared ledger", resp.Ledgers)
+	}
+	if len(resp.Transactions) != 1 || resp.Transactions[0].Id != "tx-1" {
+		t.Errorf("transactions = %v, want tx-1", resp.Transactions)
+	}
+
+	var count int64
+	s.db.Model(&Transaction{}).Where("id = ?", "tx-2").Count(&count)
+	if count != 0 {
+		t.Errorf("rejected transaction was saved")
+	}
+}
+
+func TestSyncDeletedTransactionIDs(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	ledger := &business.Ledger{Id: "ledger-1", Name: "Family", Currency: "CNY"}
+	other := &business.Ledger{Id: "ledger-2", Name: "Private", Currency: "CNY"}
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:   "owner",
+		DeviceId: "device-owner",
+		Ledgers:  []*business.Ledger{ledger, other},
+		Transactions: []*business.Transaction{
+			testTransaction("tx-1", ledger.Id, "1.00"),
+			testTransaction("tx-2", ledger.Id, "2.00"),
+			testTransaction("tx-3", other.Id, "3.00"),
+			testTransaction("tx-4", ledger.Id, "4.00"),
+		},
+	}); err != nil {
+		t.Fatalf("owner Sync: %v", err)
+	}
+	addTestMember(t, s, ledger.Id, "owner", "member", roleViewer)
+
+	beforeDelete := time.Now().Unix() - 1
+	actor := changeActor{userID: "owner", deviceID: "device-owner"}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range []string{"tx-1", "tx-3", "tx-4"} {
+			if err := deleteTransaction(tx, actor, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("delete transactions: %v", err)
+	}
+
+	// 删除后恢复的交易不再通知客户端删除
+	if _, err := s.RestoreRevision(ctx, &business.RestoreRevisionRequest{UserId: "owner", EntityType: entityTransaction, EntityId: "tx-4", Revision: 1}); err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		userID       string
+		lastSyncTime int64
+		want         []string
+	}{
+		{"owner sees deletions in all ledgers", "owner", beforeDelete, []string{"tx-1", "tx-3"}},
+		{"member sees deletions in shared ledger only", "member", beforeDelete, []string{"tx-1"}},
+		{"deletions before last sync are omitted", "owner", time.Now().Unix() + 1, nil},
+		{"non-member sees nothing", "stranger", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.Sync(ctx, &business.SyncRequest{UserId: tt.userID, LastSyncTime: tt.lastSyncTime})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			got := slices.Sorted(slices.Values(resp.DeletedTransactionIds))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DeletedTransactionIds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
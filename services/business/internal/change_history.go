@@ -0,0 +1,366 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 变更记录类型
+const (
+	entityLedger      = "ledger"
+	entityTransaction = "transaction"
+)
+
+// 变更操作
+const (
+	opCreate  = "create"
+	opUpdate  = "update"
+	opDelete  = "delete"
+	opRestore = "restore"
+)
+
+// deviceIDMetadataKey 客户端设备ID的gRPC元数据键
+const deviceIDMetadataKey = "x-device-id"
+
+// errHistoryImmutable 变更历史只允许追加
+var errHistoryImmutable = errors.New("change history is append-only")
+
+// ChangeHistory 变更历史模型（只追加）
+type ChangeHistory struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	EntityType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_change_revision"`
+	EntityID   string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_change_revision"`
+	Revision   int       `gorm:"not null;uniqueIndex:idx_change_revision"`
+	LedgerID   string    `gorm:"type:varchar(36);not null;index"`
+	Operation  string    `gorm:"type:varchar(20);not null"`
+	ActorID    string    `gorm:"type:varchar(36);not null;index"`
+	DeviceID   string    `gorm:"type:varchar(36)"`
+	Before     string    `gorm:"type:text"`
+	After      string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index"`
+}
+
+// BeforeUpdate 禁止修改变更历史
+func (h *ChangeHistory) BeforeUpdate(tx *gorm.DB) error {
+	return errHistoryImmutable
+}
+
+// BeforeDelete 禁止删除变更历史
+func (h *ChangeHistory) BeforeDelete(tx *gorm.DB) error {
+	return errHistoryImmutable
+}
+
+// changeActor 执行变更的成员和设备
+type changeActor struct {
+	userID   string
+	deviceID string
+}
+
+// actorFromContext 从请求上下文中获取设备ID，构造变更执行者
+func actorFromContext(ctx context.Context, userID string) changeActor {
+	actor := changeActor{userID: userID}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(deviceIDMetadataKey); len(values) > 0 {
+			actor.deviceID = values[0]
+		}
+	}
+	return actor
+}
+
+// recordLedgerChange 记录账本变更
+func recordLedgerChange(tx *gorm.DB, actor changeActor, operation string, before, after *Ledger) error {
+	change, err := ledgerChange(operation, before, after)
+	if err != nil {
+		return err
+	}
+	return appendChange(tx, actor, change)
+}
+
+// recordTransactionChange 记录交易变更
+func recordTransactionChange(tx *gorm.DB, actor changeActor, operation string, before, after *Transaction) error {
+	change, err := transactionChange(operation, before, after)
+	if err != nil {
+		return err
+	}
+	return appendChange(tx, actor, change)
+}
+
+// ledgerChange 构造账本变更记录（不含版本号和执行者）
+func ledgerChange(operation string, before, after *Ledger) (*ChangeHistory, error) {
+	change := &ChangeHistory{EntityType: entityLedger, Operation: operation}
+	for _, ledger := range []*Ledger{before, after} {
+		if ledger != nil {
+			change.EntityID = ledger.ID
+			change.LedgerID = ledger.ID
+		}
+	}
+
+	var err error
+	if change.Before, err = snapshot(before != nil, func() interface{} { return ledgerToProto(*before) }); err != nil {
+		return nil, err
+	}
+	if change.After, err = snapshot(after != nil, func() interface{} { return ledgerToProto(*after) }); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// transactionChange 构造交易变更记录（不含版本号和执行者）
+func transactionChange(operation string, before, after *Transaction) (*ChangeHistory, error) {
+	change := &ChangeHistory{EntityType: entityTransaction, Operation: operation}
+	for _, transaction := range []*Transaction{before, after} {
+		if transaction != nil {
+			change.EntityID = transaction.ID
+			change.LedgerID = transaction.LedgerID
+		}
+	}
+
+	var err error
+	if change.Before, err = snapshot(before != nil, func() interface{} { return transactionToProto(*before) }); err != nil {
+		return nil, err
+	}
+	if change.After, err = snapshot(after != nil, func() interface{} { return transactionToProto(*after) }); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// snapshot 将记录序列化为JSON快照
+func snapshot(present bool, message func() interface{}) (string, error) {
+	if !present {
+		return "", nil
+	}
+	data, err := json.Marshal(message())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// appendChange 分配版本号并写入变更记录
+// 调用方已在同一事务中写入实体行并持有其行锁，并发修改同一记录的事务在此排队；
+// 最新版本号使用加锁读取，读到的是已提交的版本而不是事务开始时的快照
+func appendChange(tx *gorm.DB, actor changeActor, change *ChangeHistory) error {
+	var latest int
+	if err := tx.Model(&ChangeHistory{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("entity_type = ? AND entity_id = ?", change.EntityType, change.EntityID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	change.Revision = latest + 1
+	change.ActorID = actor.userID
+	change.DeviceID = actor.deviceID
+	return tx.Create(change).Error
+}
+
+// GetHistory 获取记录的变更历史
+func (s *BusinessService) GetHistory(ctx context.Context, req *business.GetHistoryRequest) (*business.GetHistoryResponse, error) {
+	if req.EntityType != entityLedger && req.EntityType != entityTransaction {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid entity type: %s", req.EntityType)
+	}
+
+	// 设置默认分页
+	page := req.Page
+	pageSize := req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	// 记录可能已被删除，按最近一次变更所属的账本校验权限
+	latest, err := s.latestChange(req.EntityType, req.EntityId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := requireLedgerRole(s.db, latest.LedgerID, req.UserId, roleViewer); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Model(&ChangeHistory{}).Where("entity_type = ? AND entity_id = ?", req.EntityType, req.EntityId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to count history: %v", err)
+	}
+
+	var changes []ChangeHistory
+	if err := query.Order("revision DESC").Offset(int((page - 1) * pageSize)).Limit(int(pageSize)).Find(&changes).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query history: %v", err)
+	}
+
+	revisions := make([]*business.ChangeRevision, 0, len(changes))
+	for _, change := range changes {
+		revisions = append(revisions, changeToProto(change))
+	}
+
+	return &business.GetHistoryResponse{
+		Revisions: revisions,
+		Total:     int32(total),
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+// RestoreRevision 将记录恢复为指定版本变更后的状态
+func (s *BusinessService) RestoreRevision(ctx context.Context, req *business.RestoreRevisionRequest) (*business.ChangeRevision, error) {
+	var target ChangeHistory
+	if err := s.db.First(&target, "entity_type = ? AND entity_id = ? AND revision = ?", req.EntityType, req.EntityId, req.Revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Revision not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query revision: %v", err)
+	}
+	if target.After == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "Revision %d deleted the record, restore an earlier revision instead", req.Revision)
+	}
+
+	actor := changeActor{userID: req.UserId, deviceID: req.DeviceId}
+	var restored ChangeHistory
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch req.EntityType {
+		case entityLedger:
+			err = restoreLedger(tx, actor, target)
+		case entityTransaction:
+			err = restoreTransaction(tx, actor, target)
+		default:
+			return status.Errorf(codes.InvalidArgument, "Invalid entity type: %s", req.EntityType)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Where("entity_type = ? AND entity_id = ?", req.EntityType, req.EntityId).Order("revision DESC").First(&restored).Error
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to restore revision: %v", err)
+	}
+
+	return changeToProto(restored), nil
+}
+
+// restoreLedger 恢复账本信息
+func restoreLedger(tx *gorm.DB, actor changeActor, target ChangeHistory) error {
+	var snapshot business.Ledger
+	if err := json.Unmarshal([]byte(target.After), &snapshot); err != nil {
+		return status.Errorf(codes.Internal, "Failed to decode revision: %v", err)
+	}
+
+	if _, err := requireLedgerRole(tx, target.EntityID, actor.userID, roleOwner); err != nil {
+		return err
+	}
+
+	var ledger Ledger
+	if err := tx.First(&ledger, "id = ?", target.EntityID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return status.Errorf(codes.FailedPrecondition, "Ledger has been deleted")
+		}
+		return err
+	}
+
+	before := ledger
+	ledger.Name = snapshot.Name
+	ledger.Description = snapshot.Description
+	ledger.Currency = snapshot.Currency
+	if err := tx.Save(&ledger).Error; err != nil {
+		return err
+	}
+	return recordLedgerChange(tx, actor, opRestore, &before, &ledger)
+}
+
+// restoreTransaction 恢复交易，已删除的交易会以原ID重新创建
+func restoreTransaction(tx *gorm.DB, actor changeActor, target ChangeHistory) error {
+	var snapshot business.Transaction
+	if err := json.Unmarshal([]byte(target.After), &snapshot); err != nil {
+		return status.Errorf(codes.Internal, "Failed to decode revision: %v", err)
+	}
+
+	if _, err := requireLedgerRole(tx, snapshot.LedgerId, actor.userID, roleEditor); err != nil {
+		return err
+	}
+
+	var transaction Transaction
+	var before *Transaction
+	result := tx.First(&transaction, "id = ?", target.EntityID)
+	switch {
+	case result.Error == nil:
+		// 交易已移动到其他账本时，还需要当前账本的编辑权限
+		if transaction.LedgerID != snapshot.LedgerId {
+			if _, err := requireLedgerRole(tx, transaction.LedgerID, actor.userID, roleEditor); err != nil {
+				return err
+			}
+		}
+		current := transaction
+		before = &current
+	case result.Error == gorm.ErrRecordNotFound:
+		transaction = Transaction{ID: target.EntityID, UserID: snapshot.UserId}
+	default:
+		return result.Error
+	}
+
+	transaction.LedgerID = snapshot.LedgerId
+	transaction.Type = snapshot.Type
+	transaction.CategoryID = snapshot.CategoryId
+	transaction.SubcategoryID = snapshot.SubcategoryId
+	transaction.AccountID = snapshot.AccountId
+	transaction.TargetAccountID = snapshot.TargetAccountId
+	transaction.Amount = snapshot.Amount
+	transaction.Description = snapshot.Description
+	transaction.Date = snapshot.Date
+	transaction.Tags = snapshot.Tags
+	transaction.SyncTime = time.Now().Unix()
+	transaction.DeviceID = actor.deviceID
+	transaction.UpdatedBy = actor.userID
+
+	if before == nil {
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Save(&transaction).Error; err != nil {
+		return err
+	}
+	return recordTransactionChange(tx, actor, opRestore, before, &transaction)
+}
+
+// latestChange 查询记录最近一次变更
+func (s *BusinessService) latestChange(entityType, entityID string) (*ChangeHistory, error) {
+	var change ChangeHistory
+	if err := s.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("revision DESC").First(&change).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "History not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query history: %v", err)
+	}
+	return &change, nil
+}
+
+// changeToProto 将变更记录转换为proto消息
+func changeToProto(change ChangeHistory) *business.ChangeRevision {
+	return &business.ChangeRevision{
+		Id:         change.ID,
+		EntityType: change.EntityType,
+		EntityId:   change.EntityID,
+		LedgerId:   change.LedgerID,
+		Revision:   int32(change.Revision),
+		Operation:  change.Operation,
+		ActorId:    change.ActorID,
+		DeviceId:   change.DeviceID,
+		Before:     change.Before,
+		After:      change.After,
+		CreatedAt:  change.CreatedAt.Format(time.RFC3339),
+	}
+}
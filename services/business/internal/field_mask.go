@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"math/big"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 交易类型
+const (
+	transactionTypeIncome   = "income"
+	transactionTypeExpense  = "expense"
+	transactionTypeTransfer = "transfer"
+)
+
+const (
+	// maxNameLength 账本名称的最大字符数
+	maxNameLength = 255
+	// maxDescriptionLength 描述的最大字符数
+	maxDescriptionLength = 2000
+	// maxTags 单笔交易的最大标签数
+	maxTags = 50
+	// defaultCurrency 未指定货币时账本使用的默认货币
+	defaultCurrency = "CNY"
+	// transactionDateLayout 交易日期格式
+	transactionDateLayout = "2006-01-02"
+)
+
+// maxAmount 金额上限，与decimal(20,2)列的精度一致
+var maxAmount = new(big.Rat).SetFrac(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), big.NewInt(1))
+
+// fieldRule 实体字段校验规则，fields中任一字段被修改时执行
+// check可以规范化字段值，例如统一金额的小数位数
+type fieldRule[T any] struct {
+	fields []string
+	check  func(entity *T) error
+}
+
+// ledgerMaskFields 可通过字段掩码更新的账本字段
+var ledgerMaskFields = map[string]func(ledger *Ledger, update *business.Ledger){
+	"name":        func(l *Ledger, u *business.Ledger) { l.Name = u.Name },
+	"description": func(l *Ledger, u *business.Ledger) { l.Description = u.Description },
+	"currency":    func(l *Ledger, u *business.Ledger) { l.Currency = u.Currency },
+}
+
+// transactionMaskFields 可通过字段掩码更新的交易字段
+var transactionMaskFields = map[string]func(transaction *Transaction, update *business.Transaction){
+	"ledger_id":         func(t *Transaction, u *business.Transaction) { t.LedgerID = u.LedgerId },
+	"type":              func(t *Transaction, u *business.Transaction) { t.Type = u.Type },
+	"category_id":       func(t *Transaction, u *business.Transaction) { t.CategoryID = u.CategoryId },
+	"subcategory_id":    func(t *Transaction, u *business.Transaction) { t.SubcategoryID = u.SubcategoryId },
+	"account_id":        func(t *Transaction, u *business.Transaction) { t.AccountID = u.AccountId },
+	"target_account_id": func(t *Transaction, u *business.Transaction) { t.TargetAccountID = u.TargetAccountId },
+	"amount":            func(t *Transaction, u *business.Transaction) { t.Amount = u.Amount },
+	"description":       func(t *Transaction, u *business.Transaction) { t.Description = u.Description },
+	"date":              func(t *Transaction, u *business.Transaction) { t.Date = u.Date },
+	"tags":              func(t *Transaction, u *business.Transaction) { t.Tags = u.Tags },
+}
+
+// ledgerRules 账本字段校验规则
+var ledgerRules = []fieldRule[Ledger]{
+	{fields: []string{"name"}, check: func(l *Ledger) error {
+		if l.Name == "" {
+			return status.Errorf(codes.InvalidArgument, "Ledger name is required")
+		}
+		if utf8.RuneCountInString(l.Name) > maxNameLength {
+			return status.Errorf(codes.InvalidArgument, "Ledger name must be at most %d characters", maxNameLength)
+		}
+		return nil
+	}},
+	{fields: []string{"description"}, check: func(l *Ledger) error {
+		return checkDescription(l.Description)
+	}},
+	{fields: []string{"currency"}, check: func(l *Ledger) error {
+		if !isCurrencyCode(l.Currency) {
+			return status.Errorf(codes.InvalidArgument, "Invalid currency code: %s", l.Currency)
+		}
+		return nil
+	}},
+}
+
+// transactionRules 交易字段校验规则
+var transactionRules = []fieldRule[Transaction]{
+	{fields: []string{"ledger_id"}, check: func(t *Transaction) error {
+		if t.LedgerID == "" {
+			return status.Errorf(codes.InvalidArgument, "Ledger ID is required")
+		}
+		return nil
+	}},
+	{fields: []string{"type"}, check: func(t *Transaction) error {
+		switch t.Type {
+		case transactionTypeIncome, transactionTypeExpense, transactionTypeTransfer:
+			return nil
+		}
+		return status.Errorf(codes.InvalidArgument, "Invalid transaction type: %s", t.Type)
+	}},
+	{fields: []string{"account_id"}, check: func(t *Transaction) error {
+		if t.AccountID == "" {
+			return status.Errorf(codes.InvalidArgument, "Account ID is required")
+		}
+		return nil
+	}},
+	{fields: []string{"amount"}, check: func(t *Transaction) error {
+		amount, ok := new(big.Rat).SetString(t.Amount)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "Invalid amount: %s", t.Amount)
+		}
+		if amount.Sign() < 0 {
+			return status.Errorf(codes.InvalidArgument, "Amount must not be negative")
+		}
+		if amount.Cmp(maxAmount) >= 0 {
+			return status.Errorf(codes.InvalidArgument, "Amount is too large")
+		}
+		t.Amount = amount.FloatString(2)
+		return nil
+	}},
+	{fields: []string{"date"}, check: func(t *Transaction) error {
+		if _, err := time.Parse(transactionDateLayout, t.Date); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid date %q, expected YYYY-MM-DD", t.Date)
+		}
+		return nil
+	}},
+	{fields: []string{"description"}, check: func(t *Transaction) error {
+		return checkDescription(t.Description)
+	}},
+	{fields: []string{"tags"}, check: func(t *Transaction) error {
+		if len(t.Tags) > maxTags {
+			return status.Errorf(codes.InvalidArgument, "A transaction can have at most %d tags", maxTags)
+		}
+		for key := range t.Tags {
+			if key == "" {
+				return status.Errorf(codes.InvalidArgument, "Tag name must not be empty")
+			}
+		}
+		return nil
+	}},
+	// 转账需要不同的转出和转入账户
+	{fields: []string{"type", "account_id", "target_account_id"}, check: func(t *Transaction) error {
+		if t.Type != transactionTypeTransfer {
+			return nil
+		}
+		if t.TargetAccountID == "" {
+			return status.Errorf(codes.InvalidArgument, "Target account ID is required for transfers")
+		}
+		if t.TargetAccountID == t.AccountID {
+			return status.Errorf(codes.InvalidArgument, "Target account must differ from the source account")
+		}
+		return nil
+	}},
+}
+
+// applyLedgerMask 将update中掩码指定的字段写入账本并校验，paths为空时写入全部可修改字段
+func applyLedgerMask(ledger *Ledger, update *business.Ledger, paths []string) error {
+	if err := applyMask(ledger, update, ledgerMaskFields, paths); err != nil {
+		return err
+	}
+	return validateFields(ledger, ledgerRules, paths)
+}
+
+// applyTransactionMask 将update中掩码指定的字段写入交易并校验，paths为空时写入全部可修改字段
+func applyTransactionMask(transaction *Transaction, update *business.Transaction, paths []string) error {
+	if err := applyMask(transaction, update, transactionMaskFields, paths); err != nil {
+		return err
+	}
+	return validateFields(transaction, transactionRules, paths)
+}
+
+// applyMask 按字段掩码复制字段，掩码包含不可修改或不存在的字段时返回InvalidArgument
+func applyMask[T, U any](entity *T, update U, fields map[string]func(*T, U), paths []string) error {
+	if len(paths) == 0 {
+		for _, apply := range fields {
+			apply(entity, update)
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		apply, ok := fields[path]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "Field %s cannot be updated", path)
+		}
+		apply(entity, update)
+	}
+	return nil
+}
+
+// validateFields 执行涉及已修改字段的校验规则，paths为空时执行全部规则
+func validateFields[T any](entity *T, rules []fieldRule[T], paths []string) error {
+	changed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		changed[path] = true
+	}
+
+	for _, rule := range rules {
+		applies := len(paths) == 0
+		for _, field := range rule.fields {
+			applies = applies || changed[field]
+		}
+		if !applies {
+			continue
+		}
+		if err := rule.check(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDescription 校验描述长度
+func checkDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return status.Errorf(codes.InvalidArgument, "Description must be at most %d characters", maxDescriptionLength)
+	}
+	return nil
+}
+
+// isCurrencyCode 判断是否为三位大写字母的ISO 4217货币代码
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
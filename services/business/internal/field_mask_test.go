@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestApplyTransactionMask(t *testing.T) {
+	existing := Transaction{
+		ID:          "tx-1",
+		LedgerID:    "ledger-1",
+		Type:        transactionTypeExpense,
+		AccountID:   "cash",
+		Amount:      "10.00",
+		Description: "lunch",
+		Date:        "2024-03-01",
+		Tags:        map[string]string{"trip": ""},
+	}
+
+	tests := []struct {
+		name     string
+		update   *business.Transaction
+		paths    []string
+		want     func(t *Transaction)
+		wantCode codes.Code
+	}{
+		{
+			name:   "masked field only",
+			update: &business.Transaction{Description: "dinner", Amount: "99"},
+			paths:  []string{"description"},
+			want:   func(t *Transaction) { t.Description = "dinner" },
+		},
+		{
+			name:   "amount is normalized",
+			update: &business.Transaction{Amount: "7.5"},
+			paths:  []string{"amount"},
+			want:   func(t *Transaction) { t.Amount = "7.50" },
+		},
+		{
+			name: "empty mask replaces all fields",
+			update: &business.Transaction{
+				LedgerId: "ledger-2", Type: transactionTypeIncome, AccountId: "bank", Amount: "3", Date: "2024-04-01",
+			},
+			want: func(t *Transaction) {
+				t.LedgerID, t.Type, t.AccountID, t.Amount, t.Date = "ledger-2", transactionTypeIncome, "bank", "3.00", "2024-04-01"
+				t.Description, t.Tags = "", nil
+			},
+		},
+		{
+			name:     "unknown field",
+			update:   &business.Transaction{},
+			paths:    []string{"user_id"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "masked field is validated",
+			update:   &business.Transaction{Date: "01/03/2024"},
+			paths:    []string{"date"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "transfer needs a target account",
+			update:   &business.Transaction{Type: transactionTypeTransfer},
+			paths:    []string{"type"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "negative amount",
+			update:   &business.Transaction{Amount: "-1"},
+			paths:    []string{"amount"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := existing
+			got.Tags = maps.Clone(existing.Tags)
+			err := applyTransactionMask(&got, tt.update, tt.paths)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("applyTransactionMask code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			want := existing
+			want.Tags = maps.Clone(existing.Tags)
+			tt.want(&want)
+			if got.LedgerID != want.LedgerID || got.Type != want.Type || got.AccountID != want.AccountID ||
+				got.Amount != want.Amount || got.Description != want.Description || got.Date != want.Date ||
+				!maps.Equal(got.Tags, want.Tags) {
+				t.Errorf("applyTransactionMask = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyLedgerMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   *business.Ledger
+		paths    []string
+		wantName string
+		wantCode codes.Code
+	}{
+		{"rename", &business.Ledger{Name: "Trip", Currency: "bad"}, []string{"name"}, "Trip", codes.OK},
+		{"empty name", &business.Ledger{}, []string{"name"}, "", codes.InvalidArgument},
+		{"invalid currency", &business.Ledger{Currency: "eur"}, []string{"currency"}, "", codes.InvalidArgument},
+		{"owner is not updatable", &business.Ledger{UserId: "other"}, []string{"user_id"}, "", codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := Ledger{Name: "Daily", Currency: "CNY"}
+			err := applyLedgerMask(&ledger, tt.update, tt.paths)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("applyLedgerMask code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if err == nil && (ledger.Name != tt.wantName || ledger.Currency != "CNY") {
+				t.Errorf("applyLedgerMask = %+v, want name %s with currency unchanged", ledger, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,545 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// exportChunkSize 导出流每个分块的大小
+const exportChunkSize = 64 * 1024
+
+// exportContentTypes 导出格式对应的MIME类型
+var exportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"json": "application/json",
+	"qif":  "application/qif",
+	"ofx":  "application/x-ofx",
+}
+
+// exportHeader 导出文件的列
+var exportHeader = []string{
+	"id", "date", "type", "amount", "category_id", "subcategory_id",
+	"account_id", "target_account_id", "description", "tags",
+}
+
+// exportRange 导出数据的日期范围
+type exportRange struct {
+	start string
+	end   string
+}
+
+// ledgerExporter 按格式输出账本数据
+type ledgerExporter interface {
+	begin(ledger Ledger, dates exportRange) error
+	write(transaction Transaction) error
+	end(accounts, categories []string) error
+}
+
+// ExportLedger 导出账本（流式）
+func (s *BusinessService) ExportLedger(req *business.ExportLedgerRequest, stream business.BusinessService_ExportLedgerServer) error {
+	format := strings.ToLower(req.Format)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "Unsupported export format: %s", req.Format)
+	}
+	for _, date := range []string{req.StartDate, req.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid date: %s", date)
+		}
+	}
+
+	// 校验账本访问权限
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleViewer); err != nil {
+		return err
+	}
+	var ledger Ledger
+	if err := s.db.First(&ledger, "id = ?", req.LedgerId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return status.Errorf(codes.NotFound, "Ledger not found")
+		}
+		return status.Errorf(codes.Internal, "Failed to query ledger: %v", err)
+	}
+
+	// 构建查询条件
+	query := func() *gorm.DB {
+		q := s.db.Model(&Transaction{}).Where("ledger_id = ?", req.LedgerId)
+		if req.StartDate != "" {
+			q = q.Where("date >= ?", req.StartDate)
+		}
+		if req.EndDate != "" {
+			q = q.Where("date <= ?", req.EndDate)
+		}
+		return q
+	}
+
+	// 统计数量和日期范围
+	var summary struct {
+		Count   int64
+		MinDate string
+		MaxDate string
+	}
+	if err := query().Select("COUNT(*) AS count, COALESCE(MIN(date), '') AS min_date, COALESCE(MAX(date), '') AS max_date").
+		Scan(&summary).Error; err != nil {
+		return status.Errorf(codes.Internal, "Failed to count transactions: %v", err)
+	}
+	dates := exportRange{start: req.StartDate, end: req.EndDate}
+	if dates.start == "" {
+		dates.start = summary.MinDate
+	}
+	if dates.end == "" {
+		dates.end = summary.MaxDate
+	}
+
+	// 发送文件元信息
+	if err := stream.Send(&business.ExportLedgerResponse{
+		Filename:         exportFilename(ledger.Name, format),
+		ContentType:      contentType,
+		TransactionCount: summary.Count,
+	}); err != nil {
+		return status.Errorf(codes.Internal, "Failed to send export header: %v", err)
+	}
+
+	writer := &exportStreamWriter{stream: stream}
+	var exporter ledgerExporter
+	order := "date ASC, created_at ASC"
+	switch format {
+	case "csv":
+		exporter = &csvExporter{w: writer}
+	case "xlsx":
+		exporter = &xlsxExporter{w: writer}
+	case "json":
+		exporter = &jsonExporter{w: writer}
+	case "qif":
+		// QIF按账户分段输出
+		exporter = &qifExporter{w: writer}
+		order = "account_id ASC, date ASC, created_at ASC"
+	case "ofx":
+		exporter = &ofxExporter{w: writer}
+	}
+
+	if err := exporter.begin(ledger, dates); err != nil {
+		return status.Errorf(codes.Internal, "Failed to write export: %v", err)
+	}
+
+	// 使用游标逐行读取，避免一次性加载整个账本
+	rows, err := query().Order(order).Rows()
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to query transactions: %v", err)
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]bool)
+	categories := make(map[string]bool)
+	for rows.Next() {
+		var transaction Transaction
+		if err := s.db.ScanRows(rows, &transaction); err != nil {
+			return status.Errorf(codes.Internal, "Failed to read transaction: %v", err)
+		}
+		for _, id := range []string{transaction.AccountID, transaction.TargetAccountID} {
+			if id != "" {
+				accounts[id] = true
+			}
+		}
+		for _, id := range []string{transaction.CategoryID, transaction.SubcategoryID} {
+			if id != "" {
+				categories[id] = true
+			}
+		}
+		if err := exporter.write(transaction); err != nil {
+			return status.Errorf(codes.Internal, "Failed to write export: %v", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return status.Errorf(codes.Internal, "Failed to read transactions: %v", err)
+	}
+
+	if err := exporter.end(sortedKeys(accounts), sortedKeys(categories)); err != nil {
+		return status.Errorf(codes.Internal, "Failed to write export: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return status.Errorf(codes.Internal, "Failed to send export: %v", err)
+	}
+	return nil
+}
+
+// exportStreamWriter 将写入的数据按块发送到gRPC流
+type exportStreamWriter struct {
+	stream business.BusinessService_ExportLedgerServer
+	buf    []byte
+}
+
+// Write 缓冲数据，满一块后发送
+func (w *exportStreamWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= exportChunkSize {
+		if err := w.stream.Send(&business.ExportLedgerResponse{Chunk: w.buf[:exportChunkSize]}); err != nil {
+			return 0, err
+		}
+		w.buf = append([]byte(nil), w.buf[exportChunkSize:]...)
+	}
+	return len(p), nil
+}
+
+// Close 发送剩余数据并标记结束
+func (w *exportStreamWriter) Close() error {
+	err := w.stream.Send(&business.ExportLedgerResponse{Chunk: w.buf, IsLastChunk: true})
+	w.buf = nil
+	return err
+}
+
+// csvExporter CSV格式导出
+type csvExporter struct {
+	w   io.Writer
+	csv *csv.Writer
+}
+
+func (e *csvExporter) begin(_ Ledger, _ exportRange) error {
+	// 写入BOM，便于Excel正确识别UTF-8
+	if _, err := e.w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(exportHeader)
+}
+
+func (e *csvExporter) write(t Transaction) error {
+	return e.csv.Write(exportRecord(t))
+}
+
+func (e *csvExporter) end(_, _ []string) error {
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// xlsxExporter XLSX格式导出，交易、账户和分类分别写入不同工作表
+type xlsxExporter struct {
+	w      io.Writer
+	file   *excelize.File
+	sheet  *excelize.StreamWriter
+	rowNum int
+}
+
+func (e *xlsxExporter) begin(_ Ledger, _ exportRange) error {
+	e.file = excelize.NewFile()
+	if err := e.file.SetSheetName("Sheet1", "Transactions"); err != nil {
+		return err
+	}
+
+	sheet, err := e.file.NewStreamWriter("Transactions")
+	if err != nil {
+		return err
+	}
+	e.sheet = sheet
+	e.rowNum = 1
+	return e.writeRow(exportHeader)
+}
+
+func (e *xlsxExporter) write(t Transaction) error {
+	record := exportRecord(t)
+	row := make([]interface{}, len(record))
+	for i, value := range record {
+		row[i] = value
+	}
+	// 金额以数值写入，便于在表格中计算
+	if amount, err := strconv.ParseFloat(t.Amount, 64); err == nil {
+		row[3] = amount
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, e.rowNum)
+	if err != nil {
+		return err
+	}
+	e.rowNum++
+	return e.sheet.SetRow(cell, row)
+}
+
+func (e *xlsxExporter) end(accounts, categories []string) error {
+	defer e.file.Close()
+
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	for name, ids := range map[string][]string{"Accounts": accounts, "Categories": categories} {
+		if _, err := e.file.NewSheet(name); err != nil {
+			return err
+		}
+		if err := e.file.SetCellValue(name, "A1", "id"); err != nil {
+			return err
+		}
+		for i, id := range ids {
+			if err := e.file.SetCellValue(name, fmt.Sprintf("A%d", i+2), id); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := e.file.WriteTo(e.w)
+	return err
+}
+
+func (e *xlsxExporter) writeRow(values []string) error {
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+	}
+	cell, err := excelize.CoordinatesToCellName(1, e.rowNum)
+	if err != nil {
+		return err
+	}
+	e.rowNum++
+	return e.sheet.SetRow(cell, row)
+}
+
+// jsonExporter JSON格式导出
+type jsonExporter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExporter) begin(ledger Ledger, _ exportRange) error {
+	data, err := json.Marshal(ledgerToProto(ledger))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, `{"ledger":%s,"transactions":[`, data)
+	return err
+}
+
+func (e *jsonExporter) write(t Transaction) error {
+	data, err := json.Marshal(transactionToProto(t))
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := e.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) end(accounts, categories []string) error {
+	accountsData, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+	categoriesData, err := json.Marshal(categories)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, `],"accounts":%s,"categories":%s}`, accountsData, categoriesData)
+	return err
+}
+
+// qifExporter QIF格式导出，每个账户输出一个!Account段
+type qifExporter struct {
+	w       io.Writer
+	account string
+	started bool
+}
+
+func (e *qifExporter) begin(_ Ledger, _ exportRange) error {
+	return nil
+}
+
+func (e *qifExporter) write(t Transaction) error {
+	var b strings.Builder
+	if !e.started || t.AccountID != e.account {
+		e.started = true
+		e.account = t.AccountID
+		fmt.Fprintf(&b, "!Account\nN%s\nTBank\n^\n!Type:Bank\n", qifText(t.AccountID))
+	}
+
+	date, err := time.Parse("2006-01-02", t.Date)
+	if err == nil {
+		fmt.Fprintf(&b, "D%s\n", date.Format("01/02/2006"))
+	} else {
+		fmt.Fprintf(&b, "D%s\n", t.Date)
+	}
+	fmt.Fprintf(&b, "T%s\n", signedAmount(t))
+	if t.Description != "" {
+		fmt.Fprintf(&b, "P%s\n", qifText(t.Description))
+	}
+	switch {
+	case t.Type == "transfer" && t.TargetAccountID != "":
+		fmt.Fprintf(&b, "L[%s]\n", qifText(t.TargetAccountID))
+	case t.SubcategoryID != "":
+		fmt.Fprintf(&b, "L%s:%s\n", qifText(t.CategoryID), qifText(t.SubcategoryID))
+	case t.CategoryID != "":
+		fmt.Fprintf(&b, "L%s\n", qifText(t.CategoryID))
+	}
+	b.WriteString("^\n")
+
+	_, err = io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *qifExporter) end(_, _ []string) error {
+	return nil
+}
+
+// ofxExporter OFX 2.x格式导出，整个账本输出为一个对账单
+type ofxExporter struct {
+	w        io.Writer
+	currency string
+	dtEnd    string
+	balance  *big.Rat
+}
+
+func (e *ofxExporter) begin(ledger Ledger, dates exportRange) error {
+	e.currency = ledger.Currency
+	if e.currency == "" {
+		e.currency = defaultCurrency
+	}
+	e.dtEnd = ofxDate(dates.end)
+	e.balance = new(big.Rat)
+
+	now := time.Now().Format("20060102150405")
+	_, err := fmt.Fprintf(e.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>BeeCount</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`, now, xmlText(e.currency), xmlText(ledger.ID), ofxDate(dates.start), e.dtEnd)
+	return err
+}
+
+func (e *ofxExporter) write(t Transaction) error {
+	amount := signedAmount(t)
+	if r, ok := new(big.Rat).SetString(amount); ok {
+		e.balance.Add(e.balance, r)
+	}
+
+	trnType := "CREDIT"
+	if strings.HasPrefix(amount, "-") {
+		trnType = "DEBIT"
+	}
+	if t.Type == "transfer" {
+		trnType = "XFER"
+	}
+
+	// NAME字段最长32个字符
+	name := []rune(t.Description)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	_, err := fmt.Fprintf(e.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		trnType, ofxDate(t.Date), amount, xmlText(t.ID), xmlText(string(name)), xmlText(t.Description))
+	return err
+}
+
+func (e *ofxExporter) end(_, _ []string) error {
+	_, err := fmt.Fprintf(e.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`, e.balance.FloatString(2), e.dtEnd)
+	return err
+}
+
+// exportRecord 将交易转换为导出行
+func exportRecord(t Transaction) []string {
+	return []string{
+		t.ID, t.Date, t.Type, t.Amount, t.CategoryID, t.SubcategoryID,
+		t.AccountID, t.TargetAccountID, t.Description, formatExportTags(t.Tags),
+	}
+}
+
+// formatExportTags 将标签格式化为"key=value;key2"，与导入格式一致
+func formatExportTags(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		if tags[key] == "" {
+			parts = append(parts, key)
+		} else {
+			parts = append(parts, key+"="+tags[key])
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// signedAmount 返回带符号的金额，支出和转出为负数
+func signedAmount(t Transaction) string {
+	amount := t.Amount
+	if normalized, err := normalizeAmount(amount); err == nil {
+		amount = normalized
+	}
+	if (t.Type == "expense" || t.Type == "transfer") && !strings.HasPrefix(amount, "-") {
+		return "-" + amount
+	}
+	return amount
+}
+
+// exportFilename 生成导出文件名
+func exportFilename(ledgerName, format string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/:*?"<>|`, r) || r < 0x20 || r == ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(ledgerName))
+	if name == "" {
+		name = "ledger"
+	}
+	return fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102"), format)
+}
+
+// ofxDate 将YYYY-MM-DD转换为OFX日期格式
+func ofxDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
+
+// qifText 去除QIF字段中的换行
+func qifText(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// xmlText 转义XML文本
+func xmlText(value string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// trashRetention 回收站中的账本保留时长，超过后永久删除
+	trashRetention = 30 * 24 * time.Hour
+	// backgroundJobInterval 后台任务执行间隔
+	backgroundJobInterval = 10 * time.Minute
+	// systemActorID 后台任务记录变更历史时使用的执行者
+	systemActorID = "system"
+)
+
+// 账本生命周期操作
+const (
+	opArchive   = "archive"
+	opUnarchive = "unarchive"
+	opPurge     = "purge"
+)
+
+// ArchiveLedger 归档账本，归档后不出现在列表和同步中
+func (s *BusinessService) ArchiveLedger(ctx context.Context, req *business.LedgerActionRequest) (*business.Ledger, error) {
+	return s.changeArchiveState(ctx, req, true)
+}
+
+// UnarchiveLedger 取消归档
+func (s *BusinessService) UnarchiveLedger(ctx context.Context, req *business.LedgerActionRequest) (*business.Ledger, error) {
+	return s.changeArchiveState(ctx, req, false)
+}
+
+// changeArchiveState 修改账本归档状态
+func (s *BusinessService) changeArchiveState(ctx context.Context, req *business.LedgerActionRequest, archive bool) (*business.Ledger, error) {
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var ledger Ledger
+	if err := s.db.First(&ledger, "id = ?", req.LedgerId).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", err)
+	}
+	if archive == (ledger.ArchivedAt != nil) {
+		if archive {
+			return nil, status.Errorf(codes.FailedPrecondition, "Ledger is already archived")
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "Ledger is not archived")
+	}
+
+	before := ledger
+	operation := opArchive
+	if archive {
+		now := time.Now()
+		ledger.ArchivedAt = &now
+	} else {
+		// 取消归档后客户端需要重新获取账本的全部数据
+		ledger.ArchivedAt = nil
+		ledger.RestoredAt = time.Now().Unix()
+		operation = opUnarchive
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ledger).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actorFromContext(ctx, req.UserId), operation, &before, &ledger)
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to update ledger: %v", err)
+	}
+
+	return ledgerToProto(ledger), nil
+}
+
+// ListTrash 获取用户作为所有者的回收站账本
+func (s *BusinessService) ListTrash(ctx context.Context, req *business.ListTrashRequest) (*business.ListTrashResponse, error) {
+	owned := s.db.Model(&LedgerMember{}).Select("ledger_id").
+		Where("user_id = ? AND role = ? AND status = ?", req.UserId, roleOwner, memberStatusActive)
+
+	var ledgers []Ledger
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL AND id IN (?)", owned).
+		Order("deleted_at DESC").Find(&ledgers).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query trash: %v", err)
+	}
+
+	responseLedgers := make([]*business.Ledger, 0, len(ledgers))
+	for _, ledger := range ledgers {
+		responseLedger := ledgerToProto(ledger)
+		responseLedger.PurgeAt = ledger.DeletedAt.Time.Add(trashRetention).Format(time.RFC3339)
+		responseLedgers = append(responseLedgers, responseLedger)
+	}
+
+	return &business.ListTrashResponse{Ledgers: responseLedgers}, nil
+}
+
+// RestoreLedger 从回收站恢复账本
+func (s *BusinessService) RestoreLedger(ctx context.Context, req *business.LedgerActionRequest) (*business.Ledger, error) {
+	if _, err := requireTrashedLedgerRole(s.db, req.LedgerId, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var ledger Ledger
+	if err := s.db.Unscoped().First(&ledger, "id = ?", req.LedgerId).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", err)
+	}
+
+	before := ledger
+	ledger.DeletedAt = gorm.DeletedAt{}
+	ledger.RestoredAt = time.Now().Unix()
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Save(&ledger).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actorFromContext(ctx, req.UserId), opRestore, &before, &ledger)
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to restore ledger: %v", err)
+	}
+
+	return ledgerToProto(ledger), nil
+}
+
+// PurgeLedger 立即永久删除回收站中的账本
+func (s *BusinessService) PurgeLedger(ctx context.Context, req *business.LedgerActionRequest) (*common.Response, error) {
+	if _, err := requireTrashedLedgerRole(s.db, req.LedgerId, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var ledger Ledger
+	if err := s.db.Unscoped().First(&ledger, "id = ?", req.LedgerId).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger: %v", err)
+	}
+	// 附件文件已加入待删除队列，由后台任务删除，请求不等待存储服务
+	if err := s.purgeLedger(ledger, actorFromContext(ctx, req.UserId)); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to purge ledger: %v", err)
+	}
+
+	return &common.Response{
+		Success: true,
+		Message: "Ledger purged successfully",
+		Code:    200,
+	}, nil
+}
+
+// purgeLedger 在一个数据库事务中删除账本、交易和附件关联，附件文件加入待删除队列
+// 成员记录标记为已移除而不删除，离线设备下次同步时仍能收到账本删除通知；
+// 变更历史保留，每笔交易追加一条删除记录
+func (s *BusinessService) purgeLedger(ledger Ledger, actor changeActor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		transactionIDs := tx.Model(&Transaction{}).Select("id").Where("ledger_id = ?", ledger.ID)
+		if err := releaseAttachments(tx, transactionIDs); err != nil {
+			return err
+		}
+		if err := recordPurgedTransactions(tx, actor, ledger.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("ledger_id = ?", ledger.ID).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&LedgerMember{}).Where("ledger_id = ?", ledger.ID).
+			Updates(map[string]interface{}{"status": memberStatusRemoved, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&Ledger{}, "id = ?", ledger.ID).Error; err != nil {
+			return err
+		}
+		return recordLedgerChange(tx, actor, opPurge, &ledger, nil)
+	})
+}
+
+// recordPurgedTransactions 为账本中的全部交易批量追加删除记录
+// 交易行和最新版本号都使用加锁读取，与并发修改这些交易的事务串行执行
+func recordPurgedTransactions(tx *gorm.DB, actor changeActor, ledgerID string) error {
+	var batch []Transaction
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ledger_id = ?", ledgerID).FindInBatches(&batch, importBatchSize, func(batchTx *gorm.DB, _ int) error {
+		ids := make([]string, 0, len(batch))
+		for _, transaction := range batch {
+			ids = append(ids, transaction.ID)
+		}
+
+		// 一次查询本批交易的最新版本号
+		var latest []struct {
+			EntityID string
+			Revision int
+		}
+		if err := tx.Model(&ChangeHistory{}).Clauses(clause.Locking{Strength: "UPDATE"}).Select("entity_id, MAX(revision) AS revision").
+			Where("entity_type = ? AND entity_id IN ?", entityTransaction, ids).
+			Group("entity_id").Scan(&latest).Error; err != nil {
+			return err
+		}
+		revisions := make(map[string]int, len(latest))
+		for _, row := range latest {
+			revisions[row.EntityID] = row.Revision
+		}
+
+		changes := make([]*ChangeHistory, 0, len(batch))
+		for i := range batch {
+			change, err := transactionChange(opDelete, &batch[i], nil)
+			if err != nil {
+				return err
+			}
+			change.Revision = revisions[change.EntityID] + 1
+			change.ActorID = actor.userID
+			change.DeviceID = actor.deviceID
+			changes = append(changes, change)
+		}
+		return tx.CreateInBatches(changes, importBatchSize).Error
+	}).Error
+}
+
+// purgeExpiredTrash 永久删除超过保留期的回收站账本
+func (s *BusinessService) purgeExpiredTrash() {
+	var ledgers []Ledger
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-trashRetention)).
+		Find(&ledgers).Error; err != nil {
+		log.Printf("Failed to query expired trash: %v", err)
+		return
+	}
+
+	for _, ledger := range ledgers {
+		if err := s.purgeLedger(ledger, changeActor{userID: systemActorID}); err != nil {
+			log.Printf("Failed to purge ledger %s: %v", ledger.ID, err)
+			continue
+		}
+		log.Printf("Purged expired ledger %s from trash", ledger.ID)
+	}
+}
+
+// RunBackgroundJobs 定期清理过期的回收站账本和未被引用的附件，并删除待删除的存储文件，直到ctx取消
+func (s *BusinessService) RunBackgroundJobs(ctx context.Context) {
+	ticker := time.NewTicker(backgroundJobInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeExpiredTrash()
+		s.collectOrphanAttachments()
+		s.processFileDeletions(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
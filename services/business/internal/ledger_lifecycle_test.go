@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+)
+
+func TestPurgeLedgerRecordsTransactionDeletes(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	updated := testTransaction("tx-1", "ledger-1", "1.00")
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:       "owner",
+		Ledgers:      []*business.Ledger{{Id: "ledger-1", Name: "Trip", Currency: "CNY"}},
+		Transactions: []*business.Transaction{updated, testTransaction("tx-2", "ledger-1", "2.00")},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	updated.Amount = "1.50"
+	if _, err := s.Sync(ctx, &business.SyncRequest{UserId: "owner", Transactions: []*business.Transaction{updated}}); err != nil {
+		t.Fatalf("Sync update: %v", err)
+	}
+
+	if _, err := s.DeleteLedger(ctx, &business.Ledger{Id: "ledger-1", UserId: "owner"}); err != nil {
+		t.Fatalf("DeleteLedger: %v", err)
+	}
+	if _, err := s.PurgeLedger(ctx, &business.LedgerActionRequest{UserId: "owner", LedgerId: "ledger-1"}); err != nil {
+		t.Fatalf("PurgeLedger: %v", err)
+	}
+
+	var remaining int64
+	s.db.Model(&Transaction{}).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("%d transactions remain after purge", remaining)
+	}
+
+	// 每笔交易的删除记录接在已有版本之后，并保存删除前的快照
+	want := map[string]int{"tx-1": 3, "tx-2": 2}
+	var changes []ChangeHistory
+	if err := s.db.Where("entity_type = ? AND operation = ?", entityTransaction, opDelete).Find(&changes).Error; err != nil {
+		t.Fatalf("query change history: %v", err)
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d delete records, want %d", len(changes), len(want))
+	}
+	for _, change := range changes {
+		if change.Revision != want[change.EntityID] || change.ActorID != "owner" || change.LedgerID != "ledger-1" || change.Before == "" || change.After != "" {
+			t.Errorf("delete record for %s = revision %d by %s in %s, want revision %d by owner with a before snapshot",
+				change.EntityID, change.Revision, change.ActorID, change.LedgerID, want[change.EntityID])
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// 账本成员角色
+const (
+	roleOwner  = "owner"
+	roleEditor = "editor"
+	roleViewer = "viewer"
+)
+
+// 账本成员状态
+const (
+	memberStatusPending = "pending"
+	memberStatusActive  = "active"
+	memberStatusRemoved = "removed"
+)
+
+// roleRanks 角色权限等级，数值越大权限越高
+var roleRanks = map[string]int{
+	roleViewer: 1,
+	roleEditor: 2,
+	roleOwner:  3,
+}
+
+// LedgerMember 账本成员模型
+type LedgerMember struct {
+	ID        string    `gorm:"type:varchar(36);primaryKey"`
+	LedgerID  string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_ledger_member"`
+	UserID    string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_ledger_member;index"`
+	Role      string    `gorm:"type:varchar(20);not null"`
+	Status    string    `gorm:"type:varchar(20);not null;index"`
+	InvitedBy string    `gorm:"type:varchar(36)"`
+	JoinedAt  int64     `gorm:"not null;default:0"` // 成为正式成员的时间，同步时据此返回新加入账本的全部数据
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;index"`
+}
+
+// newOwnerMember 创建账本所有者成员记录
+func newOwnerMember(ledgerID, userID string, joinedAt int64) *LedgerMember {
+	return &LedgerMember{
+		ID:       uuid.New().String(),
+		LedgerID: ledgerID,
+		UserID:   userID,
+		Role:     roleOwner,
+		Status:   memberStatusActive,
+		JoinedAt: joinedAt,
+	}
+}
+
+// initLedgerMembers 为引入成员模型之前创建的账本补充所有者记录
+func (s *BusinessService) initLedgerMembers() error {
+	var ledgers []Ledger
+	if err := s.db.Where("id NOT IN (?)", s.db.Model(&LedgerMember{}).Select("ledger_id").Where("role = ?", roleOwner)).
+		Find(&ledgers).Error; err != nil {
+		return err
+	}
+	for _, ledger := range ledgers {
+		if err := s.db.Create(newOwnerMember(ledger.ID, ledger.UserID, ledger.CreatedAt.Unix())).Error; err != nil {
+			return err
+		}
+	}
+
+	// 历史交易的最后修改人即创建者
+	return s.db.Model(&Transaction{}).Where("updated_by IS NULL OR updated_by = ''").
+		UpdateColumn("updated_by", gorm.Expr("user_id")).Error
+}
+
+// requireLedgerRole 校验用户在账本中至少具有指定角色
+// 非成员或账本已在回收站中返回NotFound，避免泄露账本是否存在
+func requireLedgerRole(db *gorm.DB, ledgerID, userID, role string) (*LedgerMember, error) {
+	return checkLedgerRole(db, db.Model(&Ledger{}).Select("id"), ledgerID, userID, role)
+}
+
+// requireTrashedLedgerRole 校验用户在回收站中的账本至少具有指定角色
+func requireTrashedLedgerRole(db *gorm.DB, ledgerID, userID, role string) (*LedgerMember, error) {
+	return checkLedgerRole(db, db.Unscoped().Model(&Ledger{}).Select("id").Where("deleted_at IS NOT NULL"), ledgerID, userID, role)
+}
+
+// checkLedgerRole 在给定的账本范围内校验成员角色
+func checkLedgerRole(db *gorm.DB, ledgers *gorm.DB, ledgerID, userID, role string) (*LedgerMember, error) {
+	var member LedgerMember
+	if err := db.Where("ledger_id IN (?)", ledgers).
+		First(&member, "ledger_id = ? AND user_id = ? AND status = ?", ledgerID, userID, memberStatusActive).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Ledger not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger member: %v", err)
+	}
+	if roleRanks[member.Role] < roleRanks[role] {
+		return nil, status.Errorf(codes.PermissionDenied, "Requires %s role on ledger", role)
+	}
+	return &member, nil
+}
+
+// memberLedgerIDs 返回用户作为正式成员可见的账本ID子查询，不包括回收站中的账本
+func memberLedgerIDs(db *gorm.DB, userID string, includeArchived bool) *gorm.DB {
+	ledgers := db.Model(&Ledger{}).Select("id")
+	if !includeArchived {
+		ledgers = ledgers.Where("archived_at IS NULL")
+	}
+	return db.Model(&LedgerMember{}).Select("ledger_id").
+		Where("user_id = ? AND status = ? AND ledger_id IN (?)", userID, memberStatusActive, ledgers)
+}
+
+// InviteMember 邀请成员加入账本
+func (s *BusinessService) InviteMember(ctx context.Context, req *business.InviteMemberRequest) (*business.LedgerMember, error) {
+	if req.MemberUserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Member user ID is required")
+	}
+	if req.MemberUserId == req.UserId {
+		return nil, status.Errorf(codes.InvalidArgument, "Cannot invite yourself")
+	}
+	if req.Role != roleEditor && req.Role != roleViewer {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid role: %s", req.Role)
+	}
+
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var member LedgerMember
+	result := s.db.First(&member, "ledger_id = ? AND user_id = ?", req.LedgerId, req.MemberUserId)
+	switch {
+	case result.Error == gorm.ErrRecordNotFound:
+		member = LedgerMember{
+			ID:       uuid.New().String(),
+			LedgerID: req.LedgerId,
+			UserID:   req.MemberUserId,
+		}
+	case result.Error != nil:
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger member: %v", result.Error)
+	case member.Status == memberStatusActive:
+		return nil, status.Errorf(codes.AlreadyExists, "User is already a member of the ledger")
+	case member.Status == memberStatusPending:
+		return nil, status.Errorf(codes.AlreadyExists, "User has already been invited")
+	}
+
+	// 新邀请或重新邀请已移除的成员
+	member.Role = req.Role
+	member.Status = memberStatusPending
+	member.InvitedBy = req.UserId
+	member.JoinedAt = 0
+	if err := s.db.Save(&member).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to invite member: %v", err)
+	}
+
+	responseMember, err := s.memberToProto(member)
+	if err != nil {
+		return nil, internalError(err, "Failed to query ledger")
+	}
+	return responseMember, nil
+}
+
+// AcceptInvitation 接受账本邀请
+func (s *BusinessService) AcceptInvitation(ctx context.Context, req *business.AcceptInvitationRequest) (*business.LedgerMember, error) {
+	var member LedgerMember
+	if err := s.db.First(&member, "ledger_id = ? AND user_id = ? AND status = ?", req.LedgerId, req.UserId, memberStatusPending).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Invitation not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query invitation: %v", err)
+	}
+
+	member.Status = memberStatusActive
+	member.JoinedAt = time.Now().Unix()
+	if err := s.db.Save(&member).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to accept invitation: %v", err)
+	}
+
+	responseMember, err := s.memberToProto(member)
+	if err != nil {
+		return nil, internalError(err, "Failed to query ledger")
+	}
+	return responseMember, nil
+}
+
+// RemoveMember 移除账本成员
+func (s *BusinessService) RemoveMember(ctx context.Context, req *business.RemoveMemberRequest) (*common.Response, error) {
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleOwner); err != nil {
+		return nil, err
+	}
+
+	var member LedgerMember
+	if err := s.db.First(&member, "ledger_id = ? AND user_id = ? AND status <> ?", req.LedgerId, req.MemberUserId, memberStatusRemoved).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Member not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger member: %v", err)
+	}
+	if member.Role == roleOwner {
+		return nil, status.Errorf(codes.FailedPrecondition, "Cannot remove the ledger owner")
+	}
+
+	// 保留记录并标记为已移除，成员下次同步时会收到账本删除通知
+	member.Status = memberStatusRemoved
+	if err := s.db.Save(&member).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to remove member: %v", err)
+	}
+
+	return &common.Response{
+		Success: true,
+		Message: "Member removed successfully",
+		Code:    200,
+	}, nil
+}
+
+// LeaveLedger 退出账本或拒绝邀请
+func (s *BusinessService) LeaveLedger(ctx context.Context, req *business.LeaveLedgerRequest) (*common.Response, error) {
+	var member LedgerMember
+	if err := s.db.First(&member, "ledger_id = ? AND user_id = ? AND status <> ?", req.LedgerId, req.UserId, memberStatusRemoved).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Ledger not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger member: %v", err)
+	}
+	if member.Role == roleOwner {
+		return nil, status.Errorf(codes.FailedPrecondition, "Ledger owner cannot leave the ledger")
+	}
+
+	member.Status = memberStatusRemoved
+	if err := s.db.Save(&member).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to leave ledger: %v", err)
+	}
+
+	return &common.Response{
+		Success: true,
+		Message: "Left ledger successfully",
+		Code:    200,
+	}, nil
+}
+
+// ListMembers 获取账本成员列表
+func (s *BusinessService) ListMembers(ctx context.Context, req *business.ListMembersRequest) (*business.ListMembersResponse, error) {
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleViewer); err != nil {
+		return nil, err
+	}
+
+	var members []LedgerMember
+	if err := s.db.Where("ledger_id = ? AND status <> ?", req.LedgerId, memberStatusRemoved).
+		Order("created_at ASC").Find(&members).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query ledger members: %v", err)
+	}
+
+	responseMembers := make([]*business.LedgerMember, 0, len(members))
+	for _, member := range members {
+		responseMember, err := s.memberToProto(member)
+		if err != nil {
+			return nil, internalError(err, "Failed to query ledger")
+		}
+		responseMembers = append(responseMembers, responseMember)
+	}
+
+	return &business.ListMembersResponse{Members: responseMembers}, nil
+}
+
+// ListInvitations 获取用户待接受的邀请
+func (s *BusinessService) ListInvitations(ctx context.Context, req *business.ListInvitationsRequest) (*business.ListInvitationsResponse, error) {
+	var members []LedgerMember
+	if err := s.db.Where("user_id = ? AND status = ?", req.UserId, memberStatusPending).
+		Order("updated_at DESC").Find(&members).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query invitations: %v", err)
+	}
+
+	invitations := make([]*business.LedgerMember, 0, len(members))
+	for _, member := range members {
+		responseMember, err := s.memberToProto(member)
+		if err != nil {
+			return nil, internalError(err, "Failed to query ledger")
+		}
+		invitations = append(invitations, responseMember)
+	}
+
+	return &business.ListInvitationsResponse{Invitations: invitations}, nil
+}
+
+// memberToProto 将成员模型转换为proto消息，附带账本名称
+func (s *BusinessService) memberToProto(member LedgerMember) (*business.LedgerMember, error) {
+	// 回收站中的账本仍返回名称
+	var ledger Ledger
+	if err := s.db.Unscoped().Select("name").Where("id = ?", member.LedgerID).Limit(1).Find(&ledger).Error; err != nil {
+		return nil, err
+	}
+
+	return &business.LedgerMember{
+		LedgerId:   member.LedgerID,
+		UserId:     member.UserID,
+		Role:       member.Role,
+		Status:     member.Status,
+		InvitedBy:  member.InvitedBy,
+		CreatedAt:  member.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  member.UpdatedAt.Format(time.RFC3339),
+		LedgerName: ledger.Name,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireLedgerRole(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:  "owner",
+		Ledgers: []*business.Ledger{{Id: "ledger-1", Name: "Family", Currency: "CNY"}},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	addTestMember(t, s, "ledger-1", "owner", "editor", roleEditor)
+	addTestMember(t, s, "ledger-1", "owner", "viewer", roleViewer)
+	addTestMember(t, s, "ledger-1", "owner", "removed", roleEditor)
+	if _, err := s.RemoveMember(ctx, &business.RemoveMemberRequest{UserId: "owner", LedgerId: "ledger-1", MemberUserId: "removed"}); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if _, err := s.InviteMember(ctx, &business.InviteMemberRequest{UserId: "owner", LedgerId: "ledger-1", MemberUserId: "invited", Role: roleEditor}); err != nil {
+		t.Fatalf("InviteMember: %v", err)
+	}
+
+	tests := []struct {
+		userID string
+		role   string
+		want   codes.Code
+	}{
+		{"owner", roleOwner, codes.OK},
+		{"owner", roleViewer, codes.OK},
+		{"editor", roleOwner, codes.PermissionDenied},
+		{"editor", roleEditor, codes.OK},
+		{"editor", roleViewer, codes.OK},
+		{"viewer", roleEditor, codes.PermissionDenied},
+		{"viewer", roleViewer, codes.OK},
+		// 已移除的成员、未接受邀请的用户和非成员都看不到账本
+		{"removed", roleViewer, codes.NotFound},
+		{"invited", roleViewer, codes.NotFound},
+		{"stranger", roleViewer, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID+"/"+tt.role, func(t *testing.T) {
+			member, err := requireLedgerRole(s.db, "ledger-1", tt.userID, tt.role)
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("requireLedgerRole code = %v, want %v (%v)", code, tt.want, err)
+			}
+			if err == nil && member.UserID != tt.userID {
+				t.Errorf("requireLedgerRole returned member %s", member.UserID)
+			}
+		})
+	}
+
+	// 回收站中的账本对所有成员都不可见
+	if _, err := s.DeleteLedger(ctx, &business.Ledger{Id: "ledger-1", UserId: "owner"}); err != nil {
+		t.Fatalf("DeleteLedger: %v", err)
+	}
+	if _, err := requireLedgerRole(s.db, "ledger-1", "owner", roleViewer); status.Code(err) != codes.NotFound {
+		t.Errorf("requireLedgerRole on trashed ledger = %v, want NotFound", err)
+	}
+	if _, err := requireTrashedLedgerRole(s.db, "ledger-1", "owner", roleOwner); err != nil {
+		t.Errorf("requireTrashedLedgerRole: %v", err)
+	}
+}
+
+func TestMemberToProtoQueryError(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:  "owner",
+		Ledgers: []*business.Ledger{{Id: "ledger-1", Name: "Family", Currency: "CNY"}},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := s.InviteMember(ctx, &business.InviteMemberRequest{UserId: "owner", LedgerId: "ledger-1", MemberUserId: "invited", Role: roleEditor}); err != nil {
+		t.Fatalf("InviteMember: %v", err)
+	}
+
+	// 账本在回收站中时邀请仍带有账本名称
+	if _, err := s.DeleteLedger(ctx, &business.Ledger{Id: "ledger-1", UserId: "owner"}); err != nil {
+		t.Fatalf("DeleteLedger: %v", err)
+	}
+	resp, err := s.ListInvitations(ctx, &business.ListInvitationsRequest{UserId: "invited"})
+	if err != nil {
+		t.Fatalf("ListInvitations: %v", err)
+	}
+	if len(resp.Invitations) != 1 || resp.Invitations[0].LedgerName != "Family" {
+		t.Errorf("invitations = %v, want one for Family", resp.Invitations)
+	}
+
+	// 查询账本失败时返回错误，而不是名称为空的成员
+	if err := s.db.Migrator().DropTable(&Ledger{}); err != nil {
+		t.Fatalf("DropTable: %v", err)
+	}
+	if _, err := s.ListInvitations(ctx, &business.ListInvitationsRequest{UserId: "invited"}); status.Code(err) != codes.Internal {
+		t.Errorf("ListInvitations = %v, want Internal", err)
+	}
+}
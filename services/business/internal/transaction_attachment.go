@@ -0,0 +1,378 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// fileDeletionBatchSize 每次处理的待删除文件数量
+	fileDeletionBatchSize = 100
+	// orphanAttachmentGracePeriod 登记后未关联交易的附件保留时长，给先上传后关联的客户端留出时间
+	orphanAttachmentGracePeriod = 24 * time.Hour
+)
+
+// Attachment 附件文件模型，登记上传到存储服务的附件
+type Attachment struct {
+	FileID      string    `gorm:"type:varchar(36);primaryKey"`
+	UserID      string    `gorm:"type:varchar(36);not null;index"` // 上传者，即存储文件的所有者
+	Filename    string    `gorm:"type:varchar(255)"`
+	ContentType string    `gorm:"type:varchar(100)"`
+	Size        int64     `gorm:"not null;default:0"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TransactionAttachment 交易附件关联模型
+type TransactionAttachment struct {
+	TransactionID string    `gorm:"type:varchar(36);primaryKey"`
+	FileID        string    `gorm:"type:varchar(36);primaryKey;index"`
+	UserID        string    `gorm:"type:varchar(36);not null"` // 文件所有者，删除存储文件时使用
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+// PendingFileDeletion 待删除的存储文件
+// 在删除业务数据的同一数据库事务中写入，再由后台任务调用存储服务删除，
+// 保证业务数据与存储文件最终一致
+type PendingFileDeletion struct {
+	FileID    string    `gorm:"type:varchar(36);primaryKey"`
+	UserID    string    `gorm:"type:varchar(36);not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// ConfigureStorageClient 配置存储服务客户端
+func (s *BusinessService) ConfigureStorageClient(addr string) error {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	s.storageClient = storage.NewStorageServiceClient(conn)
+	return nil
+}
+
+// releaseAttachments 移除交易的附件关联，不再被任何交易引用的文件加入待删除队列，需在数据库事务中调用
+func releaseAttachments(tx *gorm.DB, transactionIDs interface{}) error {
+	var fileIDs []string
+	if err := tx.Model(&TransactionAttachment{}).Distinct("file_id").
+		Where("transaction_id IN (?)", transactionIDs).Pluck("file_id", &fileIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("transaction_id IN (?)", transactionIDs).Delete(&TransactionAttachment{}).Error; err != nil {
+		return err
+	}
+	return queueUnreferencedFiles(tx, fileIDs)
+}
+
+// queueUnreferencedFiles 将未被任何交易引用的附件加入待删除队列并删除登记记录，需在数据库事务中调用
+func queueUnreferencedFiles(tx *gorm.DB, fileIDs []string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	var attachments []Attachment
+	if err := tx.Where("file_id IN ? AND file_id NOT IN (?)", fileIDs, tx.Model(&TransactionAttachment{}).Select("file_id")).
+		Find(&attachments).Error; err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		deletion := PendingFileDeletion{FileID: attachment.FileID, UserID: attachment.UserID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deletion).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchTransactions 更新交易的同步时间，使附件变更在下次同步时下发到其他设备
+func touchTransactions(tx *gorm.DB, actor changeActor, transactionIDs []string) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&Transaction{}).Where("id IN ?", transactionIDs).
+		Updates(map[string]interface{}{"sync_time": time.Now().Unix(), "updated_by": actor.userID}).Error
+}
+
+// AttachFile 登记附件文件，指定交易时同时建立关联
+func (s *BusinessService) AttachFile(ctx context.Context, req *business.AttachFileRequest) (*business.Attachment, error) {
+	if req.FileId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "File ID is required")
+	}
+	actor := batchActor(ctx, req.UserId, req.DeviceId)
+
+	var attachment Attachment
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&attachment, "file_id = ?", req.FileId).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			attachment = Attachment{
+				FileID:      req.FileId,
+				UserID:      req.UserId,
+				Filename:    req.Filename,
+				ContentType: req.ContentType,
+				Size:        req.Size,
+			}
+			if err := tx.Create(&attachment).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case attachment.UserID != req.UserId:
+			// 只能关联自己上传的文件
+			return status.Errorf(codes.NotFound, "Attachment not found")
+		}
+
+		if req.TransactionId == "" {
+			return nil
+		}
+		if _, err := requireTransactionRole(tx, req.TransactionId, req.UserId, roleEditor); err != nil {
+			return err
+		}
+		link := TransactionAttachment{TransactionID: req.TransactionId, FileID: attachment.FileID, UserID: attachment.UserID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return err
+		}
+		return touchTransactions(tx, actor, []string{req.TransactionId})
+	}); err != nil {
+		return nil, internalError(err, "Failed to attach file")
+	}
+
+	return s.attachmentToProto(attachment)
+}
+
+// DetachFile 取消交易与附件的关联
+func (s *BusinessService) DetachFile(ctx context.Context, req *business.DetachFileRequest) (*common.Response, error) {
+	actor := batchActor(ctx, req.UserId, req.DeviceId)
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := requireTransactionRole(tx, req.TransactionId, req.UserId, roleEditor); err != nil {
+			return err
+		}
+		result := tx.Delete(&TransactionAttachment{}, "transaction_id = ? AND file_id = ?", req.TransactionId, req.FileId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return status.Errorf(codes.NotFound, "Attachment not found")
+		}
+		if err := touchTransactions(tx, actor, []string{req.TransactionId}); err != nil {
+			return err
+		}
+		return queueUnreferencedFiles(tx, []string{req.FileId})
+	}); err != nil {
+		return nil, internalError(err, "Failed to detach file")
+	}
+
+	// 尽快清理存储文件，失败的文件由后台任务重试
+	s.processFileDeletions(ctx)
+
+	return &common.Response{
+		Success: true,
+		Message: "Attachment detached successfully",
+		Code:    200,
+	}, nil
+}
+
+// GetAttachment 获取附件信息
+// 上传者或能够查看引用该文件的交易的账本成员可以访问，其他用户返回NotFound
+func (s *BusinessService) GetAttachment(ctx context.Context, req *business.AttachmentRequest) (*business.Attachment, error) {
+	var attachment Attachment
+	if err := s.db.First(&attachment, "file_id = ?", req.FileId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Attachment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query attachment: %v", err)
+	}
+
+	if attachment.UserID != req.UserId {
+		visible := s.db.Model(&Transaction{}).Select("id").Where("ledger_id IN (?)", memberLedgerIDs(s.db, req.UserId, true))
+		var count int64
+		if err := s.db.Model(&TransactionAttachment{}).
+			Where("file_id = ? AND transaction_id IN (?)", req.FileId, visible).Count(&count).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to query attachment: %v", err)
+		}
+		if count == 0 {
+			return nil, status.Errorf(codes.NotFound, "Attachment not found")
+		}
+	}
+
+	return s.attachmentToProto(attachment)
+}
+
+// DeleteAttachment 删除附件文件及其全部关联
+func (s *BusinessService) DeleteAttachment(ctx context.Context, req *business.AttachmentRequest) (*common.Response, error) {
+	actor := actorFromContext(ctx, req.UserId)
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var attachment Attachment
+		if err := tx.First(&attachment, "file_id = ? AND user_id = ?", req.FileId, req.UserId).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return status.Errorf(codes.NotFound, "Attachment not found")
+			}
+			return err
+		}
+
+		var transactionIDs []string
+		if err := tx.Model(&TransactionAttachment{}).Where("file_id = ?", req.FileId).
+			Pluck("transaction_id", &transactionIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&TransactionAttachment{}, "file_id = ?", req.FileId).Error; err != nil {
+			return err
+		}
+		if err := touchTransactions(tx, actor, transactionIDs); err != nil {
+			return err
+		}
+		return queueUnreferencedFiles(tx, []string{req.FileId})
+	}); err != nil {
+		return nil, internalError(err, "Failed to delete attachment")
+	}
+
+	s.processFileDeletions(ctx)
+
+	return &common.Response{
+		Success: true,
+		Message: "Attachment deleted successfully",
+		Code:    200,
+	}, nil
+}
+
+// requireTransactionRole 校验用户在交易所属账本中至少具有指定角色
+func requireTransactionRole(db *gorm.DB, transactionID, userID, role string) (*Transaction, error) {
+	var transaction Transaction
+	if err := db.First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Transaction not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query transaction: %v", err)
+	}
+	if _, err := requireLedgerRole(db, transaction.LedgerID, userID, role); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// loadAttachments 批量查询交易的附件并填入proto消息
+func loadAttachments(db *gorm.DB, transactions []*business.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	byID := make(map[string]*business.Transaction, len(transactions))
+	transactionIDs := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		byID[transaction.Id] = transaction
+		transactionIDs = append(transactionIDs, transaction.Id)
+	}
+
+	var rows []struct {
+		TransactionID string
+		Attachment
+	}
+	if err := db.Model(&TransactionAttachment{}).
+		Select("transaction_attachments.transaction_id, attachments.*").
+		Joins("JOIN attachments ON attachments.file_id = transaction_attachments.file_id").
+		Where("transaction_attachments.transaction_id IN ?", transactionIDs).
+		Order("transaction_attachments.created_at ASC").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		transaction := byID[row.TransactionID]
+		transaction.Attachments = append(transaction.Attachments, &business.Attachment{
+			FileId:         row.FileID,
+			UserId:         row.UserID,
+			Filename:       row.Filename,
+			ContentType:    row.ContentType,
+			Size:           row.Size,
+			CreatedAt:      row.CreatedAt.Format(time.RFC3339),
+			TransactionIds: []string{row.TransactionID},
+		})
+	}
+	return nil
+}
+
+// attachmentToProto 将附件模型转换为proto消息，附带引用该文件的交易
+func (s *BusinessService) attachmentToProto(attachment Attachment) (*business.Attachment, error) {
+	transactionIDs := []string{}
+	if err := s.db.Model(&TransactionAttachment{}).Where("file_id = ?", attachment.FileID).
+		Order("created_at ASC").Pluck("transaction_id", &transactionIDs).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query attachment: %v", err)
+	}
+
+	return &business.Attachment{
+		FileId:         attachment.FileID,
+		UserId:         attachment.UserID,
+		Filename:       attachment.Filename,
+		ContentType:    attachment.ContentType,
+		Size:           attachment.Size,
+		CreatedAt:      attachment.CreatedAt.Format(time.RFC3339),
+		TransactionIds: transactionIDs,
+	}, nil
+}
+
+// collectOrphanAttachments 删除超过保留期仍未被任何交易引用的附件
+func (s *BusinessService) collectOrphanAttachments() {
+	var fileIDs []string
+	if err := s.db.Model(&Attachment{}).
+		Where("created_at < ? AND file_id NOT IN (?)", time.Now().Add(-orphanAttachmentGracePeriod), s.db.Model(&TransactionAttachment{}).Select("file_id")).
+		Limit(fileDeletionBatchSize).Pluck("file_id", &fileIDs).Error; err != nil {
+		log.Printf("Failed to query orphan attachments: %v", err)
+		return
+	}
+	if len(fileIDs) == 0 {
+		return
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return queueUnreferencedFiles(tx, fileIDs)
+	}); err != nil {
+		log.Printf("Failed to collect orphan attachments: %v", err)
+		return
+	}
+	log.Printf("Queued %d orphan attachments for deletion", len(fileIDs))
+}
+
+// processFileDeletions 调用存储服务删除队列中的文件，失败的文件保留到下次重试
+func (s *BusinessService) processFileDeletions(ctx context.Context) {
+	if s.storageClient == nil {
+		return
+	}
+
+	var deletions []PendingFileDeletion
+	if err := s.db.Order("updated_at ASC").Limit(fileDeletionBatchSize).Find(&deletions).Error; err != nil {
+		log.Printf("Failed to query pending file deletions: %v", err)
+		return
+	}
+
+	for _, deletion := range deletions {
+		_, err := s.storageClient.DeleteFile(ctx, &storage.DeleteFileRequest{
+			FileId: deletion.FileID,
+			UserId: deletion.UserID,
+		})
+		if err == nil || status.Code(err) == codes.NotFound {
+			if err := s.db.Delete(&deletion).Error; err != nil {
+				log.Printf("Failed to remove pending file deletion %s: %v", deletion.FileID, err)
+			}
+			continue
+		}
+
+		deletion.Attempts++
+		deletion.LastError = err.Error()
+		if err := s.db.Save(&deletion).Error; err != nil {
+			log.Printf("Failed to update pending file deletion %s: %v", deletion.FileID, err)
+		}
+	}
+}
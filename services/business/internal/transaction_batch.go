@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// maxBatchSize 单次批量操作允许的最大记录数
+const maxBatchSize = 500
+
+// errBatchFailed 批量操作中存在失败记录，用于回滚整个数据库事务
+var errBatchFailed = errors.New("batch contains failed items")
+
+// BatchCreateTransactions 批量创建交易
+func (s *BusinessService) BatchCreateTransactions(ctx context.Context, req *business.BatchCreateTransactionsRequest) (*business.BatchTransactionsResponse, error) {
+	actor := batchActor(ctx, req.UserId, req.DeviceId)
+	return s.runBatch(len(req.Transactions), func(tx *gorm.DB, index int) (string, *Transaction, error) {
+		item := req.Transactions[index]
+		if item == nil {
+			return "", nil, status.Errorf(codes.InvalidArgument, "Transaction at index %d is required", index)
+		}
+		transaction, err := createTransaction(tx, actor, item)
+		if err != nil {
+			return item.Id, nil, err
+		}
+		return transaction.ID, transaction, nil
+	})
+}
+
+// BatchUpdateTransactions 批量更新交易
+func (s *BusinessService) BatchUpdateTransactions(ctx context.Context, req *business.BatchUpdateTransactionsRequest) (*business.BatchTransactionsResponse, error) {
+	actor := batchActor(ctx, req.UserId, req.DeviceId)
+	return s.runBatch(len(req.Updates), func(tx *gorm.DB, index int) (string, *Transaction, error) {
+		update := req.Updates[index]
+		if update.Transaction == nil || update.Transaction.Id == "" {
+			return "", nil, status.Errorf(codes.InvalidArgument, "Transaction ID is required")
+		}
+		paths := update.UpdateMask.GetPaths()
+		if len(paths) == 0 {
+			paths = update.Transaction.UpdateMask.GetPaths()
+		}
+		transaction, err := updateTransaction(tx, actor, update.Transaction, paths)
+		return update.Transaction.Id, transaction, err
+	})
+}
+
+// BatchDeleteTransactions 批量删除交易
+func (s *BusinessService) BatchDeleteTransactions(ctx context.Context, req *business.BatchDeleteTransactionsRequest) (*business.BatchTransactionsResponse, error) {
+	actor := batchActor(ctx, req.UserId, req.DeviceId)
+	response, err := s.runBatch(len(req.TransactionIds), func(tx *gorm.DB, index int) (string, *Transaction, error) {
+		transactionID := req.TransactionIds[index]
+		return transactionID, nil, deleteTransaction(tx, actor, transactionID)
+	})
+	if err == nil && response.Committed {
+		s.processFileDeletions(ctx)
+	}
+	return response, err
+}
+
+// batchActor 构造批量操作的执行者，请求中的设备ID优先于元数据中的设备ID
+func batchActor(ctx context.Context, userID, deviceID string) changeActor {
+	actor := actorFromContext(ctx, userID)
+	if deviceID != "" {
+		actor.deviceID = deviceID
+	}
+	return actor
+}
+
+// runBatch 在一个数据库事务中依次执行count条操作并收集每条记录的结果
+// 每条操作在独立的保存点中执行，失败不会影响后续记录的校验；任一记录失败时回滚整个事务
+func (s *BusinessService) runBatch(count int, apply func(tx *gorm.DB, index int) (string, *Transaction, error)) (*business.BatchTransactionsResponse, error) {
+	if count == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Batch is empty")
+	}
+	if count > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Batch size %d exceeds the limit of %d", count, maxBatchSize)
+	}
+
+	response := &business.BatchTransactionsResponse{
+		Results: make([]*business.BatchTransactionResult, 0, count),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for index := 0; index < count; index++ {
+			var (
+				id          string
+				transaction *Transaction
+			)
+			err := tx.Transaction(func(itemTx *gorm.DB) error {
+				var err error
+				id, transaction, err = apply(itemTx, index)
+				return err
+			})
+
+			result := &business.BatchTransactionResult{Index: int32(index), Id: id}
+			if err != nil {
+				err = internalError(err, "Failed to apply batch item")
+				result.Code = int32(status.Code(err))
+				result.Error = status.Convert(err).Message()
+				response.Failed++
+			} else {
+				result.Success = true
+				if transaction != nil {
+					result.Transaction = transactionToProto(*transaction)
+				}
+				response.Succeeded++
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		if response.Failed > 0 {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if err != nil && err != errBatchFailed {
+		return nil, status.Errorf(codes.Internal, "Failed to commit batch: %v", err)
+	}
+
+	// 事务已回滚时成功的记录也未生效，不返回其数据
+	response.Committed = err == nil
+	var transactions []*business.Transaction
+	for _, result := range response.Results {
+		if !response.Committed {
+			result.Transaction = nil
+		} else if result.Transaction != nil {
+			transactions = append(transactions, result.Transaction)
+		}
+	}
+	if err := loadAttachments(s.db, transactions); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query attachments: %v", err)
+	}
+	return response, nil
+}
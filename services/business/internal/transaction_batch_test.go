@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunBatchRollback(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:  "owner",
+		Ledgers: []*business.Ledger{{Id: "ledger-1", Name: "Daily", Currency: "CNY"}},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	addTestMember(t, s, "ledger-1", "owner", "viewer", roleViewer)
+
+	invalid := testTransaction("tx-bad", "ledger-1", "-5")
+	tests := []struct {
+		name          string
+		userID        string
+		transactions  []*business.Transaction
+		wantCommitted bool
+		wantCodes     []codes.Code
+		wantSaved     int64
+	}{
+		{
+			name:          "invalid item rolls back the batch",
+			userID:        "owner",
+			transactions:  []*business.Transaction{testTransaction("tx-1", "ledger-1", "1.00"), invalid, testTransaction("tx-2", "ledger-1", "2.00")},
+			wantCommitted: false,
+			wantCodes:     []codes.Code{codes.OK, codes.InvalidArgument, codes.OK},
+		},
+		{
+			name:          "duplicate ID within the batch",
+			userID:        "owner",
+			transactions:  []*business.Transaction{testTransaction("tx-1", "ledger-1", "1.00"), testTransaction("tx-1", "ledger-1", "1.00")},
+			wantCommitted: false,
+			wantCodes:     []codes.Code{codes.OK, codes.AlreadyExists},
+		},
+		{
+			name:          "nil item is rejected",
+			userID:        "owner",
+			transactions:  []*business.Transaction{testTransaction("tx-1", "ledger-1", "1.00"), nil},
+			wantCommitted: false,
+			wantCodes:     []codes.Code{codes.OK, codes.InvalidArgument},
+		},
+		{
+			name:          "viewer cannot create",
+			userID:        "viewer",
+			transactions:  []*business.Transaction{testTransaction("tx-1", "ledger-1", "1.00")},
+			wantCommitted: false,
+			wantCodes:     []codes.Code{codes.PermissionDenied},
+		},
+		{
+			name:          "valid batch commits",
+			userID:        "owner",
+			transactions:  []*business.Transaction{testTransaction("tx-1", "ledger-1", "1.00"), testTransaction("tx-2", "ledger-1", "2.00")},
+			wantCommitted: true,
+			wantCodes:     []codes.Code{codes.OK, codes.OK},
+			wantSaved:     2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.BatchCreateTransactions(ctx, &business.BatchCreateTransactionsRequest{UserId: tt.userID, Transactions: tt.transactions})
+			if err != nil {
+				t.Fatalf("BatchCreateTransactions: %v", err)
+			}
+			if resp.Committed != tt.wantCommitted {
+				t.Errorf("Committed = %v, want %v", resp.Committed, tt.wantCommitted)
+			}
+			if len(resp.Results) != len(tt.wantCodes) {
+				t.Fatalf("got %d results, want %d", len(resp.Results), len(tt.wantCodes))
+			}
+			for i, result := range resp.Results {
+				if codes.Code(result.Code) != tt.wantCodes[i] || result.Success != (tt.wantCodes[i] == codes.OK) {
+					t.Errorf("result %d = code %v success %v, want %v", i, codes.Code(result.Code), result.Success, tt.wantCodes[i])
+				}
+				// 回滚后成功的记录也不返回数据
+				if (result.Transaction != nil) != (resp.Committed && result.Success) {
+					t.Errorf("result %d transaction returned = %v with committed %v", i, result.Transaction != nil, resp.Committed)
+				}
+			}
+
+			var saved int64
+			s.db.Model(&Transaction{}).Count(&saved)
+			if saved != tt.wantSaved {
+				t.Errorf("saved %d transactions, want %d", saved, tt.wantSaved)
+			}
+		})
+	}
+
+	for _, count := range []int{0, maxBatchSize + 1} {
+		items := make([]*business.Transaction, count)
+		_, err := s.BatchCreateTransactions(ctx, &business.BatchCreateTransactionsRequest{UserId: "owner", Transactions: items})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("batch of %d = %v, want InvalidArgument", count, err)
+		}
+	}
+}
@@ -0,0 +1,670 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// 导入行状态
+const (
+	importStatusOK        = "ok"
+	importStatusDuplicate = "duplicate"
+	importStatusError     = "error"
+)
+
+const (
+	// importBatchSize 批量插入的行数
+	importBatchSize = 200
+	// maxImportRows 单次导入允许的最大行数
+	maxImportRows = 50000
+)
+
+// importDateLayouts 自动检测日期格式时的候选格式，按优先级排列
+var importDateLayouts = []string{
+	"2006-1-2",
+	"2006/1/2",
+	"2006.1.2",
+	"20060102",
+	"2006年1月2日",
+	"2006-1-2 15:04:05",
+	"2006/1/2 15:04:05",
+	"2006-1-2 15:04",
+	"2006/1/2 15:04",
+	time.RFC3339,
+	"1/2/2006",
+	"2/1/2006",
+	"2.1.2006",
+	"1-2-2006",
+	"2-1-2006",
+	"1/2/2006 15:04",
+	"2/1/2006 15:04",
+}
+
+// importTypeAliases 交易类型别名
+var importTypeAliases = map[string]string{
+	"income":   "income",
+	"in":       "income",
+	"credit":   "income",
+	"收入":       "income",
+	"expense":  "expense",
+	"out":      "expense",
+	"debit":    "expense",
+	"支出":       "expense",
+	"transfer": "transfer",
+	"转账":       "transfer",
+}
+
+// importColumns 解析后的列序号（从0开始，-1表示未映射）
+type importColumns struct {
+	date          int
+	amount        int
+	txType        int
+	category      int
+	subcategory   int
+	account       int
+	targetAccount int
+	description   int
+	tags          int
+}
+
+// importRow 源文件中的一行
+type importRow struct {
+	line   int
+	fields []string
+}
+
+// ImportTransactions 导入交易（CSV/XLSX）
+func (s *BusinessService) ImportTransactions(ctx context.Context, req *business.ImportTransactionsRequest) (*business.ImportTransactionsResponse, error) {
+	if req.LedgerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Ledger ID is required")
+	}
+	if len(req.Data) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Import data is empty")
+	}
+
+	// 校验账本编辑权限
+	if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleEditor); err != nil {
+		return nil, err
+	}
+
+	// 读取源文件
+	var records [][]string
+	var err error
+	format := strings.ToLower(req.Format)
+	switch format {
+	case "csv":
+		records, err = readImportCSV(req.Data)
+	case "xlsx":
+		records, err = readImportXLSX(req.Data, req.Sheet)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Unsupported import format: %s", req.Format)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Failed to read import file: %v", err)
+	}
+
+	// 解析表头和列映射
+	var header []string
+	firstLine := 1
+	if !req.NoHeader && len(records) > 0 {
+		header = records[0]
+		records = records[1:]
+		firstLine = 2
+	}
+	columns, err := resolveImportColumns(req.Mapping, header)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	// 过滤空行
+	var rows []importRow
+	for i, record := range records {
+		if isBlankRecord(record) {
+			continue
+		}
+		rows = append(rows, importRow{line: firstLine + i, fields: record})
+	}
+	if len(rows) > maxImportRows {
+		return nil, status.Errorf(codes.InvalidArgument, "Too many rows: %d (max %d)", len(rows), maxImportRows)
+	}
+
+	// xlsx中的日期可能是序列号，先转换为标准日期
+	if format == "xlsx" {
+		for _, row := range rows {
+			value := cellAt(row.fields, columns.date)
+			if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial < 2958466 {
+				if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+					row.fields[columns.date] = t.Format("2006-01-02")
+				}
+			}
+		}
+	}
+
+	// 检测日期和金额格式
+	var dateValues, amountValues []string
+	for _, row := range rows {
+		dateValues = append(dateValues, cellAt(row.fields, columns.date))
+		amountValues = append(amountValues, cellAt(row.fields, columns.amount))
+	}
+	dateLayout := req.DateFormat
+	if dateLayout == "" {
+		dateLayout = detectDateLayout(dateValues)
+	}
+	decimalSep := detectDecimalSeparator(amountValues)
+
+	// 逐行转换为交易
+	results := make([]*business.ImportRowResult, 0, len(rows))
+	candidates := make([]*Transaction, len(rows))
+	for i, row := range rows {
+		transaction, err := s.buildImportTransaction(req, columns, row, dateLayout, decimalSep)
+		result := &business.ImportRowResult{Row: int32(row.line)}
+		if err != nil {
+			result.Status = importStatusError
+			result.Error = err.Error()
+		} else {
+			result.Status = importStatusOK
+			candidates[i] = transaction
+			result.Transaction = transactionToProto(*transaction)
+			result.Transaction.CreatedAt = ""
+			result.Transaction.UpdatedAt = ""
+		}
+		results = append(results, result)
+	}
+
+	// 重复检测：日期、金额和描述均相同视为重复
+	seen, err := s.existingImportKeys(req.LedgerId, candidates)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query existing transactions: %v", err)
+	}
+
+	resp := &business.ImportTransactionsResponse{
+		DryRun:           req.DryRun,
+		TotalRows:        int32(len(rows)),
+		DateFormat:       dateLayout,
+		DecimalSeparator: string(decimalSep),
+		Rows:             results,
+	}
+
+	var toInsert []*Transaction
+	for i, transaction := range candidates {
+		if transaction == nil {
+			resp.Failed++
+			continue
+		}
+		key := importDedupKey(transaction.Date, transaction.Amount, transaction.Description)
+		if seen[key] {
+			results[i].Status = importStatusDuplicate
+			resp.Duplicates++
+			continue
+		}
+		seen[key] = true
+		toInsert = append(toInsert, transaction)
+	}
+	resp.Imported = int32(len(toInsert))
+
+	if req.DryRun || len(toInsert) == 0 {
+		return resp, nil
+	}
+
+	// 在同一个数据库事务中批量写入
+	syncTime := time.Now().Unix()
+	for _, transaction := range toInsert {
+		transaction.SyncTime = syncTime
+	}
+	actor := changeActor{userID: req.UserId, deviceID: req.DeviceId}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(toInsert, importBatchSize).Error; err != nil {
+			return err
+		}
+
+		// 导入的交易都是新记录，直接批量写入第一个版本
+		changes := make([]*ChangeHistory, 0, len(toInsert))
+		for _, transaction := range toInsert {
+			change, err := transactionChange(opCreate, nil, transaction)
+			if err != nil {
+				return err
+			}
+			change.Revision = 1
+			change.ActorID = actor.userID
+			change.DeviceID = actor.deviceID
+			changes = append(changes, change)
+		}
+		return tx.CreateInBatches(changes, importBatchSize).Error
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to import transactions: %v", err)
+	}
+
+	return resp, nil
+}
+
+// buildImportTransaction 将一行数据转换为交易
+func (s *BusinessService) buildImportTransaction(req *business.ImportTransactionsRequest, columns importColumns, row importRow, dateLayout string, decimalSep byte) (*Transaction, error) {
+	// 日期
+	dateValue := cellAt(row.fields, columns.date)
+	if dateValue == "" {
+		return nil, fmt.Errorf("date is empty")
+	}
+	if dateLayout == "" {
+		return nil, fmt.Errorf("unrecognized date %q", dateValue)
+	}
+	date, err := time.Parse(dateLayout, dateValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q for format %q", dateValue, dateLayout)
+	}
+
+	// 金额
+	amountValue := cellAt(row.fields, columns.amount)
+	amount, negative, err := parseImportAmount(amountValue, decimalSep)
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型
+	txType := req.DefaultType
+	if value := cellAt(row.fields, columns.txType); value != "" {
+		mapped, ok := importTypeAliases[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("unknown transaction type %q", value)
+		}
+		txType = mapped
+	}
+	if txType == "" {
+		txType = "income"
+		if negative {
+			txType = "expense"
+		}
+	}
+
+	// 分类
+	categoryID, err := mapImportName(cellAt(row.fields, columns.category), req.CategoryMapping, "category")
+	if err != nil {
+		return nil, err
+	}
+	subcategoryID, err := mapImportName(cellAt(row.fields, columns.subcategory), req.CategoryMapping, "subcategory")
+	if err != nil {
+		return nil, err
+	}
+
+	// 账户
+	accountID, err := mapImportName(cellAt(row.fields, columns.account), req.AccountMapping, "account")
+	if err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		accountID = req.DefaultAccountId
+	}
+	if accountID == "" {
+		return nil, fmt.Errorf("account is required")
+	}
+	targetAccountID, err := mapImportName(cellAt(row.fields, columns.targetAccount), req.AccountMapping, "account")
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &Transaction{
+		ID:              uuid.New().String(),
+		LedgerID:        req.LedgerId,
+		UserID:          req.UserId,
+		Type:            txType,
+		CategoryID:      categoryID,
+		SubcategoryID:   subcategoryID,
+		AccountID:       accountID,
+		TargetAccountID: targetAccountID,
+		Amount:          amount,
+		Description:     cellAt(row.fields, columns.description),
+		Date:            date.Format("2006-01-02"),
+		Tags:            parseImportTags(cellAt(row.fields, columns.tags)),
+		DeviceID:        req.DeviceId,
+		UpdatedBy:       req.UserId,
+	}
+
+	// 与单条和批量创建使用相同的字段校验
+	if err := validateFields(transaction, transactionRules, nil); err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return transaction, nil
+}
+
+// existingImportKeys 查询账本中已存在交易的去重键
+func (s *BusinessService) existingImportKeys(ledgerID string, candidates []*Transaction) (map[string]bool, error) {
+	keys := make(map[string]bool)
+
+	dateSet := make(map[string]bool)
+	var dates []string
+	for _, transaction := range candidates {
+		if transaction != nil && !dateSet[transaction.Date] {
+			dateSet[transaction.Date] = true
+			dates = append(dates, transaction.Date)
+		}
+	}
+	if len(dates) == 0 {
+		return keys, nil
+	}
+
+	var existing []Transaction
+	if err := s.db.Select("date", "amount", "description").
+		Where("ledger_id = ? AND date IN ?", ledgerID, dates).
+		Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range existing {
+		keys[importDedupKey(transaction.Date, transaction.Amount, transaction.Description)] = true
+	}
+	return keys, nil
+}
+
+// importDedupKey 生成去重键
+func importDedupKey(date, amount, description string) string {
+	if normalized, err := normalizeAmount(amount); err == nil {
+		amount = normalized
+	}
+	return date + "|" + amount + "|" + strings.TrimSpace(description)
+}
+
+// readImportCSV 读取CSV文件，自动识别编码和分隔符
+func readImportCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	// 国内银行和记账软件导出的CSV常用GB18030编码
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectCSVDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// detectCSVDelimiter 根据首行内容检测分隔符
+func detectCSVDelimiter(data []byte) rune {
+	firstLine := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = data[:idx]
+	}
+
+	delimiter := ','
+	best := bytes.Count(firstLine, []byte{','})
+	for _, candidate := range []rune{';', '\t', '|'} {
+		if count := bytes.Count(firstLine, []byte(string(candidate))); count > best {
+			delimiter = candidate
+			best = count
+		}
+	}
+	return delimiter
+}
+
+// readImportXLSX 读取XLSX文件中的工作表
+func readImportXLSX(data []byte, sheet string) ([][]string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+
+	// 读取原始值，避免日期和金额被单元格格式改写
+	return file.GetRows(sheet, excelize.Options{RawCellValue: true})
+}
+
+// resolveImportColumns 将列映射解析为列序号
+func resolveImportColumns(mapping *business.ImportColumnMapping, header []string) (importColumns, error) {
+	if mapping == nil {
+		mapping = &business.ImportColumnMapping{}
+	}
+
+	var err error
+	resolve := func(name, ref string) int {
+		if err != nil {
+			return -1
+		}
+		var idx int
+		idx, err = resolveImportColumn(name, ref, header)
+		return idx
+	}
+
+	columns := importColumns{
+		date:          resolve("date", mapping.Date),
+		amount:        resolve("amount", mapping.Amount),
+		txType:        resolve("type", mapping.Type),
+		category:      resolve("category", mapping.Category),
+		subcategory:   resolve("subcategory", mapping.Subcategory),
+		account:       resolve("account", mapping.Account),
+		targetAccount: resolve("target_account", mapping.TargetAccount),
+		description:   resolve("description", mapping.Description),
+		tags:          resolve("tags", mapping.Tags),
+	}
+	if err != nil {
+		return columns, err
+	}
+
+	if columns.date < 0 {
+		return columns, fmt.Errorf("date column mapping is required")
+	}
+	if columns.amount < 0 {
+		return columns, fmt.Errorf("amount column mapping is required")
+	}
+	return columns, nil
+}
+
+// resolveImportColumn 解析单个列引用，支持列名和从1开始的列序号
+func resolveImportColumn(name, ref string, header []string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return -1, nil
+	}
+
+	for i, title := range header {
+		if strings.EqualFold(strings.TrimSpace(title), ref) {
+			return i, nil
+		}
+	}
+
+	if idx, err := strconv.Atoi(ref); err == nil && idx >= 1 {
+		return idx - 1, nil
+	}
+
+	return -1, fmt.Errorf("%s column %q not found", name, ref)
+}
+
+// detectDateLayout 选择能解析最多日期值的格式
+func detectDateLayout(values []string) string {
+	bestLayout := ""
+	bestCount := 0
+	for _, layout := range importDateLayouts {
+		count := 0
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if _, err := time.Parse(layout, value); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			bestLayout = layout
+			bestCount = count
+		}
+	}
+	return bestLayout
+}
+
+// detectDecimalSeparator 检测金额列使用的小数分隔符
+// 出现"1.234,56"或"12,50"这类写法时认为使用逗号作为小数分隔符
+func detectDecimalSeparator(values []string) byte {
+	for _, value := range values {
+		lastComma := strings.LastIndexByte(value, ',')
+		if lastComma < 0 {
+			continue
+		}
+		lastDot := strings.LastIndexByte(value, '.')
+		if lastDot >= 0 {
+			if lastComma > lastDot {
+				return ','
+			}
+			continue
+		}
+		digits := 0
+		for _, r := range value[lastComma+1:] {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if strings.Count(value, ",") == 1 && digits > 0 && digits <= 2 {
+			return ','
+		}
+	}
+	return '.'
+}
+
+// parseImportAmount 解析金额，返回两位小数的绝对值和是否为负数
+func parseImportAmount(value string, decimalSep byte) (string, bool, error) {
+	raw := strings.TrimSpace(value)
+	if raw == "" {
+		return "", false, fmt.Errorf("amount is empty")
+	}
+
+	negative := false
+	if strings.HasPrefix(raw, "(") && strings.HasSuffix(raw, ")") {
+		negative = true
+		raw = raw[1 : len(raw)-1]
+	}
+
+	thousandsSep := byte(',')
+	if decimalSep == ',' {
+		thousandsSep = '.'
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteByte(c)
+		case c == decimalSep:
+			b.WriteByte('.')
+		case c == '-':
+			negative = true
+		case c == thousandsSep, c == ' ', c == '\'', c == '+':
+			// 忽略千分位和正号
+		case c < utf8.RuneSelf && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '$'):
+			// 忽略货币代码和符号
+		case c >= utf8.RuneSelf:
+			// 忽略¥、€等多字节货币符号
+		default:
+			return "", false, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	amount, err := normalizeAmount(b.String())
+	if err != nil {
+		return "", false, fmt.Errorf("invalid amount %q", value)
+	}
+	if strings.HasPrefix(amount, "-") {
+		negative = true
+		amount = amount[1:]
+	}
+	return amount, negative, nil
+}
+
+// normalizeAmount 将金额规范化为两位小数
+func normalizeAmount(amount string) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return "", fmt.Errorf("invalid amount %q", amount)
+	}
+	return r.FloatString(2), nil
+}
+
+// mapImportName 根据映射表将名称转换为ID
+// 映射表非空时，未映射的名称视为错误，便于在预览中发现遗漏
+func mapImportName(name string, mapping map[string]string, kind string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if len(mapping) == 0 {
+		return name, nil
+	}
+	if id, ok := mapping[name]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("unmapped %s %q", kind, name)
+}
+
+// parseImportTags 解析标签列，格式为"a,b"或"key=value;key2=value2"
+func parseImportTags(value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '，' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if key, val, ok := strings.Cut(part, "="); ok {
+			tags[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		} else {
+			tags[part] = ""
+		}
+	}
+	return tags
+}
+
+// cellAt 获取指定列的值，列不存在时返回空字符串
+func cellAt(fields []string, idx int) string {
+	if idx < 0 || idx >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[idx])
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
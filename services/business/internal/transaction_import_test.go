@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+)
+
+func TestDetectDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   byte
+	}{
+		{"dot decimals", []string{"12.50", "3"}, '.'},
+		{"comma thousands", []string{"1,234.56"}, '.'},
+		{"comma thousands without decimals", []string{"1,234"}, '.'},
+		{"dot thousands", []string{"1.234,56"}, ','},
+		{"comma decimals", []string{"12,50"}, ','},
+		{"first comma decimal wins", []string{"1,234", "3,5"}, ','},
+		{"no separators", []string{"", "7"}, '.'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDecimalSeparator(tt.values); got != tt.want {
+				t.Errorf("detectDecimalSeparator(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportAmount(t *testing.T) {
+	tests := []struct {
+		value        string
+		decimalSep   byte
+		want         string
+		wantNegative bool
+		wantErr      bool
+	}{
+		{value: "12.5", decimalSep: '.', want: "12.50"},
+		{value: "-12.5", decimalSep: '.', want: "12.50", wantNegative: true},
+		{value: "(1,234.50)", decimalSep: '.', want: "1234.50", wantNegative: true},
+		{value: "1.234,56", decimalSep: ',', want: "1234.56"},
+		{value: "-0,5", decimalSep: ',', want: "0.50", wantNegative: true},
+		{value: "¥ 88", decimalSep: '.', want: "88.00"},
+		{value: "USD 3.10", decimalSep: '.', want: "3.10"},
+		{value: "", decimalSep: '.', wantErr: true},
+		{value: "12..5", decimalSep: '.', wantErr: true},
+		{value: "1#2", decimalSep: '.', wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, negative, err := parseImportAmount(tt.value, tt.decimalSep)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseImportAmount(%q) = %s, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportAmount(%q): %v", tt.value, err)
+			}
+			if got != tt.want || negative != tt.wantNegative {
+				t.Errorf("parseImportAmount(%q) = %s, %v, want %s, %v", tt.value, got, negative, tt.want, tt.wantNegative)
+			}
+		})
+	}
+}
+
+func TestImportTransactions(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:  "owner",
+		Ledgers: []*business.Ledger{{Id: "ledger-1", Name: "Daily", Currency: "EUR"}},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	importCSV := func(data string, mapping *business.ImportColumnMapping, dryRun bool) *business.ImportTransactionsResponse {
+		t.Helper()
+		resp, err := s.ImportTransactions(ctx, &business.ImportTransactionsRequest{
+			UserId:           "owner",
+			LedgerId:         "ledger-1",
+			Format:           "csv",
+			Data:             []byte(data),
+			Mapping:          mapping,
+			DefaultAccountId: "bank",
+			DryRun:           dryRun,
+		})
+		if err != nil {
+			t.Fatalf("ImportTransactions: %v", err)
+		}
+		return resp
+	}
+
+	// 逗号作为小数分隔符，同一文件中金额写法不同的重复行只导入一次
+	data := "Datum;Betrag;Text\n01.03.2024;-12,50;Brot\n01.03.2024;-12,5;Brot\n02.03.2024;1.500,00;Gehalt\n"
+	mapping := &business.ImportColumnMapping{Date: "Datum", Amount: "Betrag", Description: "Text"}
+
+	preview := importCSV(data, mapping, true)
+	if preview.DecimalSeparator != "," || preview.DateFormat != "2.1.2006" {
+		t.Errorf("detected separator %q and date format %q, want \",\" and \"2.1.2006\"", preview.DecimalSeparator, preview.DateFormat)
+	}
+	if preview.Imported != 2 || preview.Duplicates != 1 {
+		t.Errorf("preview imported %d with %d duplicates, want 2 and 1", preview.Imported, preview.Duplicates)
+	}
+	if got := preview.Rows[0].Transaction; got.Amount != "12.50" || got.Type != transactionTypeExpense || got.Date != "2024-03-01" {
+		t.Errorf("first row = %s %s on %s, want expense 12.50 on 2024-03-01", got.Type, got.Amount, got.Date)
+	}
+	if got := preview.Rows[2].Transaction; got.Amount != "1500.00" || got.Type != transactionTypeIncome {
+		t.Errorf("third row = %s %s, want income 1500.00", got.Type, got.Amount)
+	}
+
+	var count int64
+	s.db.Model(&Transaction{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run saved %d transactions", count)
+	}
+
+	if resp := importCSV(data, mapping, false); resp.Imported != 2 {
+		t.Fatalf("imported %d rows, want 2", resp.Imported)
+	}
+
+	// 再次导入时与已有交易重复的行全部跳过
+	resp := importCSV(data, mapping, false)
+	if resp.Imported != 0 || resp.Duplicates != 3 {
+		t.Errorf("reimport imported %d with %d duplicates, want 0 and 3", resp.Imported, resp.Duplicates)
+	}
+	for _, row := range resp.Rows {
+		if row.Status != importStatusDuplicate {
+			t.Errorf("row %d status = %s, want %s", row.Row, row.Status, importStatusDuplicate)
+		}
+	}
+	s.db.Model(&Transaction{}).Count(&count)
+	if count != 2 {
+		t.Errorf("ledger has %d transactions, want 2", count)
+	}
+}
+
+func TestImportTransactionsValidation(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:  "owner",
+		Ledgers: []*business.Ledger{{Id: "ledger-1", Name: "Daily", Currency: "CNY"}},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// 导入的行与单条创建使用相同的字段校验
+	data := "date,amount,type,account,target,tags\n" +
+		"2024-03-01,10,transfer,cash,,\n" +
+		"2024-03-02,20,transfer,cash,cash,\n" +
+		"2024-03-03,30,expense,cash,,=food\n" +
+		"2024-03-04,40,transfer,cash,bank,\n"
+	resp, err := s.ImportTransactions(ctx, &business.ImportTransactionsRequest{
+		UserId:   "owner",
+		LedgerId: "ledger-1",
+		Format:   "csv",
+		Data:     []byte(data),
+		Mapping: &business.ImportColumnMapping{
+			Date: "date", Amount: "amount", Type: "type", Account: "account", TargetAccount: "target", Tags: "tags",
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportTransactions: %v", err)
+	}
+
+	wantErrors := []string{
+		"Target account ID is required for transfers",
+		"Target account must differ from the source account",
+		"Tag name must not be empty",
+		"",
+	}
+	if len(resp.Rows) != len(wantErrors) {
+		t.Fatalf("got %d rows, want %d", len(resp.Rows), len(wantErrors))
+	}
+	for i, row := range resp.Rows {
+		if row.Error != wantErrors[i] {
+			t.Errorf("row %d error = %q, want %q", row.Row, row.Error, wantErrors[i])
+		}
+	}
+	if resp.Imported != 1 || resp.Failed != 3 {
+		t.Errorf("imported %d with %d failed, want 1 and 3", resp.Imported, resp.Failed)
+	}
+}
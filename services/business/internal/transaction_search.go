@@ -0,0 +1,325 @@
+package internal
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	// searchIndexName MySQL/Postgres全文索引名称
+	searchIndexName = "idx_transactions_search_text"
+	// searchFTSTable SQLite FTS5虚拟表名称
+	searchFTSTable = "transactions_fts"
+	// mysqlMinTokenSize InnoDB全文索引默认的最小词长，更短的词使用LIKE匹配
+	mysqlMinTokenSize = 3
+	// maxSearchPageSize 搜索分页大小上限
+	maxSearchPageSize = 100
+)
+
+// sqliteSearchSchema SQLite全文索引表的触发器
+// 索引表按交易ID关联交易，触发器保证任何写入路径（包括Sync和批量导入）都会同步索引
+var sqliteSearchSchema = []string{
+	`CREATE TRIGGER IF NOT EXISTS transactions_fts_ai AFTER INSERT ON transactions BEGIN
+		INSERT INTO transactions_fts(transaction_id, search_text) VALUES (new.id, new.search_text);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS transactions_fts_ad AFTER DELETE ON transactions BEGIN
+		DELETE FROM transactions_fts WHERE transaction_id = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS transactions_fts_au AFTER UPDATE ON transactions BEGIN
+		DELETE FROM transactions_fts WHERE transaction_id = old.id;
+		INSERT INTO transactions_fts(transaction_id, search_text) VALUES (new.id, new.search_text);
+	END`,
+}
+
+// sqliteSearchTriggers 全文索引触发器名称
+var sqliteSearchTriggers = []string{"transactions_fts_ai", "transactions_fts_ad", "transactions_fts_au"}
+
+// BeforeSave 写入前生成全文检索文本
+// Create和Save都会触发；使用Updates/UpdateColumn修改描述或标签时需要同时更新search_text
+func (t *Transaction) BeforeSave(tx *gorm.DB) error {
+	t.SearchText = buildSearchText(t.Description, t.Tags)
+	return nil
+}
+
+// initSearchIndex 初始化全文索引并回填历史数据
+func (s *BusinessService) initSearchIndex() error {
+	if err := s.backfillSearchText(); err != nil {
+		return err
+	}
+
+	migrator := s.db.Migrator()
+	switch s.db.Dialector.Name() {
+	case "sqlite":
+		// 早期的索引表是以rowid关联transactions的外部内容表，transactions没有整数主键，
+		// VACUUM后rowid可能变化导致索引指向错误的交易，需要删除后按交易ID重建
+		var schema string
+		if err := s.db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", searchFTSTable).Scan(&schema).Error; err != nil {
+			return err
+		}
+		if schema != "" && !strings.Contains(schema, "transaction_id") {
+			for _, trigger := range sqliteSearchTriggers {
+				if err := s.db.Exec("DROP TRIGGER IF EXISTS " + trigger).Error; err != nil {
+					return err
+				}
+			}
+			if err := s.db.Exec("DROP TABLE " + searchFTSTable).Error; err != nil {
+				return err
+			}
+			schema = ""
+		}
+
+		created := false
+		if schema == "" {
+			if err := s.db.Exec(`CREATE VIRTUAL TABLE transactions_fts USING fts5(transaction_id UNINDEXED, search_text, tokenize='unicode61 remove_diacritics 2')`).Error; err != nil {
+				return err
+			}
+			created = true
+		}
+
+		// 表结构迁移可能重建transactions表并丢失触发器，每次启动都确保触发器存在
+		var triggers int64
+		if err := s.db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ?", sqliteSearchTriggers).Scan(&triggers).Error; err != nil {
+			return err
+		}
+		for _, statement := range sqliteSearchSchema {
+			if err := s.db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		if created || triggers < int64(len(sqliteSearchSchema)) {
+			return s.db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec("DELETE FROM transactions_fts").Error; err != nil {
+					return err
+				}
+				return tx.Exec("INSERT INTO transactions_fts(transaction_id, search_text) SELECT id, search_text FROM transactions").Error
+			})
+		}
+
+	case "mysql":
+		if !migrator.HasIndex(&Transaction{}, searchIndexName) {
+			return s.db.Exec("CREATE FULLTEXT INDEX " + searchIndexName + " ON transactions(search_text)").Error
+		}
+	}
+	return nil
+}
+
+// backfillSearchText 为新增search_text列之前写入的交易生成检索文本
+func (s *BusinessService) backfillSearchText() error {
+	var batch []Transaction
+	return s.db.Select("id", "description", "tags").Where("search_text IS NULL").
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, transaction := range batch {
+				// UpdateColumn不触发钩子也不修改updated_at，避免引起客户端重新同步
+				if err := s.db.Model(&Transaction{}).Where("id = ?", transaction.ID).
+					UpdateColumn("search_text", buildSearchText(transaction.Description, transaction.Tags)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// SearchTransactions 全文搜索交易
+func (s *BusinessService) SearchTransactions(ctx context.Context, req *business.SearchTransactionsRequest) (*business.SearchTransactionsResponse, error) {
+	// 设置默认分页
+	page := req.Page
+	pageSize := req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+
+	// 未指定账本时搜索用户参与的所有未归档账本，包括共享账本
+	query := s.db.WithContext(ctx).Model(&Transaction{})
+	if req.LedgerId != "" {
+		if _, err := requireLedgerRole(s.db, req.LedgerId, req.UserId, roleViewer); err != nil {
+			return nil, err
+		}
+		query = query.Where("ledger_id = ?", req.LedgerId)
+	} else {
+		query = query.Where("ledger_id IN (?)", memberLedgerIDs(s.db, req.UserId, false))
+	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if len(req.CategoryIds) > 0 {
+		query = query.Where("category_id IN ? OR subcategory_id IN ?", req.CategoryIds, req.CategoryIds)
+	}
+
+	// 金额过滤
+	for _, bound := range []struct {
+		value string
+		op    string
+	}{{req.MinAmount, ">="}, {req.MaxAmount, "<="}} {
+		if bound.value == "" {
+			continue
+		}
+		amount, err := normalizeAmount(bound.value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid amount: %s", bound.value)
+		}
+		query = query.Where("amount "+bound.op+" ?", amount)
+	}
+
+	// 日期过滤
+	for _, bound := range []struct {
+		value string
+		op    string
+	}{{req.StartDate, ">="}, {req.EndDate, "<="}} {
+		if bound.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", bound.value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid date: %s", bound.value)
+		}
+		query = query.Where("date "+bound.op+" ?", bound.value)
+	}
+
+	// 全文匹配
+	if terms := searchTerms(req.Query); len(terms) > 0 {
+		query = s.applySearchTerms(query, terms)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to count transactions: %v", err)
+	}
+
+	var transactions []Transaction
+	if err := query.Order("date DESC, created_at DESC").Offset(int((page - 1) * pageSize)).Limit(int(pageSize)).
+		Find(&transactions).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to search transactions: %v", err)
+	}
+
+	responseTransactions := make([]*business.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		responseTransactions = append(responseTransactions, transactionToProto(transaction))
+	}
+	if err := loadAttachments(s.db, responseTransactions); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query attachments: %v", err)
+	}
+
+	return &business.SearchTransactionsResponse{
+		Transactions: responseTransactions,
+		Total:        int32(total),
+		Page:         page,
+		PageSize:     pageSize,
+	}, nil
+}
+
+// applySearchTerms 按数据库类型添加全文匹配条件，所有词都必须匹配
+func (s *BusinessService) applySearchTerms(query *gorm.DB, terms []string) *gorm.DB {
+	switch s.db.Dialector.Name() {
+	case "sqlite":
+		parts := make([]string, 0, len(terms))
+		for _, term := range terms {
+			if isCJKTerm(term) {
+				parts = append(parts, `"`+term+`"`)
+			} else {
+				parts = append(parts, `"`+term+`"*`)
+			}
+		}
+		return query.Where("id IN (SELECT transaction_id FROM transactions_fts WHERE transactions_fts MATCH ?)", strings.Join(parts, " "))
+
+	case "mysql":
+		var parts []string
+		for _, term := range terms {
+			// 单字和短词不在InnoDB全文索引中，改用LIKE匹配词首
+			if isCJKTerm(term) || len([]rune(term)) < mysqlMinTokenSize {
+				query = query.Where("CONCAT(' ', search_text) LIKE ?", "% "+term+"%")
+				continue
+			}
+			parts = append(parts, "+"+term+"*")
+		}
+		if len(parts) > 0 {
+			query = query.Where("MATCH(search_text) AGAINST (? IN BOOLEAN MODE)", strings.Join(parts, " "))
+		}
+		return query
+	}
+
+	// 未知数据库退化为LIKE匹配
+	for _, term := range terms {
+		query = query.Where("search_text LIKE ?", "%"+term+"%")
+	}
+	return query
+}
+
+// buildSearchText 由描述和标签生成检索文本
+func buildSearchText(description string, tags map[string]string) string {
+	terms := searchTerms(description)
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		terms = append(terms, searchTerms(key)...)
+		terms = append(terms, searchTerms(tags[key])...)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchTerms 将文本切分为检索词
+// 字母数字组成的词转为小写；中日韩文字没有分词，连续的字符以空格分隔作为一个短语，
+// 这样各数据库的默认分词器都能按单字索引，查询时再按短语匹配
+func searchTerms(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []string
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) > 0 {
+			terms = append(terms, strings.Join(cjk, " "))
+			cjk = cjk[:0]
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+// isCJK 判断字符是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isCJKTerm 判断检索词是否为中日韩文字短语
+func isCJKTerm(term string) bool {
+	for _, r := range term {
+		return isCJK(r)
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	"gorm.io/gorm"
+)
+
+// searchIDs 搜索交易并返回排序后的交易ID
+func searchIDs(t *testing.T, s *BusinessService, query string) []string {
+	t.Helper()
+	resp, err := s.SearchTransactions(context.Background(), &business.SearchTransactionsRequest{UserId: "owner", Query: query})
+	if err != nil {
+		t.Fatalf("SearchTransactions %q: %v", query, err)
+	}
+	var ids []string
+	for _, transaction := range resp.Transactions {
+		ids = append(ids, transaction.Id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestSearchIndexFollowsTransactions(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	transactions := []*business.Transaction{
+		testTransaction("tx-1", "ledger-1", "1.00"),
+		testTransaction("tx-2", "ledger-1", "2.00"),
+		testTransaction("tx-3", "ledger-1", "3.00"),
+	}
+	transactions[0].Description = "coffee beans"
+	transactions[1].Description = "午餐 咖啡"
+	transactions[2].Description = "train ticket"
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:       "owner",
+		Ledgers:      []*business.Ledger{{Id: "ledger-1", Name: "Daily", Currency: "CNY"}},
+		Transactions: transactions,
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// 删除第一笔交易后VACUUM，无整数主键的表的rowid可能重新编号
+	actor := changeActor{userID: "owner"}
+	if err := s.db.Transaction(func(tx *gorm.DB) error { return deleteTransaction(tx, actor, "tx-1") }); err != nil {
+		t.Fatalf("deleteTransaction: %v", err)
+	}
+	if err := s.db.Exec("VACUUM").Error; err != nil {
+		t.Fatalf("VACUUM: %v", err)
+	}
+	transactions[2].Description = "coffee to go"
+	if _, err := s.Sync(ctx, &business.SyncRequest{UserId: "owner", Transactions: transactions[2:]}); err != nil {
+		t.Fatalf("Sync update: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"coffee", []string{"tx-3"}},
+		{"cof", []string{"tx-3"}},
+		{"咖啡", []string{"tx-2"}},
+		{"train", nil},
+		{"beans", nil},
+	}
+	for _, tt := range tests {
+		if got := searchIDs(t, s, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("search %q = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchIndexMigratesRowidTable(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	transaction := testTransaction("tx-1", "ledger-1", "1.00")
+	transaction.Description = "coffee beans"
+	if _, err := s.Sync(ctx, &business.SyncRequest{
+		UserId:       "owner",
+		Ledgers:      []*business.Ledger{{Id: "ledger-1", Name: "Daily", Currency: "CNY"}},
+		Transactions: []*business.Transaction{transaction},
+	}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// 替换为早期以rowid关联的外部内容表
+	for _, statement := range []string{
+		"DROP TRIGGER transactions_fts_ai",
+		"DROP TRIGGER transactions_fts_ad",
+		"DROP TRIGGER transactions_fts_au",
+		"DROP TABLE transactions_fts",
+		"CREATE VIRTUAL TABLE transactions_fts USING fts5(search_text, content='transactions', content_rowid='rowid')",
+		"CREATE TRIGGER transactions_fts_ai AFTER INSERT ON transactions BEGIN INSERT INTO transactions_fts(rowid, search_text) VALUES (new.rowid, new.search_text); END",
+		"CREATE TRIGGER transactions_fts_ad AFTER DELETE ON transactions BEGIN SELECT 1; END",
+		"CREATE TRIGGER transactions_fts_au AFTER UPDATE ON transactions BEGIN SELECT 1; END",
+		"INSERT INTO transactions_fts(transactions_fts) VALUES ('rebuild')",
+	} {
+		if err := s.db.Exec(statement).Error; err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	if err := s.initSearchIndex(); err != nil {
+		t.Fatalf("initSearchIndex: %v", err)
+	}
+	if got := searchIDs(t, s, "beans"); !slices.Equal(got, []string{"tx-1"}) {
+		t.Errorf("search after migration = %v, want [tx-1]", got)
+	}
+
+	// 迁移后的触发器按交易ID维护索引
+	transaction.Description = "tea"
+	if _, err := s.Sync(ctx, &business.SyncRequest{UserId: "owner", Transactions: []*business.Transaction{transaction}}); err != nil {
+		t.Fatalf("Sync update: %v", err)
+	}
+	if got := searchIDs(t, s, "beans"); len(got) != 0 {
+		t.Errorf("search for replaced text = %v, want none", got)
+	}
+	if got := searchIDs(t, s, "tea"); !slices.Equal(got, []string{"tx-1"}) {
+		t.Errorf("search after update = %v, want [tx-1]", got)
+	}
+}
@@ -2,10 +2,12 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	stdsync "sync"
 	"time"
 
@@ -14,7 +16,9 @@ import (
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/generator"
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/loader"
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/revision"
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/sync"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/validator"
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/watcher"
 )
 
@@ -26,18 +30,21 @@ type ConfigManager struct {
 	configPath  string
 	currentCfg  *model.Config
 	isActive    bool // 服务是否已激活
-	subscribers map[int64]config.ConfigService_WatchConfigServer
+	subscribers map[int64]*subscriber
 	nextSubID   int64
+	version     int64           // 配置版本，与最新的配置修订版本号相同
+	revisions   *revision.Store // 配置修订版本，Init之前为空
 	fileWatcher *watcher.FileWatcher
 	envWatcher  *watcher.EnvWatcher
 	mu          stdsync.RWMutex // 互斥锁，保护共享资源
+	applyMu     stdsync.Mutex   // 串行化配置变更，保证校验、同步到文件和环境变量、生成修订版本按同一顺序完成
 }
 
 // NewConfigManager 创建配置管理器
 func NewConfigManager(configPath string) *ConfigManager {
 	return &ConfigManager{
 		configPath:  configPath,
-		subscribers: make(map[int64]config.ConfigService_WatchConfigServer),
+		subscribers: make(map[int64]*subscriber),
 		nextSubID:   1,
 	}
 }
@@ -71,15 +78,41 @@ func (cm *ConfigManager) Init() error {
 	// 检查配置完整性
 	cfg = sync.CheckConfigIntegrity(cfg)
 
+	// 读取已保存的配置版本，启动时的配置与最新版本相同时不产生新版本
+	revisions := revision.NewStore(filepath.Join(configDir, "revisions"))
+	if err := revisions.Load(); err != nil {
+		return fmt.Errorf("failed to load config revisions: %w", err)
+	}
+
+	// 配置不合法时使用最新版本的配置，没有保存过的版本时无法启动
+	if err := validator.Validate(cfg); err != nil {
+		latest := revisions.Latest()
+		if latest == nil {
+			return err
+		}
+		log.Printf("Ignoring config file, keeping version %d: %v", latest.Version, err)
+		if cfg, err = latest.CloneConfig(); err != nil {
+			return fmt.Errorf("failed to read config revision %d: %w", latest.Version, err)
+		}
+	}
+
 	// 同步配置到环境变量
 	if err := sync.SyncConfig(cfg, model.ConfigSourceFile, cm.configPath); err != nil {
 		return fmt.Errorf("failed to sync config to env: %w", err)
 	}
 
 	cm.mu.Lock()
-	cm.currentCfg = cfg
+	cm.revisions = revisions
+	if latest := revisions.Latest(); latest != nil {
+		cm.currentCfg = latest.Config
+		cm.version = latest.Version
+	}
 	cm.mu.Unlock()
 
+	if _, err := cm.setConfig(cfg, model.ConfigSourceFile, systemAuthor, 0); err != nil {
+		return fmt.Errorf("failed to apply config: %w", err)
+	}
+
 	// 启动配置监听器
 	if err := cm.startWatchers(); err != nil {
 		return fmt.Errorf("failed to start watchers: %w", err)
@@ -93,7 +126,7 @@ func (cm *ConfigManager) Init() error {
 func (cm *ConfigManager) startWatchers() error {
 	// 启动文件监听器
 	fileWatcher, err := watcher.NewFileWatcher(cm.configPath, func(cfg *model.Config) {
-		cm.handleConfigChange(cfg, model.ConfigSourceFile)
+		cm.handleConfigChange(cfg, model.ConfigSourceFile, systemAuthor, 0)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create file watcher: %w", err)
@@ -111,25 +144,52 @@ func (cm *ConfigManager) startWatchers() error {
 	return nil
 }
 
-// handleConfigChange 处理配置变化
-func (cm *ConfigManager) handleConfigChange(cfg *model.Config, source model.ConfigSource) {
+// handleConfigChange 处理配置变化，返回新的配置版本，配置内容没有变化时返回nil
+func (cm *ConfigManager) handleConfigChange(cfg *model.Config, source model.ConfigSource, author string, rollbackOf int64) (*revision.Revision, error) {
+	cm.applyMu.Lock()
+	defer cm.applyMu.Unlock()
+
 	// 检查配置完整性
 	cfg = sync.CheckConfigIntegrity(cfg)
 
+	// 不合法的配置不生效，保留当前配置
+	if err := validator.Validate(cfg); err != nil {
+		cm.mu.RLock()
+		version := cm.version
+		cm.mu.RUnlock()
+		log.Printf("Rejected config change from %s, keeping version %d: %v", source, version, err)
+		return nil, err
+	}
+
+	// 记录同步前的配置文件和当前配置，保存版本失败时用于恢复
+	previousFile, readErr := os.ReadFile(cm.configPath)
+	cm.mu.RLock()
+	previousCfg := cm.currentCfg
+	cm.mu.RUnlock()
+
 	// 同步配置
 	if err := sync.SyncConfig(cfg, source, cm.configPath); err != nil {
 		log.Printf("Failed to sync config: %v", err)
-		return
+		return nil, err
 	}
 
-	// 更新当前配置
-	cm.mu.Lock()
-	oldCfg := cm.currentCfg
-	cm.currentCfg = cfg
-	cm.mu.Unlock()
-
-	// 通知所有订阅者
-	cm.notifySubscribers(oldCfg)
+	rev, err := cm.setConfig(cfg, source, author, rollbackOf)
+	if err != nil {
+		log.Printf("Failed to apply config: %v", err)
+		// 恢复已同步的配置文件和环境变量，避免重启后加载没有版本记录的配置
+		if source != model.ConfigSourceFile && (readErr == nil || os.IsNotExist(readErr)) {
+			if err := sync.RestoreConfigFile(cm.configPath, previousFile, readErr == nil); err != nil {
+				log.Printf("Failed to restore config file: %v", err)
+			}
+		}
+		if previousCfg != nil {
+			if err := sync.SyncConfigToEnv(previousCfg); err != nil {
+				log.Printf("Failed to restore config env: %v", err)
+			}
+		}
+		return nil, err
+	}
+	return rev, nil
 }
 
 // handleEnvChange 处理环境变量变化
@@ -141,44 +201,41 @@ func (cm *ConfigManager) handleEnvChange(_ map[string]string) {
 		return
 	}
 
-	cm.handleConfigChange(cfg, model.ConfigSourceEnv)
+	cm.handleConfigChange(cfg, model.ConfigSourceEnv, systemAuthor, 0)
 }
 
-// notifySubscribers 通知所有订阅者配置变更
-func (cm *ConfigManager) notifySubscribers(_ *model.Config) {
-	// 创建配置变更事件
-	event := &config.ConfigChangeEvent{
-		Version:   "v1.0.0",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Key:       "config.updated",
-	}
-
-	// 遍历所有订阅者
+// GetConfig 获取配置
+func (cm *ConfigManager) GetConfig(ctx context.Context, req *config.GetConfigRequest) (*config.GetConfigResponse, error) {
 	cm.mu.RLock()
-	subscribersCopy := make(map[int64]config.ConfigService_WatchConfigServer, len(cm.subscribers))
-	for k, v := range cm.subscribers {
-		subscribersCopy[k] = v
-	}
+	cfg := cm.currentCfg
+	version := cm.version
 	cm.mu.RUnlock()
 
-	for subID, stream := range subscribersCopy {
-		if err := stream.Send(event); err != nil {
-			log.Printf("Failed to send config change event to subscriber %d: %v", subID, err)
-			// 移除无效的订阅者
-			cm.mu.Lock()
-			delete(cm.subscribers, subID)
-			cm.mu.Unlock()
+	configs := cm.flattenConfig(cfg)
+
+	// 只返回指定的配置项
+	if len(req.Keys) > 0 {
+		selected := make(map[string]*config.ConfigItem, len(req.Keys))
+		for _, key := range req.Keys {
+			if item, ok := configs[key]; ok {
+				selected[key] = item
+			}
 		}
+		configs = selected
 	}
-}
 
-// GetConfig 获取配置
-func (cm *ConfigManager) GetConfig(ctx context.Context, req *config.GetConfigRequest) (*config.GetConfigResponse, error) {
-	cm.mu.RLock()
-	cfg := cm.currentCfg
-	cm.mu.RUnlock()
+	return &config.GetConfigResponse{
+		Configs: configs,
+		Version: formatVersion(version),
+	}, nil
+}
 
+// flattenConfig 将配置模型展开为以点分隔键索引的配置项
+func (cm *ConfigManager) flattenConfig(cfg *model.Config) map[string]*config.ConfigItem {
 	configs := make(map[string]*config.ConfigItem)
+	if cfg == nil {
+		return configs
+	}
 
 	// 将模型转换为gRPC响应格式
 	cm.convertServerConfig(cfg.Server, configs)
@@ -187,12 +244,10 @@ func (cm *ConfigManager) GetConfig(ctx context.Context, req *config.GetConfigReq
 	cm.convertJWTConfig(cfg.JWT, configs)
 	cm.convertLogConfig(cfg.Log, configs)
 	cm.convertCORSConfig(cfg.CORS, configs)
+	cm.convertFirewallConfig(cfg.Firewall, configs)
 	cm.convertCacheConfig(cfg.Cache, configs)
 
-	return &config.GetConfigResponse{
-		Configs: configs,
-		Version: "v1.0.0",
-	}, nil
+	return configs
 }
 
 // convertServerConfig 转换服务器配置
@@ -359,6 +414,59 @@ func (cm *ConfigManager) convertStorageConfig(storage model.StorageConfig, confi
 		Value: fmt.Sprintf("%d", storage.MaxFileSize),
 		Type:  "int",
 	}
+	configs["storage.allowed_file_types"] = &config.ConfigItem{
+		Key:   "storage.allowed_file_types",
+		Value: strings.Join(storage.AllowedFileTypes, ","),
+		Type:  "list",
+	}
+	configs["storage.user_quota"] = &config.ConfigItem{
+		Key:   "storage.user_quota",
+		Value: fmt.Sprintf("%d", storage.UserQuota),
+		Type:  "int",
+	}
+	configs["storage.export_retention_hours"] = &config.ConfigItem{
+		Key:   "storage.export_retention_hours",
+		Value: fmt.Sprintf("%d", storage.ExportRetention),
+		Type:  "int",
+	}
+	// 存储加密配置
+	configs["storage.encryption.master_key"] = &config.ConfigItem{
+		Key:   "storage.encryption.master_key",
+		Value: storage.Encryption.MasterKey,
+		Type:  "string",
+	}
+	configs["storage.encryption.master_key_file"] = &config.ConfigItem{
+		Key:   "storage.encryption.master_key_file",
+		Value: storage.Encryption.MasterKeyFile,
+		Type:  "string",
+	}
+	configs["storage.encryption.previous_keys"] = &config.ConfigItem{
+		Key:   "storage.encryption.previous_keys",
+		Value: strings.Join(storage.Encryption.PreviousKeys, ","),
+		Type:  "list",
+	}
+	// 签名下载URL配置
+	configs["storage.signed_url.secret"] = &config.ConfigItem{
+		Key:   "storage.signed_url.secret",
+		Value: storage.SignedURL.Secret,
+		Type:  "string",
+	}
+	configs["storage.signed_url.expire_minutes"] = &config.ConfigItem{
+		Key:   "storage.signed_url.expire_minutes",
+		Value: fmt.Sprintf("%d", storage.SignedURL.ExpireMinutes),
+		Type:  "int",
+	}
+	// 存储巡检配置
+	configs["storage.scrub.interval_hours"] = &config.ConfigItem{
+		Key:   "storage.scrub.interval_hours",
+		Value: fmt.Sprintf("%d", storage.Scrub.IntervalHours),
+		Type:  "int",
+	}
+	configs["storage.scrub.action"] = &config.ConfigItem{
+		Key:   "storage.scrub.action",
+		Value: storage.Scrub.Action,
+		Type:  "string",
+	}
 	// 本地存储配置
 	configs["storage.local.path"] = &config.ConfigItem{
 		Key:   "storage.local.path",
@@ -479,6 +587,26 @@ func (cm *ConfigManager) convertLogConfig(log model.LogConfig, configs map[strin
 
 // convertCORSConfig 转换CORS配置
 func (cm *ConfigManager) convertCORSConfig(cors model.CORSConfig, configs map[string]*config.ConfigItem) {
+	configs["cors.allowed_origins"] = &config.ConfigItem{
+		Key:   "cors.allowed_origins",
+		Value: strings.Join(cors.AllowedOrigins, ","),
+		Type:  "list",
+	}
+	configs["cors.allowed_methods"] = &config.ConfigItem{
+		Key:   "cors.allowed_methods",
+		Value: strings.Join(cors.AllowedMethods, ","),
+		Type:  "list",
+	}
+	configs["cors.allowed_headers"] = &config.ConfigItem{
+		Key:   "cors.allowed_headers",
+		Value: strings.Join(cors.AllowedHeaders, ","),
+		Type:  "list",
+	}
+	configs["cors.exposed_headers"] = &config.ConfigItem{
+		Key:   "cors.exposed_headers",
+		Value: strings.Join(cors.ExposedHeaders, ","),
+		Type:  "list",
+	}
 	configs["cors.allow_credentials"] = &config.ConfigItem{
 		Key:   "cors.allow_credentials",
 		Value: fmt.Sprintf("%t", cors.AllowCredentials),
@@ -491,6 +619,15 @@ func (cm *ConfigManager) convertCORSConfig(cors model.CORSConfig, configs map[st
 	}
 }
 
+// convertFirewallConfig 转换防火墙配置
+func (cm *ConfigManager) convertFirewallConfig(firewall model.FirewallConfig, configs map[string]*config.ConfigItem) {
+	configs["firewall.default_action"] = &config.ConfigItem{
+		Key:   "firewall.default_action",
+		Value: firewall.DefaultAction,
+		Type:  "string",
+	}
+}
+
 // convertCacheConfig 转换缓存配置
 func (cm *ConfigManager) convertCacheConfig(cache model.CacheConfig, configs map[string]*config.ConfigItem) {
 	configs["cache.active"] = &config.ConfigItem{
@@ -527,30 +664,6 @@ func (cm *ConfigManager) convertCacheConfig(cache model.CacheConfig, configs map
 	}
 }
 
-// WatchConfig 监听配置变化
-func (cm *ConfigManager) WatchConfig(req *config.WatchConfigRequest, stream config.ConfigService_WatchConfigServer) error {
-	// 生成订阅ID
-	subID := cm.nextSubID
-	cm.nextSubID++
-
-	// 添加订阅者
-	cm.mu.Lock()
-	cm.subscribers[subID] = stream
-	cm.mu.Unlock()
-	log.Printf("New subscriber: %d", subID)
-
-	// 保持连接，直到客户端断开
-	<-stream.Context().Done()
-
-	// 移除订阅者
-	cm.mu.Lock()
-	delete(cm.subscribers, subID)
-	cm.mu.Unlock()
-	log.Printf("Subscriber disconnected: %d", subID)
-
-	return nil
-}
-
 // ReloadConfig 重新加载配置
 func (cm *ConfigManager) ReloadConfig(ctx context.Context, req *config.ReloadConfigRequest) (*common.Response, error) {
 	log.Println("Reloading config...")
@@ -565,7 +678,17 @@ func (cm *ConfigManager) ReloadConfig(ctx context.Context, req *config.ReloadCon
 		}, nil
 	}
 
-	cm.handleConfigChange(cfg, model.ConfigSourceGRPC)
+	if _, err := cm.handleConfigChange(cfg, model.ConfigSourceGRPC, authorOrSystem(req.Author), 0); err != nil {
+		code := int32(500)
+		if errors.As(err, new(*validator.ValidationError)) {
+			code = 400
+		}
+		return &common.Response{
+			Success: false,
+			Message: fmt.Sprintf("Failed to apply config: %v", err),
+			Code:    code,
+		}, nil
+	}
 
 	return &common.Response{
 		Success: true,
@@ -641,8 +764,8 @@ func (cm *ConfigManager) Watch(req *common.HealthCheckRequest, stream common.Hea
 
 // UpdateConfig 更新配置（内部使用）
 func (cm *ConfigManager) UpdateConfig(cfg *model.Config) error {
-	cm.handleConfigChange(cfg, model.ConfigSourceGRPC)
-	return nil
+	_, err := cm.handleConfigChange(cfg, model.ConfigSourceGRPC, systemAuthor, 0)
+	return err
 }
 
 // Shutdown 关闭配置管理器
@@ -657,7 +780,8 @@ func (cm *ConfigManager) Shutdown() {
 
 	// 关闭所有订阅者连接
 	cm.mu.Lock()
-	for subID := range cm.subscribers {
+	for subID, sub := range cm.subscribers {
+		close(sub.events)
 		delete(cm.subscribers, subID)
 	}
 	cm.mu.Unlock()
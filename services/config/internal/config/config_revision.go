@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/revision"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// systemAuthor 由配置文件和环境变量变化产生的版本的操作者
+const systemAuthor = "system"
+
+// authorOrSystem 请求未指定操作者时使用systemAuthor
+func authorOrSystem(author string) string {
+	if author == "" {
+		return systemAuthor
+	}
+	return author
+}
+
+// setConfig 更新当前配置，保存为新版本并通知订阅者，在同一个锁内完成以保证事件按版本顺序送达
+// 配置内容没有变化时不产生新版本，返回nil；保存版本失败时不更新配置
+func (cm *ConfigManager) setConfig(cfg *model.Config, source model.ConfigSource, author string, rollbackOf int64) (*revision.Revision, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	events := cm.diffConfig(cm.currentCfg, cfg, "")
+	if len(events) == 0 {
+		cm.currentCfg = cfg
+		return nil, nil
+	}
+
+	// 未初始化版本存储时只在内存中递增版本号
+	rev := &revision.Revision{
+		Version:    cm.version + 1,
+		Author:     author,
+		Source:     source,
+		Timestamp:  time.Now(),
+		RollbackOf: rollbackOf,
+		Config:     cfg,
+	}
+	if cm.revisions != nil {
+		saved, err := cm.revisions.Append(cfg, source, author, rollbackOf)
+		if err != nil {
+			return nil, err
+		}
+		rev = saved
+	}
+
+	cm.currentCfg = cfg
+	cm.version = rev.Version
+	version := formatVersion(rev.Version)
+	for _, event := range events {
+		event.Version = version
+	}
+	log.Printf("Config changed to version %d by %s (%s), %d keys updated", rev.Version, author, source, len(events))
+
+	cm.notifySubscribers(events)
+	return rev, nil
+}
+
+// revisionInfo 转换配置版本信息
+func revisionInfo(rev *revision.Revision) *config.RevisionInfo {
+	return &config.RevisionInfo{
+		Version:    rev.Version,
+		Author:     rev.Author,
+		Source:     rev.Source.String(),
+		Timestamp:  rev.Timestamp.Format(time.RFC3339),
+		RollbackOf: rev.RollbackOf,
+	}
+}
+
+// revisionStore 返回配置版本存储，服务未启动时返回错误
+func (cm *ConfigManager) revisionStore() (*revision.Store, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.revisions == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Config service is not started")
+	}
+	return cm.revisions, nil
+}
+
+// ListRevisions 列出配置版本
+func (cm *ConfigManager) ListRevisions(ctx context.Context, req *config.ListRevisionsRequest) (*config.ListRevisionsResponse, error) {
+	store, err := cm.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := store.List(int(req.Limit))
+	resp := &config.ListRevisionsResponse{Revisions: make([]*config.RevisionInfo, 0, len(revisions))}
+	for _, rev := range revisions {
+		resp.Revisions = append(resp.Revisions, revisionInfo(rev))
+	}
+	return resp, nil
+}
+
+// DiffRevisions 比较两个配置版本，返回从from_version到to_version变化的配置项
+func (cm *ConfigManager) DiffRevisions(ctx context.Context, req *config.DiffRevisionsRequest) (*config.DiffRevisionsResponse, error) {
+	store, err := cm.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	from := store.Get(req.FromVersion)
+	if from == nil {
+		return nil, status.Errorf(codes.NotFound, "Config revision %d not found", req.FromVersion)
+	}
+	to := store.Get(req.ToVersion)
+	if to == nil {
+		return nil, status.Errorf(codes.NotFound, "Config revision %d not found", req.ToVersion)
+	}
+
+	changes := cm.diffConfig(from.Config, to.Config, formatVersion(to.Version))
+	timestamp := to.Timestamp.Format(time.RFC3339)
+	for _, change := range changes {
+		change.Timestamp = timestamp
+	}
+	return &config.DiffRevisionsResponse{Changes: changes}, nil
+}
+
+// RollbackConfig 回滚到指定的配置版本，回滚后的配置保存为新版本并通知订阅者
+// 当前配置与指定版本相同时不产生新版本，返回最新版本
+func (cm *ConfigManager) RollbackConfig(ctx context.Context, req *config.RollbackConfigRequest) (*config.RevisionInfo, error) {
+	store, err := cm.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	target := store.Get(req.Version)
+	if target == nil {
+		return nil, status.Errorf(codes.NotFound, "Config revision %d not found", req.Version)
+	}
+	cfg, err := target.CloneConfig()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to read config revision %d: %v", req.Version, err)
+	}
+
+	author := authorOrSystem(req.Author)
+	log.Printf("Rolling back config to version %d by %s", req.Version, author)
+	rev, err := cm.handleConfigChange(cfg, model.ConfigSourceGRPC, author, req.Version)
+	if errors.As(err, new(*validator.ValidationError)) {
+		return nil, status.Errorf(codes.InvalidArgument, "Failed to roll back config: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to roll back config: %v", err)
+	}
+	if rev == nil {
+		rev = store.Latest()
+	}
+	return revisionInfo(rev), nil
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	stdsync "sync"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/loader"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/revision"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/sync"
+)
+
+func TestRollbackConfig(t *testing.T) {
+	// 回滚会同步环境变量，测试结束后恢复
+	for _, key := range []string{"DATABASE_TYPE", "STORAGE_TYPE", "ADMIN_PASSWORD", "SERVER_PORT"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	cm := NewConfigManager(filepath.Join(dir, "config.yaml"))
+	cm.revisions = revision.NewStore(filepath.Join(dir, "revisions"))
+	if err := cm.revisions.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	cfg := sync.CheckConfigIntegrity(&model.Config{})
+	cfg.Log.Level = "info"
+	if _, err := cm.setConfig(cfg, model.ConfigSourceFile, systemAuthor, 0); err != nil {
+		t.Fatalf("setConfig: %v", err)
+	}
+	changed := *cfg
+	changed.Log.Level = "debug"
+	if _, err := cm.setConfig(&changed, model.ConfigSourceEnv, systemAuthor, 0); err != nil {
+		t.Fatalf("setConfig: %v", err)
+	}
+
+	ctx := context.Background()
+	list, err := cm.ListRevisions(ctx, &config.ListRevisionsRequest{})
+	if err != nil || len(list.Revisions) != 2 || list.Revisions[0].Version != 2 || list.Revisions[0].Source != "env" {
+		t.Fatalf("ListRevisions = %v, %v, want versions 2 (env) and 1", list, err)
+	}
+	diff, err := cm.DiffRevisions(ctx, &config.DiffRevisionsRequest{FromVersion: 1, ToVersion: 2})
+	if err != nil || len(diff.Changes) != 1 || diff.Changes[0].Key != "log.level" || diff.Changes[0].NewValue.Value != "debug" {
+		t.Fatalf("DiffRevisions = %v, %v, want log.level info -> debug", diff, err)
+	}
+	if _, err := cm.DiffRevisions(ctx, &config.DiffRevisionsRequest{FromVersion: 1, ToVersion: 9}); err == nil {
+		t.Error("DiffRevisions with unknown version succeeded")
+	}
+
+	// 回滚产生新的版本，而不是回到旧的版本号
+	info, err := cm.RollbackConfig(ctx, &config.RollbackConfigRequest{Version: 1, Author: "admin"})
+	if err != nil {
+		t.Fatalf("RollbackConfig: %v", err)
+	}
+	if info.Version != 3 || info.RollbackOf != 1 || info.Author != "admin" || info.Source != "grpc" {
+		t.Errorf("RollbackConfig = %v, want version 3 rolled back to 1 by admin via grpc", info)
+	}
+	resp, _ := cm.GetConfig(ctx, &config.GetConfigRequest{Keys: []string{"log.level"}})
+	if resp.Version != "3" || resp.Configs["log.level"].Value != "info" {
+		t.Errorf("GetConfig = version %s with log.level %s, want version 3 with info", resp.Version, resp.Configs["log.level"].Value)
+	}
+
+	// 版本保存在磁盘上，重新加载后保持不变
+	store := revision.NewStore(filepath.Join(dir, "revisions"))
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if latest := store.Latest(); latest == nil || latest.Version != 3 || latest.RollbackOf != 1 || latest.Config.Log.Level != "info" {
+		t.Errorf("reloaded latest revision = %+v, want version 3 with log.level info", latest)
+	}
+	if rev := store.Get(2); rev == nil || rev.Config.Log.Level != "debug" {
+		t.Errorf("reloaded revision 2 = %+v, want log.level debug", rev)
+	}
+}
+
+func TestRejectInvalidConfig(t *testing.T) {
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.yaml"))
+	cfg := sync.CheckConfigIntegrity(&model.Config{})
+	if _, err := cm.setConfig(cfg, model.ConfigSourceFile, systemAuthor, 0); err != nil {
+		t.Fatalf("setConfig: %v", err)
+	}
+
+	invalid := *cfg
+	invalid.Database.Active = "mongo"
+	if _, err := cm.handleConfigChange(&invalid, model.ConfigSourceFile, systemAuthor, 0); err == nil {
+		t.Fatal("handleConfigChange accepted database.active mongo")
+	}
+	resp, _ := cm.GetConfig(context.Background(), &config.GetConfigRequest{Keys: []string{"database.active"}})
+	if resp.Version != "1" || resp.Configs["database.active"].Value != "sqlite" {
+		t.Errorf("GetConfig = version %s with database.active %s, want the last good config", resp.Version, resp.Configs["database.active"].Value)
+	}
+}
+
+func TestConcurrentConfigChanges(t *testing.T) {
+	for _, key := range []string{"DATABASE_TYPE", "STORAGE_TYPE", "ADMIN_PASSWORD", "SERVER_PORT"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	cm := NewConfigManager(filepath.Join(dir, "config.yaml"))
+	cm.revisions = revision.NewStore(filepath.Join(dir, "revisions"))
+	if err := cm.revisions.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// 并发的配置变更依次生效，配置文件、环境变量和最新修订版本必须一致
+	var wg stdsync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg := sync.CheckConfigIntegrity(&model.Config{})
+			cfg.Server.Port = 9000 + i
+			if _, err := cm.handleConfigChange(cfg, model.ConfigSourceGRPC, systemAuthor, 0); err != nil {
+				t.Errorf("handleConfigChange port %d: %v", cfg.Server.Port, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	latest := cm.revisions.Latest()
+	if latest == nil || latest.Version != 8 {
+		t.Fatalf("latest revision = %+v, want version 8", latest)
+	}
+	loaded, _, err := loader.LoadConfig(cm.configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.Server.Port != latest.Config.Server.Port {
+		t.Errorf("config file has port %d, latest revision has %d", loaded.Server.Port, latest.Config.Server.Port)
+	}
+}
+
+func TestConfigChangeRestoresFileWhenRevisionFails(t *testing.T) {
+	for _, key := range []string{"DATABASE_TYPE", "STORAGE_TYPE", "ADMIN_PASSWORD", "SERVER_PORT"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	revisionDir := filepath.Join(dir, "revisions")
+	cm := NewConfigManager(filepath.Join(dir, "config.yaml"))
+	cm.revisions = revision.NewStore(revisionDir)
+	if err := cm.revisions.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	cfg := sync.CheckConfigIntegrity(&model.Config{})
+	cfg.Server.Port = 9000
+	if _, err := cm.handleConfigChange(cfg, model.ConfigSourceGRPC, systemAuthor, 0); err != nil {
+		t.Fatalf("handleConfigChange: %v", err)
+	}
+	before, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	// 版本目录被替换为普通文件后无法保存新版本
+	if err := os.RemoveAll(revisionDir); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if err := os.WriteFile(revisionDir, nil, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	changed := sync.CheckConfigIntegrity(&model.Config{})
+	changed.Server.Port = 9001
+	if _, err := cm.handleConfigChange(changed, model.ConfigSourceGRPC, systemAuthor, 0); err == nil {
+		t.Fatal("handleConfigChange succeeded without a revision store")
+	}
+
+	after, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("config file was not restored after the revision failed")
+	}
+	if port := os.Getenv("SERVER_PORT"); port != "9000" {
+		t.Errorf("SERVER_PORT = %s, want 9000", port)
+	}
+	if cm.version != 1 || cm.currentCfg.Server.Port != 9000 {
+		t.Errorf("current config = version %d with port %d, want version 1 with port 9000", cm.version, cm.currentCfg.Server.Port)
+	}
+}
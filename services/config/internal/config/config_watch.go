@@ -0,0 +1,137 @@
+package config
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subscriberBuffer 每个订阅者可缓存的未发送变更批次数
+const subscriberBuffer = 16
+
+// subscriber 配置变更订阅者
+type subscriber struct {
+	keys   map[string]bool                  // 监听的配置项，为空时监听所有配置项
+	events chan []*config.ConfigChangeEvent // 待发送的变更事件，每次配置变化为一批
+}
+
+// watches 判断订阅者是否监听指定的配置项
+func (sub *subscriber) watches(key string) bool {
+	return len(sub.keys) == 0 || sub.keys[key]
+}
+
+// formatVersion 格式化配置版本号
+func formatVersion(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// diffConfig 比较新旧配置展开后的配置项，返回按键排序的变更事件
+// 只在一侧存在的配置项对应的另一侧值为空
+func (cm *ConfigManager) diffConfig(oldCfg, newCfg *model.Config, version string) []*config.ConfigChangeEvent {
+	oldItems := cm.flattenConfig(oldCfg)
+	newItems := cm.flattenConfig(newCfg)
+
+	keys := make(map[string]bool, len(newItems))
+	for key := range oldItems {
+		keys[key] = true
+	}
+	for key := range newItems {
+		keys[key] = true
+	}
+
+	timestamp := time.Now().Format(time.RFC3339)
+	var events []*config.ConfigChangeEvent
+	for key := range keys {
+		oldItem, newItem := oldItems[key], newItems[key]
+		if oldItem != nil && newItem != nil && oldItem.Value == newItem.Value && oldItem.Type == newItem.Type {
+			continue
+		}
+		events = append(events, &config.ConfigChangeEvent{
+			Key:       key,
+			OldValue:  oldItem,
+			NewValue:  newItem,
+			Version:   version,
+			Timestamp: timestamp,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
+
+// notifySubscribers 将配置变更分发给监听对应配置项的订阅者，调用方需持有写锁
+func (cm *ConfigManager) notifySubscribers(events []*config.ConfigChangeEvent) {
+	for subID, sub := range cm.subscribers {
+		var batch []*config.ConfigChangeEvent
+		for _, event := range events {
+			if sub.watches(event.Key) {
+				batch = append(batch, event)
+			}
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		// 订阅者处理不过来时断开连接，由客户端重新获取配置后再订阅，避免丢失事件
+		select {
+		case sub.events <- batch:
+		default:
+			log.Printf("Subscriber %d is too slow, disconnecting", subID)
+			close(sub.events)
+			delete(cm.subscribers, subID)
+		}
+	}
+}
+
+// WatchConfig 监听配置变化
+func (cm *ConfigManager) WatchConfig(req *config.WatchConfigRequest, stream config.ConfigService_WatchConfigServer) error {
+	sub := &subscriber{
+		keys:   make(map[string]bool, len(req.Keys)),
+		events: make(chan []*config.ConfigChangeEvent, subscriberBuffer),
+	}
+	for _, key := range req.Keys {
+		sub.keys[key] = true
+	}
+
+	// 添加订阅者
+	cm.mu.Lock()
+	subID := cm.nextSubID
+	cm.nextSubID++
+	cm.subscribers[subID] = sub
+	cm.mu.Unlock()
+	log.Printf("New subscriber: %d", subID)
+
+	defer func() {
+		// 移除订阅者
+		cm.mu.Lock()
+		if cm.subscribers[subID] == sub {
+			delete(cm.subscribers, subID)
+		}
+		cm.mu.Unlock()
+		log.Printf("Subscriber disconnected: %d", subID)
+	}()
+
+	// 保持连接并发送变更事件，直到客户端断开
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case batch, ok := <-sub.events:
+			if !ok {
+				return status.Errorf(codes.Unavailable, "Config subscription closed, fetch the config and watch again")
+			}
+			for _, event := range batch {
+				if err := stream.Send(event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"google.golang.org/grpc"
+)
+
+// fakeWatchStream 记录发送的变更事件的订阅流
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *config.ConfigChangeEvent
+}
+
+func (s *fakeWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchStream) Send(event *config.ConfigChangeEvent) error {
+	s.events <- event
+	return nil
+}
+
+func TestWatchConfig(t *testing.T) {
+	cm := NewConfigManager("")
+	cfg := &model.Config{}
+	cfg.Log.Level = "info"
+	cfg.JWT.ExpireHours = 24
+	cm.setConfig(cfg, model.ConfigSourceFile, systemAuthor, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch := func(keys ...string) *fakeWatchStream {
+		stream := &fakeWatchStream{ctx: ctx, events: make(chan *config.ConfigChangeEvent, 16)}
+		go cm.WatchConfig(&config.WatchConfigRequest{Keys: keys}, stream)
+		return stream
+	}
+	logStream := watch("log.level")
+	allStream := watch()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		cm.mu.RLock()
+		ready := len(cm.subscribers) == 2
+		cm.mu.RUnlock()
+		if ready {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscribers were not registered")
+		}
+	}
+	receive := func(stream *fakeWatchStream) *config.ConfigChangeEvent {
+		t.Helper()
+		select {
+		case event := <-stream.events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no config change event received")
+			return nil
+		}
+	}
+
+	// 内容没有变化时不发送事件，也不增加版本号
+	same := *cfg
+	cm.setConfig(&same, model.ConfigSourceFile, systemAuthor, 0)
+	resp, _ := cm.GetConfig(ctx, &config.GetConfigRequest{Keys: []string{"log.level"}})
+	if resp.Version != "1" || len(resp.Configs) != 1 {
+		t.Fatalf("GetConfig = version %s with %d keys, want version 1 with 1 key", resp.Version, len(resp.Configs))
+	}
+
+	changed := *cfg
+	changed.Log.Level = "debug"
+	changed.JWT.ExpireHours = 48
+	cm.setConfig(&changed, model.ConfigSourceFile, systemAuthor, 0)
+
+	event := receive(logStream)
+	if event.Key != "log.level" || event.OldValue.Value != "info" || event.NewValue.Value != "debug" || event.Version != "2" {
+		t.Errorf("log.level event = %v, want info -> debug at version 2", event)
+	}
+	var keys []string
+	for i := 0; i < 2; i++ {
+		event := receive(allStream)
+		keys = append(keys, event.Key)
+		if event.Version != "2" {
+			t.Errorf("event %s has version %s, want 2", event.Key, event.Version)
+		}
+	}
+	if keys[0] != "jwt.expire_hours" || keys[1] != "log.level" {
+		t.Errorf("unfiltered subscriber received %v, want jwt.expire_hours and log.level", keys)
+	}
+
+	// 只监听log.level的订阅者收不到其他配置项的变更
+	next := changed
+	next.JWT.ExpireHours = 72
+	cm.setConfig(&next, model.ConfigSourceFile, systemAuthor, 0)
+	if event := receive(allStream); event.Key != "jwt.expire_hours" || event.Version != "3" {
+		t.Errorf("event = %v, want jwt.expire_hours at version 3", event)
+	}
+	select {
+	case event := <-logStream.events:
+		t.Errorf("log.level subscriber received %s", event.Key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,13 @@
+package generator
+
+import (
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+)
+
+// GenerateFirewallConfig 生成防火墙配置内容
+func GenerateFirewallConfig(cfg *model.FirewallConfig) string {
+	return `# Firewall Configuration
+firewall:
+  default_action: ` + cfg.DefaultAction + ` # Action when no rule matches: allow, deny
+`
+}
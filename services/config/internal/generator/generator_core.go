@@ -19,6 +19,10 @@ func GenerateDefaultConfig(configPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate secret: %w", err)
 	}
+	urlSecret, err := GenerateRandomSecret()
+	if err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
 
 	// 生成默认配置
 	defaultCfg := &model.Config{
@@ -70,6 +74,15 @@ func GenerateDefaultConfig(configPath string) error {
 			Active:           "local",
 			MaxFileSize:      5242880,
 			AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+			ExportRetention:  7 * 24,
+			SignedURL: model.SignedURLConfig{
+				Secret:        urlSecret,
+				ExpireMinutes: 15,
+			},
+			Scrub: model.ScrubConfig{
+				IntervalHours: 24,
+				Action:        "quarantine",
+			},
 			Local: model.LocalConfig{
 				Path:      "./data/uploads",
 				URLPrefix: "/uploads",
@@ -110,6 +123,9 @@ func GenerateDefaultConfig(configPath string) error {
 			AllowCredentials: true,
 			MaxAge:           "12h",
 		},
+		Firewall: model.FirewallConfig{
+			DefaultAction: "allow",
+		},
 		Cache: model.CacheConfig{
 			Active: "memory",
 			Memory: model.MemoryCacheConfig{
@@ -149,6 +165,10 @@ func GenerateSplitConfigFiles(configDir string) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate secret: %w", err)
 	}
+	urlSecret, err := GenerateRandomSecret()
+	if err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
 
 	// 生成默认配置
 	defaultCfg := &model.Config{
@@ -181,6 +201,15 @@ func GenerateSplitConfigFiles(configDir string) error {
 			Active:           "local",
 			MaxFileSize:      5242880,
 			AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+			ExportRetention:  7 * 24,
+			SignedURL: model.SignedURLConfig{
+				Secret:        urlSecret,
+				ExpireMinutes: 15,
+			},
+			Scrub: model.ScrubConfig{
+				IntervalHours: 24,
+				Action:        "quarantine",
+			},
 			Local: model.LocalConfig{
 				Path:      "./data/uploads",
 				URLPrefix: "/uploads",
@@ -214,6 +243,9 @@ func GenerateSplitConfigFiles(configDir string) error {
 			AllowCredentials: true,
 			MaxAge:           "12h",
 		},
+		Firewall: model.FirewallConfig{
+			DefaultAction: "allow",
+		},
 	}
 
 	// 确保配置目录存在
@@ -229,6 +261,7 @@ func GenerateSplitConfigFiles(configDir string) error {
 		"jwt.yaml":      GenerateJWTConfig(&defaultCfg.JWT),
 		"log.yaml":      GenerateLogConfig(&defaultCfg.Log),
 		"cors.yaml":     GenerateCORSConfig(&defaultCfg.CORS),
+		"firewall.yaml": GenerateFirewallConfig(&defaultCfg.Firewall),
 	}
 
 	// 写入配置文件
@@ -312,6 +345,18 @@ storage:
   max_file_size: ` + fmt.Sprintf("%d", cfg.Storage.MaxFileSize) + `
   allowed_file_types:
     ` + generateAllowedFileTypes(cfg.Storage.AllowedFileTypes) + `
+  user_quota: ` + fmt.Sprintf("%d", cfg.Storage.UserQuota) + `
+  export_retention_hours: ` + fmt.Sprintf("%d", cfg.Storage.ExportRetention) + `
+  encryption:
+    master_key: "` + cfg.Storage.Encryption.MasterKey + `"
+    master_key_file: "` + cfg.Storage.Encryption.MasterKeyFile + `"
+    previous_keys: ` + generateKeyList(cfg.Storage.Encryption.PreviousKeys) + `
+  signed_url:
+    secret: "` + cfg.Storage.SignedURL.Secret + `"
+    expire_minutes: ` + fmt.Sprintf("%d", cfg.Storage.SignedURL.ExpireMinutes) + `
+  scrub:
+    interval_hours: ` + fmt.Sprintf("%d", cfg.Storage.Scrub.IntervalHours) + `
+    action: ` + cfg.Storage.Scrub.Action + `
   local:
     path: ` + cfg.Storage.Local.Path + `
     url_prefix: ` + cfg.Storage.Local.URLPrefix + `
@@ -366,6 +411,10 @@ cors:
     ` + generateExposedHeaders(cfg.CORS.ExposedHeaders) + `
   allow_credentials: ` + fmt.Sprintf("%t", cfg.CORS.AllowCredentials) + `
   max_age: ` + cfg.CORS.MaxAge + `
+
+# Firewall Configuration
+firewall:
+  default_action: ` + cfg.Firewall.DefaultAction + `
 `
 }
 
@@ -381,6 +430,18 @@ func generateAllowedFileTypes(fileTypes []string) string {
 	return result
 }
 
+// generateKeyList 生成主密钥列表配置，为空时生成空列表
+func generateKeyList(keys []string) string {
+	if len(keys) == 0 {
+		return "[]"
+	}
+	var result string
+	for _, key := range keys {
+		result += fmt.Sprintf("\n      - \"%s\"", key)
+	}
+	return result
+}
+
 // generateAllowedOrigins 生成允许的源配置
 func generateAllowedOrigins(origins []string) string {
 	var result string
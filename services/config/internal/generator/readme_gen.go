@@ -19,7 +19,8 @@ func GenerateREADMEFiles(configDir string) error {
 		"- storage.yaml: Storage configuration (local or S3-compatible storage)\n" +
 		"- jwt.yaml: JWT authentication configuration\n" +
 		"- log.yaml: Logging configuration (level, format, output)\n" +
-		"- cors.yaml: CORS configuration\n\n" +
+		"- cors.yaml: CORS configuration\n" +
+		"- firewall.yaml: Firewall configuration (default action)\n\n" +
 		"## Usage\n\n" +
 		"### Starting the Service\n" +
 		"# Start the configuration service\n" +
@@ -49,7 +50,8 @@ func GenerateREADMEFiles(configDir string) error {
 		"- storage.yaml: 存储配置（本地存储或 S3 兼容存储）\n" +
 		"- jwt.yaml: JWT 认证配置\n" +
 		"- log.yaml: 日志配置（级别、格式、输出方式）\n" +
-		"- cors.yaml: CORS 配置\n\n" +
+		"- cors.yaml: CORS 配置\n" +
+		"- firewall.yaml: 防火墙配置（默认动作）\n\n" +
 		"## 使用方法\n\n" +
 		"### 启动服务\n" +
 		"# 启动配置服务\n" +
@@ -14,6 +14,18 @@ storage:
   max_file_size: ` + fmt.Sprintf("%d", cfg.MaxFileSize) + ` # Maximum upload file size (5MB)
   allowed_file_types: # Allowed upload file types
     ` + generateAllowedFileTypes(cfg.AllowedFileTypes) + `
+  user_quota: ` + fmt.Sprintf("%d", cfg.UserQuota) + ` # Per-user storage quota in bytes, 0 means unlimited
+  export_retention_hours: ` + fmt.Sprintf("%d", cfg.ExportRetention) + ` # Hours to keep stored ledger exports before they are deleted
+  encryption: # Envelope encryption, files are stored in plaintext when no master key is set
+    master_key: "` + cfg.Encryption.MasterKey + `" # Base64-encoded 32-byte master key
+    master_key_file: "` + cfg.Encryption.MasterKeyFile + `" # Used when master_key is empty; first line is the current key, later lines are previous keys
+    previous_keys: ` + generateKeyList(cfg.Encryption.PreviousKeys) + ` # Previous master keys, data keys are re-wrapped on startup
+  signed_url: # Signed download URLs served under local.url_prefix
+    secret: "` + cfg.SignedURL.Secret + `" # HMAC secret, a random secret is used when empty and URLs become invalid after restart
+    expire_minutes: ` + fmt.Sprintf("%d", cfg.SignedURL.ExpireMinutes) + ` # Default and maximum lifetime of a signed URL (minutes)
+  scrub: # Background scrubber that cross-checks stored objects against metadata and verifies checksums
+    interval_hours: ` + fmt.Sprintf("%d", cfg.Scrub.IntervalHours) + ` # Hours between scrubs, 0 disables the background scrubber
+    action: ` + cfg.Scrub.Action + ` # What to do with orphaned objects: report, quarantine, remove
   local: # Local storage configuration
     path: ` + cfg.Local.Path + ` # Local storage path
     url_prefix: ` + cfg.Local.URLPrefix + ` # Access prefix
@@ -0,0 +1,25 @@
+package loader
+
+import (
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"github.com/spf13/viper"
+)
+
+// LoadFirewallConfig 加载防火墙配置，旧的配置文件没有该部分时返回空配置，由默认值补充
+func LoadFirewallConfig(v *viper.Viper) (*model.FirewallConfig, error) {
+	var cfg model.FirewallConfig
+	sub := v.Sub("firewall")
+	if sub == nil {
+		cfg.DefaultAction = v.GetString("firewall.default_action")
+		return &cfg, nil
+	}
+	if err := sub.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// BindFirewallEnv 绑定防火墙相关环境变量
+func BindFirewallEnv(v *viper.Viper) {
+	v.BindEnv("firewall.default_action", "FIREWALL_DEFAULT_ACTION")
+}
@@ -22,6 +22,7 @@ func NewViper(configPath string) *viper.Viper {
 	BindJWTEnv(v)
 	BindLogEnv(v)
 	BindCORSEnv(v)
+	BindFirewallEnv(v)
 
 	return v
 }
@@ -78,6 +79,11 @@ func LoadConfig(configPath string) (*model.Config, *model.ExplicitlySetFields, e
 		return nil, nil, fmt.Errorf("failed to load cors config: %w", err)
 	}
 
+	firewallCfg, err := LoadFirewallConfig(v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load firewall config: %w", err)
+	}
+
 	// 构建完整配置
 	cfg = model.Config{
 		Server:   *serverCfg,
@@ -86,6 +92,7 @@ func LoadConfig(configPath string) (*model.Config, *model.ExplicitlySetFields, e
 		JWT:      *jwtCfg,
 		Log:      *logCfg,
 		CORS:     *corsCfg,
+		Firewall: *firewallCfg,
 		Cache: model.CacheConfig{
 			Active: "memory",
 			Memory: model.MemoryCacheConfig{
@@ -182,6 +189,14 @@ func getDefaultConfig() *model.Config {
 			Active:           "local",
 			MaxFileSize:      5242880,
 			AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+			ExportRetention:  7 * 24,
+			SignedURL: model.SignedURLConfig{
+				ExpireMinutes: 15,
+			},
+			Scrub: model.ScrubConfig{
+				IntervalHours: 24,
+				Action:        "quarantine",
+			},
 			Local: model.LocalConfig{
 				Path:      "./data/uploads",
 				URLPrefix: "/uploads",
@@ -222,6 +237,9 @@ func getDefaultConfig() *model.Config {
 			AllowCredentials: true,
 			MaxAge:           "12h",
 		},
+		Firewall: model.FirewallConfig{
+			DefaultAction: "allow",
+		},
 		Cache: model.CacheConfig{
 			Active: "memory",
 			Memory: model.MemoryCacheConfig{
@@ -348,6 +366,15 @@ func mergeConfigs(cfg, defaultCfg *model.Config, explicitlySet *model.Explicitly
 	if cfg.Storage.Local.URLPrefix == "" {
 		cfg.Storage.Local.URLPrefix = defaultCfg.Storage.Local.URLPrefix
 	}
+	if cfg.Storage.SignedURL.ExpireMinutes == 0 {
+		cfg.Storage.SignedURL.ExpireMinutes = defaultCfg.Storage.SignedURL.ExpireMinutes
+	}
+	if cfg.Storage.ExportRetention == 0 {
+		cfg.Storage.ExportRetention = defaultCfg.Storage.ExportRetention
+	}
+	if cfg.Storage.Scrub.Action == "" {
+		cfg.Storage.Scrub.Action = defaultCfg.Storage.Scrub.Action
+	}
 	if cfg.Storage.S3.Region == "" {
 		cfg.Storage.S3.Region = defaultCfg.Storage.S3.Region
 	}
@@ -430,6 +457,11 @@ func mergeConfigs(cfg, defaultCfg *model.Config, explicitlySet *model.Explicitly
 		cfg.CORS.MaxAge = defaultCfg.CORS.MaxAge
 	}
 
+	// 合并 Firewall 配置
+	if cfg.Firewall.DefaultAction == "" {
+		cfg.Firewall.DefaultAction = defaultCfg.Firewall.DefaultAction
+	}
+
 	// 合并 Cache 配置
 	if cfg.Cache.Active == "" {
 		cfg.Cache.Active = defaultCfg.Cache.Active
@@ -13,6 +13,7 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
 	CORS     CORSConfig     `mapstructure:"cors"`
+	Firewall FirewallConfig `mapstructure:"firewall"`
 	Cache    CacheConfig    `mapstructure:"cache"`
 }
 
@@ -100,11 +101,35 @@ type PostgresConfig struct {
 
 // StorageConfig 存储配置
 type StorageConfig struct {
-	Local            LocalConfig `mapstructure:"local"`
-	S3               S3Config    `mapstructure:"s3"`
-	MaxFileSize      int64       `mapstructure:"max_file_size"`
-	AllowedFileTypes []string    `mapstructure:"allowed_file_types"`
-	Active           string      `mapstructure:"active"`
+	Local            LocalConfig      `mapstructure:"local"`
+	S3               S3Config         `mapstructure:"s3"`
+	MaxFileSize      int64            `mapstructure:"max_file_size"`
+	AllowedFileTypes []string         `mapstructure:"allowed_file_types"`
+	UserQuota        int64            `mapstructure:"user_quota"`
+	ExportRetention  int              `mapstructure:"export_retention_hours"`
+	Encryption       EncryptionConfig `mapstructure:"encryption"`
+	SignedURL        SignedURLConfig  `mapstructure:"signed_url"`
+	Scrub            ScrubConfig      `mapstructure:"scrub"`
+	Active           string           `mapstructure:"active"`
+}
+
+// SignedURLConfig 签名下载URL配置
+type SignedURLConfig struct {
+	Secret        string `mapstructure:"secret"`
+	ExpireMinutes int    `mapstructure:"expire_minutes"`
+}
+
+// EncryptionConfig 存储加密配置
+type EncryptionConfig struct {
+	MasterKey     string   `mapstructure:"master_key"`
+	MasterKeyFile string   `mapstructure:"master_key_file"`
+	PreviousKeys  []string `mapstructure:"previous_keys"`
+}
+
+// ScrubConfig 存储巡检配置
+type ScrubConfig struct {
+	IntervalHours int    `mapstructure:"interval_hours"`
+	Action        string `mapstructure:"action"`
 }
 
 // LocalConfig 本地存储配置
@@ -159,6 +184,11 @@ type CORSConfig struct {
 	MaxAge           string   `mapstructure:"max_age"`
 }
 
+// FirewallConfig 防火墙配置
+type FirewallConfig struct {
+	DefaultAction string `mapstructure:"default_action"` // 没有匹配规则时的动作：allow或deny
+}
+
 // DocsConfig API文档配置
 type DocsConfig struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -29,6 +29,20 @@ const (
 	ConfigSourceGRPC
 )
 
+// String 返回配置来源的名称
+func (s ConfigSource) String() string {
+	switch s {
+	case ConfigSourceFile:
+		return "file"
+	case ConfigSourceEnv:
+		return "env"
+	case ConfigSourceGRPC:
+		return "grpc"
+	default:
+		return "unknown"
+	}
+}
+
 // ConfigUpdateRequest 配置更新请求
 type ConfigUpdateRequest struct {
 	Config *Config
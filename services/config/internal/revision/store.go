@@ -0,0 +1,175 @@
+package revision
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+)
+
+// Revision 配置修订版本，每次生效的配置保存为一个不可修改的版本
+type Revision struct {
+	Version    int64              `json:"version"`
+	Author     string             `json:"author"`
+	Source     model.ConfigSource `json:"source"`
+	Timestamp  time.Time          `json:"timestamp"`
+	RollbackOf int64              `json:"rollback_of,omitempty"` // 回滚时为回滚到的版本号
+	Config     *model.Config      `json:"config"`
+}
+
+// Store 配置修订版本存储，每个版本保存为目录下以版本号命名的JSON文件
+type Store struct {
+	dir       string
+	mu        stdsync.RWMutex
+	revisions []*Revision // 按版本号升序排列
+}
+
+// NewStore 创建配置修订版本存储
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Load 从目录中读取已保存的版本，目录不存在时创建
+func (s *Store) Load() error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create revision directory: %w", err)
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read revision directory: %w", err)
+	}
+
+	var revisions []*Revision
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if _, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to read revision %s: %w", name, err)
+		}
+		rev := &Revision{}
+		if err := json.Unmarshal(data, rev); err != nil {
+			return fmt.Errorf("failed to parse revision %s: %w", name, err)
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version < revisions[j].Version
+	})
+
+	s.mu.Lock()
+	s.revisions = revisions
+	s.mu.Unlock()
+	return nil
+}
+
+// Append 保存新版本，版本号为最新版本号加一，返回保存后的版本
+// 先写入临时文件再重命名，避免留下不完整的版本文件
+func (s *Store) Append(cfg *model.Config, source model.ConfigSource, author string, rollbackOf int64) (*Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rev := &Revision{
+		Version:    1,
+		Author:     author,
+		Source:     source,
+		Timestamp:  time.Now(),
+		RollbackOf: rollbackOf,
+		Config:     cfg,
+	}
+	if n := len(s.revisions); n > 0 {
+		rev.Version = s.revisions[n-1].Version + 1
+	}
+
+	data, err := json.MarshalIndent(rev, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".revision-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revision file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write revision file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write revision file: %w", err)
+	}
+	path := filepath.Join(s.dir, strconv.FormatInt(rev.Version, 10)+".json")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to save revision %d: %w", rev.Version, err)
+	}
+
+	// 保存与文件内容相同的副本，调用方之后修改cfg不影响已保存的版本
+	saved := &Revision{}
+	if err := json.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("failed to decode revision: %w", err)
+	}
+	s.revisions = append(s.revisions, saved)
+	return saved, nil
+}
+
+// CloneConfig 返回版本中配置的副本，用于在其基础上生成新的配置
+func (r *Revision) CloneConfig() (*model.Config, error) {
+	data, err := json.Marshal(r.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	cfg := &model.Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Get 返回指定版本，不存在时返回nil
+func (s *Store) Get(version int64) *Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.revisions), func(i int) bool {
+		return s.revisions[i].Version >= version
+	})
+	if i < len(s.revisions) && s.revisions[i].Version == version {
+		return s.revisions[i]
+	}
+	return nil
+}
+
+// Latest 返回最新版本，没有版本时返回nil
+func (s *Store) Latest() *Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.revisions) == 0 {
+		return nil
+	}
+	return s.revisions[len(s.revisions)-1]
+}
+
+// List 按版本号降序返回最近的limit个版本，limit不大于0时返回所有版本
+func (s *Store) List(limit int) []*Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := len(s.revisions)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	revisions := make([]*Revision, 0, n)
+	for i := len(s.revisions) - 1; len(revisions) < n; i-- {
+		revisions = append(revisions, s.revisions[i])
+	}
+	return revisions
+}
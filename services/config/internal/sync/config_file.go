@@ -0,0 +1,249 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/generator"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"go.yaml.in/yaml/v3"
+)
+
+// configFileHeader 配置文件开头的说明
+const configFileHeader = `# BeeCount Cloud Configuration File
+# This file contains all configuration options for the BeeCount Cloud service
+
+`
+
+// MarshalConfig 将配置序列化为带注释的YAML
+// existing为当前配置文件的内容，其中的注释、键顺序和模型之外的配置项会被保留，只更新配置项的值；
+// 文件中没有的配置项按生成器模板的注释追加，existing为空或无法解析时整个文件按模板生成
+func MarshalConfig(cfg *model.Config, existing []byte) ([]byte, error) {
+	values, err := encodeValue(reflect.ValueOf(cfg).Elem())
+	if err != nil {
+		return nil, err
+	}
+	template, err := configTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有可用的现有文件时以模板为基础，使用模板的注释和键顺序
+	doc := parseDocument(existing)
+	if doc == nil {
+		doc = template
+	}
+	mergeMapping(doc.Content[0], values, template.Content[0])
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return separateSections(buf.Bytes()), nil
+}
+
+// configTemplate 返回生成器模板的文档节点，提供注释和键顺序
+// 模板使用空配置生成，避免配置值中的特殊字符影响解析
+func configTemplate() (*yaml.Node, error) {
+	cfg := &model.Config{}
+	content := configFileHeader + strings.Join([]string{
+		generator.GenerateServerConfig(&cfg.Server),
+		generator.GenerateDatabaseConfig(&cfg.Database),
+		generator.GenerateStorageConfig(&cfg.Storage),
+		generator.GenerateJWTConfig(&cfg.JWT),
+		generator.GenerateLogConfig(&cfg.Log),
+		generator.GenerateCORSConfig(&cfg.CORS),
+		generator.GenerateFirewallConfig(&cfg.Firewall),
+	}, "\n")
+	template := parseDocument([]byte(content))
+	if template == nil {
+		return nil, fmt.Errorf("failed to parse config template")
+	}
+	return template, nil
+}
+
+// parseDocument 解析YAML文档，内容为空或顶层不是映射时返回nil
+func parseDocument(content []byte) *yaml.Node {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc
+}
+
+// separateSections 在顶层配置项及其前面的注释之前插入空行，yaml包编码时不保留空行
+func separateSections(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		topLevel := line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-")
+		if i > 0 && topLevel {
+			// 注释属于其后的配置项，只在注释块或没有注释的配置项之前插入空行
+			if prev := out[len(out)-1]; prev != "" && !strings.HasPrefix(prev, "#") {
+				out = append(out, "")
+			}
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// encodeValue 按mapstructure标签将配置模型编码为YAML节点，结构体按字段顺序生成映射
+func encodeValue(v reflect.Value) (*yaml.Node, error) {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return encodeScalar(time.Duration(v.Int()).String())
+	}
+	if v.Kind() != reflect.Struct {
+		return encodeScalar(v.Interface())
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		value, err := encodeValue(v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%w", key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return node, nil
+}
+
+// encodeScalar 编码基本类型、列表和映射，由yaml包决定是否需要加引号
+func encodeScalar(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	// 空列表和空映射使用[]和{}，非空的使用块格式
+	if (node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode) && len(node.Content) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	return node, nil
+}
+
+// mergeMapping 用values中的值更新映射节点dst，保留dst中的注释、键顺序和values中没有的键
+// dst中没有的键从template中复制键和注释后追加，template为空时不带注释
+func mergeMapping(dst, values, template *yaml.Node) {
+	for i := 0; i+1 < len(values.Content); i += 2 {
+		key, value := values.Content[i], values.Content[i+1]
+		tmplKey, tmplValue := lookup(template, key.Value)
+
+		if _, current := lookup(dst, key.Value); current != nil {
+			replaceValue(current, value, tmplValue)
+			continue
+		}
+
+		newKey := &yaml.Node{Kind: yaml.ScalarNode, Value: key.Value}
+		newValue := &yaml.Node{Kind: value.Kind}
+		if tmplKey != nil {
+			newKey.HeadComment = tmplKey.HeadComment
+			newKey.LineComment = tmplKey.LineComment
+		}
+		if tmplValue != nil {
+			newValue.LineComment = tmplValue.LineComment
+		}
+		replaceValue(newValue, value, tmplValue)
+		dst.Content = append(dst.Content, newKey, newValue)
+	}
+}
+
+// replaceValue 将节点current的值替换为value，保留current上的注释
+func replaceValue(current, value, template *yaml.Node) {
+	// 模型中的结构体逐个合并配置项，映射类型的配置项（如cache.options）整体替换
+	if current.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode && value.Tag == "" {
+		current.Style = 0
+		mergeMapping(current, value, template)
+		return
+	}
+
+	// 保留原有字符串的引号风格，减少不必要的改动
+	style := value.Style
+	if current.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && value.Tag == "!!str" &&
+		(current.Style == yaml.DoubleQuotedStyle || current.Style == yaml.SingleQuotedStyle) {
+		style = current.Style
+	}
+
+	// 列表逐项替换，保留原有元素的注释和引号风格
+	content := value.Content
+	if current.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+		for i, item := range value.Content {
+			if i < len(current.Content) {
+				replaceValue(current.Content[i], item, nil)
+				content[i] = current.Content[i]
+			}
+		}
+		if len(content) > 0 && style == yaml.FlowStyle && current.Style != yaml.FlowStyle {
+			style = current.Style
+		}
+	}
+
+	current.Kind = value.Kind
+	current.Tag = value.Tag
+	current.Value = value.Value
+	current.Style = style
+	current.Content = content
+	current.Anchor = ""
+	current.Alias = nil
+}
+
+// lookup 在映射节点中查找键，返回键和值节点，不存在时返回nil
+func lookup(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// writeFileAtomic 先写入同一目录下的临时文件再重命名，写入过程中出错不会破坏原文件
+// 原文件存在时沿用其权限
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
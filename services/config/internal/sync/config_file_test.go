@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/loader"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+)
+
+func TestSyncConfigToFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	existing := `# My BeeCount config
+
+log:
+  level: warn # keep it quiet
+  output: stdout
+
+server:
+  port: 8080 # public port
+  admin_account:
+    password: "old"
+
+database:
+  active: sqlite
+storage:
+  active: local
+jwt:
+  expire_hours: 24
+cors:
+  max_age: 12h
+
+custom:
+  note: not part of the model
+`
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := loader.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg = CheckConfigIntegrity(cfg)
+	cfg.Server.Port = 9090
+	cfg.Log.Level = "debug"
+	cfg.Server.AdminAccount.Password = "p#ss: word"
+	secret := cfg.JWT.Secret
+	if err := SyncConfigToFile(cfg, configPath); err != nil {
+		t.Fatalf("SyncConfigToFile: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"# My BeeCount config", "level: debug # keep it quiet", "port: 9090 # public port", "note: not part of the model", "# Firewall Configuration"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config file does not contain %q:\n%s", want, content)
+		}
+	}
+	if strings.Index(content, "log:") > strings.Index(content, "server:") {
+		t.Errorf("key order was not preserved:\n%s", content)
+	}
+
+	// 写入的是实际配置，重新加载后值不变
+	reloaded, _, err := loader.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig after sync: %v", err)
+	}
+	reloaded = CheckConfigIntegrity(reloaded)
+	if reloaded.Server.Port != 9090 || reloaded.Server.AdminAccount.Password != "p#ss: word" || reloaded.JWT.Secret != secret {
+		t.Errorf("reloaded config = port %d, password %q, secret changed %t", reloaded.Server.Port, reloaded.Server.AdminAccount.Password, reloaded.JWT.Secret != secret)
+	}
+
+	// 内容不变时再次同步结果相同，且不留下临时文件
+	if err := SyncConfigToFile(reloaded, configPath); err != nil {
+		t.Fatalf("SyncConfigToFile: %v", err)
+	}
+	if again, _ := os.ReadFile(configPath); string(again) != content {
+		t.Errorf("second sync changed the file:\n%s", again)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("config directory has %d entries, want only config.yaml", len(entries))
+	}
+	if info, err := os.Stat(configPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("config file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestMarshalConfigWithoutFile(t *testing.T) {
+	cfg := CheckConfigIntegrity(&model.Config{})
+	data, err := MarshalConfig(cfg, nil)
+	if err != nil {
+		t.Fatalf("MarshalConfig: %v", err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "# BeeCount Cloud Configuration File") || !strings.Contains(content, "port: 8080 # Server listening port") {
+		t.Errorf("generated config is missing the template comments:\n%s", content)
+	}
+}
@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -52,14 +53,41 @@ func SyncConfigToEnv(cfg *model.Config) error {
 }
 
 // SyncConfigToFile 将配置同步到配置文件
+// 保留文件中已有的注释和键顺序，通过临时文件和重命名原子地替换
 func SyncConfigToFile(cfg *model.Config, configPath string) error {
+	// 读取现有文件，保留其中的注释和键顺序
+	existing, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
 	// 生成配置文件内容
-	configContent := generateConfigContent(cfg)
+	content, err := MarshalConfig(cfg, existing)
+	if err != nil {
+		return fmt.Errorf("failed to generate config file: %w", err)
+	}
+	if bytes.Equal(content, existing) {
+		return nil
+	}
 
 	// 写入配置文件
-	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+	if err := writeFileAtomic(configPath, content, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
+
+// RestoreConfigFile 将配置文件恢复为同步前的内容，同步前不存在的文件被删除
+func RestoreConfigFile(configPath string, content []byte, existed bool) error {
+	if !existed {
+		if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove config file: %w", err)
+		}
+		return nil
+	}
+	if err := writeFileAtomic(configPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to restore config file: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package sync
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/generator"
@@ -25,29 +24,6 @@ func SyncConfig(cfg *model.Config, source model.ConfigSource, configPath string)
 	return nil
 }
 
-// generateConfigContent 生成配置文件内容
-// 复用generator包的功能
-func generateConfigContent(cfg *model.Config) string {
-	// 创建一个临时文件路径
-	tempPath := "./temp_config.yaml"
-
-	// 使用generator包生成配置文件
-	if err := generator.GenerateDefaultConfig(tempPath); err != nil {
-		return "" // 返回空字符串表示生成失败
-	}
-
-	// 读取临时文件内容
-	content, err := os.ReadFile(tempPath)
-	if err != nil {
-		return ""
-	}
-
-	// 删除临时文件
-	os.Remove(tempPath)
-
-	return string(content)
-}
-
 // CheckConfigIntegrity 检查配置完整性
 // 如果配置项缺失，使用默认值补充
 func CheckConfigIntegrity(cfg *model.Config) *model.Config {
@@ -101,6 +77,14 @@ func CheckConfigIntegrity(cfg *model.Config) *model.Config {
 			Active:           "local",
 			MaxFileSize:      5242880,
 			AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+			ExportRetention:  7 * 24,
+			SignedURL: model.SignedURLConfig{
+				ExpireMinutes: 15,
+			},
+			Scrub: model.ScrubConfig{
+				IntervalHours: 24,
+				Action:        "quarantine",
+			},
 			Local: model.LocalConfig{
 				Path:      "./data/uploads",
 				URLPrefix: "/uploads",
@@ -141,6 +125,9 @@ func CheckConfigIntegrity(cfg *model.Config) *model.Config {
 			AllowCredentials: true,
 			MaxAge:           "12h",
 		},
+		Firewall: model.FirewallConfig{
+			DefaultAction: "allow",
+		},
 		Cache: model.CacheConfig{
 			Active: "memory",
 			Memory: model.MemoryCacheConfig{
@@ -280,6 +267,15 @@ func mergeConfigs(cfg, defaultCfg *model.Config) {
 	if cfg.Storage.Local.URLPrefix == "" {
 		cfg.Storage.Local.URLPrefix = defaultCfg.Storage.Local.URLPrefix
 	}
+	if cfg.Storage.SignedURL.ExpireMinutes == 0 {
+		cfg.Storage.SignedURL.ExpireMinutes = defaultCfg.Storage.SignedURL.ExpireMinutes
+	}
+	if cfg.Storage.ExportRetention == 0 {
+		cfg.Storage.ExportRetention = defaultCfg.Storage.ExportRetention
+	}
+	if cfg.Storage.Scrub.Action == "" {
+		cfg.Storage.Scrub.Action = defaultCfg.Storage.Scrub.Action
+	}
 	if cfg.Storage.S3.Region == "" {
 		cfg.Storage.S3.Region = defaultCfg.Storage.S3.Region
 	}
@@ -362,6 +358,11 @@ func mergeConfigs(cfg, defaultCfg *model.Config) {
 		cfg.CORS.MaxAge = defaultCfg.CORS.MaxAge
 	}
 
+	// 合并 Firewall 配置
+	if cfg.Firewall.DefaultAction == "" {
+		cfg.Firewall.DefaultAction = defaultCfg.Firewall.DefaultAction
+	}
+
 	// 合并 Cache 配置
 	if cfg.Cache.Active == "" {
 		cfg.Cache.Active = defaultCfg.Cache.Active
@@ -0,0 +1,373 @@
+package validator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+)
+
+// FieldError 配置项校验错误，Key与GetConfig返回的键相同
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+// Error 实现error接口
+func (e FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// ValidationError 配置校验错误，包含所有不合法的配置项
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// rule 配置项校验规则
+type rule struct {
+	key   string
+	when  func(cfg *model.Config) bool   // 为空时总是校验
+	check func(cfg *model.Config) string // 返回不合法的原因，合法时返回空
+}
+
+// Validate 按规则校验配置，返回所有不合法的配置项
+// 应在补充默认值之后调用，校验的是将要生效的配置
+func Validate(cfg *model.Config) error {
+	var errs []FieldError
+	for _, r := range rules {
+		if r.when != nil && !r.when(cfg) {
+			continue
+		}
+		if msg := r.check(cfg); msg != "" {
+			errs = append(errs, FieldError{Key: r.key, Message: msg})
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// rules 配置校验规则，按配置文件中的顺序排列
+var rules = slices.Concat(
+	// 服务器配置
+	[]rule{
+		port("server.port", func(c *model.Config) int { return c.Server.Port }),
+		oneOf("server.mode", func(c *model.Config) string { return c.Server.Mode }, "debug", "release", "test"),
+		durationRange("server.read_timeout", func(c *model.Config) time.Duration { return c.Server.ReadTimeout }, time.Second, 24*time.Hour),
+		durationRange("server.write_timeout", func(c *model.Config) time.Duration { return c.Server.WriteTimeout }, time.Second, 24*time.Hour),
+		required("server.admin_account.username", func(c *model.Config) string { return c.Server.AdminAccount.Username }),
+		required("server.admin_account.password", func(c *model.Config) string { return c.Server.AdminAccount.Password }),
+	},
+
+	// 数据库配置，只校验启用的数据库
+	[]rule{
+		oneOf("database.active", func(c *model.Config) string { return c.Database.Active }, "sqlite", "mysql", "postgres"),
+		intRange("database.pool.max_idle_conns", func(c *model.Config) int { return c.Database.Pool.MaxIdleConns }, 0, 10000),
+		intRange("database.pool.max_open_conns", func(c *model.Config) int { return c.Database.Pool.MaxOpenConns }, 0, 10000),
+		durationRange("database.pool.conn_max_lifetime", func(c *model.Config) time.Duration { return c.Database.Pool.ConnMaxLifetime }, 0, 24*time.Hour),
+		durationRange("database.pool.conn_max_idle_time", func(c *model.Config) time.Duration { return c.Database.Pool.ConnMaxIdleTime }, 0, 24*time.Hour),
+		custom("database.pool.max_idle_conns", func(c *model.Config) string {
+			if pool := c.Database.Pool; pool.MaxOpenConns > 0 && pool.MaxIdleConns > pool.MaxOpenConns {
+				return fmt.Sprintf("must not exceed database.pool.max_open_conns (%d), got %d", pool.MaxOpenConns, pool.MaxIdleConns)
+			}
+			return ""
+		}),
+	},
+	when(databaseIs("sqlite"),
+		path("database.sqlite.path", func(c *model.Config) string { return c.Database.SQLite.Path }),
+	),
+	when(databaseIs("mysql"),
+		required("database.mysql.host", func(c *model.Config) string { return c.Database.MySQL.Host }),
+		port("database.mysql.port", func(c *model.Config) int { return c.Database.MySQL.Port }),
+		required("database.mysql.username", func(c *model.Config) string { return c.Database.MySQL.Username }),
+		required("database.mysql.database", func(c *model.Config) string { return c.Database.MySQL.Database }),
+		required("database.mysql.charset", func(c *model.Config) string { return c.Database.MySQL.Charset }),
+		required("database.mysql.loc", func(c *model.Config) string { return c.Database.MySQL.Loc }),
+	),
+	when(databaseIs("postgres"),
+		required("database.postgres.host", func(c *model.Config) string { return c.Database.Postgres.Host }),
+		port("database.postgres.port", func(c *model.Config) int { return c.Database.Postgres.Port }),
+		required("database.postgres.username", func(c *model.Config) string { return c.Database.Postgres.Username }),
+		required("database.postgres.database", func(c *model.Config) string { return c.Database.Postgres.Database }),
+		oneOf("database.postgres.sslmode", func(c *model.Config) string { return c.Database.Postgres.SSLMode },
+			"disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		required("database.postgres.timezone", func(c *model.Config) string { return c.Database.Postgres.Timezone }),
+	),
+
+	// 存储配置，只校验启用的存储后端
+	[]rule{
+		oneOf("storage.active", func(c *model.Config) string { return c.Storage.Active }, "local", "s3"),
+		int64Min("storage.max_file_size", func(c *model.Config) int64 { return c.Storage.MaxFileSize }, 1),
+		int64Min("storage.user_quota", func(c *model.Config) int64 { return c.Storage.UserQuota }, 0),
+		custom("storage.max_file_size", func(c *model.Config) string {
+			if s := c.Storage; s.UserQuota > 0 && s.MaxFileSize > s.UserQuota {
+				return fmt.Sprintf("must not exceed storage.user_quota (%d), got %d", s.UserQuota, s.MaxFileSize)
+			}
+			return ""
+		}),
+		each("storage.allowed_file_types", func(c *model.Config) []string { return c.Storage.AllowedFileTypes }, func(value string) string {
+			if mediaType, _, err := mime.ParseMediaType(value); err != nil || !strings.Contains(mediaType, "/") {
+				return "is not a MIME type"
+			}
+			return ""
+		}),
+		masterKey("storage.encryption.master_key", func(c *model.Config) []string { return []string{c.Storage.Encryption.MasterKey} }),
+		masterKey("storage.encryption.previous_keys", func(c *model.Config) []string { return c.Storage.Encryption.PreviousKeys }),
+		custom("storage.encryption.master_key_file", func(c *model.Config) string {
+			if file := c.Storage.Encryption.MasterKeyFile; file != "" {
+				return checkPath(file)
+			}
+			return ""
+		}),
+		intRange("storage.export_retention_hours", func(c *model.Config) int { return c.Storage.ExportRetention }, 1, 24*365),
+		intRange("storage.signed_url.expire_minutes", func(c *model.Config) int { return c.Storage.SignedURL.ExpireMinutes }, 1, 7*24*60),
+		intRange("storage.scrub.interval_hours", func(c *model.Config) int { return c.Storage.Scrub.IntervalHours }, 0, 24*365),
+		oneOf("storage.scrub.action", func(c *model.Config) string { return c.Storage.Scrub.Action }, "report", "quarantine", "remove"),
+	},
+	when(storageIs("local"),
+		path("storage.local.path", func(c *model.Config) string { return c.Storage.Local.Path }),
+		custom("storage.local.url_prefix", func(c *model.Config) string {
+			if prefix := c.Storage.Local.URLPrefix; !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") {
+				return fmt.Sprintf("must start with / and not end with /, got %q", prefix)
+			}
+			return ""
+		}),
+	),
+	when(storageIs("s3"),
+		required("storage.s3.region", func(c *model.Config) string { return c.Storage.S3.Region }),
+		required("storage.s3.bucket", func(c *model.Config) string { return c.Storage.S3.Bucket }),
+		required("storage.s3.access_key_id", func(c *model.Config) string { return c.Storage.S3.AccessKeyID }),
+		required("storage.s3.secret_access_key", func(c *model.Config) string { return c.Storage.S3.SecretAccessKey }),
+		custom("storage.s3.endpoint", func(c *model.Config) string {
+			if endpoint := c.Storage.S3.Endpoint; endpoint != "" {
+				if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Sprintf("must be an http or https URL, got %q", endpoint)
+				}
+			}
+			return ""
+		}),
+	),
+
+	// JWT配置
+	[]rule{
+		custom("jwt.secret", func(c *model.Config) string {
+			if n := len(c.JWT.Secret); n < 16 {
+				return fmt.Sprintf("must be at least 16 characters, got %d", n)
+			}
+			return ""
+		}),
+		intRange("jwt.expire_hours", func(c *model.Config) int { return c.JWT.ExpireHours }, 1, 24*365),
+		intRange("jwt.rotation_interval_days", func(c *model.Config) int { return c.JWT.RotationIntervalDays }, 0, 365),
+		custom("jwt.last_rotation_date", func(c *model.Config) string {
+			if date := c.JWT.LastRotationDate; date != "" {
+				if _, err := time.Parse(time.DateOnly, date); err != nil {
+					return fmt.Sprintf("must be a date in YYYY-MM-DD format, got %q", date)
+				}
+			}
+			return ""
+		}),
+	},
+
+	// 日志配置，输出到文件时校验文件配置
+	[]rule{
+		oneOf("log.level", func(c *model.Config) string { return c.Log.Level }, "debug", "info", "warn", "error", "fatal"),
+		oneOf("log.format", func(c *model.Config) string { return c.Log.Format }, "json", "console"),
+		oneOf("log.output", func(c *model.Config) string { return c.Log.Output }, "stdout", "console", "file"),
+	},
+	when(func(c *model.Config) bool { return c.Log.Output == "file" },
+		path("log.file.path", func(c *model.Config) string { return c.Log.File.Path }),
+		intRange("log.file.max_size", func(c *model.Config) int { return c.Log.File.MaxSize }, 1, 10240),
+		intRange("log.file.max_backups", func(c *model.Config) int { return c.Log.File.MaxBackups }, 0, 10000),
+		intRange("log.file.max_age", func(c *model.Config) int { return c.Log.File.MaxAge }, 0, 3650),
+		intRange("log.file.max_total_size_gb", func(c *model.Config) int { return c.Log.File.MaxTotalSizeGB }, 0, 10240),
+	),
+
+	// CORS配置
+	[]rule{
+		each("cors.allowed_origins", func(c *model.Config) []string { return c.CORS.AllowedOrigins }, func(value string) string {
+			if value == "*" {
+				return ""
+			}
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				return "must be * or an origin such as https://example.com"
+			}
+			return ""
+		}),
+		each("cors.allowed_methods", func(c *model.Config) []string { return c.CORS.AllowedMethods }, func(value string) string {
+			switch strings.ToUpper(value) {
+			case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+				http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+				return ""
+			}
+			return "is not an HTTP method"
+		}),
+		custom("cors.max_age", func(c *model.Config) string {
+			if value := c.CORS.MaxAge; value != "" {
+				d, err := time.ParseDuration(value)
+				if err != nil || d < 0 {
+					return fmt.Sprintf("must be a non-negative duration such as 12h, got %q", value)
+				}
+			}
+			return ""
+		}),
+	},
+
+	// 防火墙配置
+	[]rule{
+		oneOf("firewall.default_action", func(c *model.Config) string { return c.Firewall.DefaultAction }, "allow", "deny"),
+	},
+
+	// 缓存配置，只校验启用的缓存
+	[]rule{
+		oneOf("cache.active", func(c *model.Config) string { return c.Cache.Active }, "memory", "redis"),
+	},
+	when(func(c *model.Config) bool { return c.Cache.Active == "memory" },
+		intRange("cache.memory.max_size", func(c *model.Config) int { return c.Cache.Memory.MaxSize }, 1, 100000000),
+	),
+	when(func(c *model.Config) bool { return c.Cache.Active == "redis" },
+		required("cache.redis.host", func(c *model.Config) string { return c.Cache.Redis.Host }),
+		port("cache.redis.port", func(c *model.Config) int { return c.Cache.Redis.Port }),
+		intRange("cache.redis.db", func(c *model.Config) int { return c.Cache.Redis.DB }, 0, 15),
+	),
+)
+
+// when 只在条件成立时校验的规则
+func when(cond func(cfg *model.Config) bool, rules ...rule) []rule {
+	for i := range rules {
+		rules[i].when = cond
+	}
+	return rules
+}
+
+// databaseIs 启用的数据库为name时成立
+func databaseIs(name string) func(cfg *model.Config) bool {
+	return func(cfg *model.Config) bool { return cfg.Database.Active == name }
+}
+
+// storageIs 启用的存储后端为name时成立
+func storageIs(name string) func(cfg *model.Config) bool {
+	return func(cfg *model.Config) bool { return cfg.Storage.Active == name }
+}
+
+// custom 自定义校验规则，用于跨配置项的校验
+func custom(key string, check func(cfg *model.Config) string) rule {
+	return rule{key: key, check: check}
+}
+
+// required 不能为空的字符串配置项
+func required(key string, get func(cfg *model.Config) string) rule {
+	return custom(key, func(cfg *model.Config) string {
+		if strings.TrimSpace(get(cfg)) == "" {
+			return "is required"
+		}
+		return ""
+	})
+}
+
+// oneOf 取值只能是values之一的配置项
+func oneOf(key string, get func(cfg *model.Config) string, values ...string) rule {
+	return custom(key, func(cfg *model.Config) string {
+		if value := get(cfg); !slices.Contains(values, value) {
+			return fmt.Sprintf("must be one of %s, got %q", strings.Join(values, ", "), value)
+		}
+		return ""
+	})
+}
+
+// intRange 取值在[min, max]之间的整数配置项
+func intRange(key string, get func(cfg *model.Config) int, min, max int) rule {
+	return custom(key, func(cfg *model.Config) string {
+		if value := get(cfg); value < min || value > max {
+			return fmt.Sprintf("must be between %d and %d, got %d", min, max, value)
+		}
+		return ""
+	})
+}
+
+// int64Min 不小于min的64位整数配置项
+func int64Min(key string, get func(cfg *model.Config) int64, min int64) rule {
+	return custom(key, func(cfg *model.Config) string {
+		if value := get(cfg); value < min {
+			return fmt.Sprintf("must be at least %d, got %d", min, value)
+		}
+		return ""
+	})
+}
+
+// port 端口号配置项
+func port(key string, get func(cfg *model.Config) int) rule {
+	return intRange(key, get, 1, 65535)
+}
+
+// durationRange 取值在[min, max]之间的时长配置项
+func durationRange(key string, get func(cfg *model.Config) time.Duration, min, max time.Duration) rule {
+	return custom(key, func(cfg *model.Config) string {
+		if value := get(cfg); value < min || value > max {
+			return fmt.Sprintf("must be between %v and %v, got %v", min, max, value)
+		}
+		return ""
+	})
+}
+
+// path 文件或目录路径配置项，不要求路径已存在
+func path(key string, get func(cfg *model.Config) string) rule {
+	return custom(key, func(cfg *model.Config) string {
+		return checkPath(get(cfg))
+	})
+}
+
+// checkPath 检查路径是否可用
+func checkPath(value string) string {
+	switch {
+	case strings.TrimSpace(value) == "":
+		return "is required"
+	case strings.ContainsRune(value, 0):
+		return "must not contain NUL characters"
+	case strings.TrimSpace(value) != value:
+		return fmt.Sprintf("must not have leading or trailing spaces, got %q", value)
+	}
+	return ""
+}
+
+// each 逐项校验的列表配置项，错误信息包含不合法的元素
+func each(key string, get func(cfg *model.Config) []string, check func(value string) string) rule {
+	return custom(key, func(cfg *model.Config) string {
+		for _, value := range get(cfg) {
+			if msg := check(value); msg != "" {
+				return fmt.Sprintf("%q %s", value, msg)
+			}
+		}
+		return ""
+	})
+}
+
+// masterKey 存储加密主密钥配置项，为空表示未配置，否则必须是base64编码的32字节密钥
+func masterKey(key string, get func(cfg *model.Config) []string) rule {
+	return custom(key, func(cfg *model.Config) string {
+		for _, value := range get(cfg) {
+			if value == "" {
+				continue
+			}
+			if decoded, err := base64.StdEncoding.DecodeString(value); err != nil || len(decoded) != 32 {
+				return "must be a 32-byte key encoded in base64"
+			}
+		}
+		return ""
+	})
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
+	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/sync"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(sync.CheckConfigIntegrity(&model.Config{})); err != nil {
+		t.Fatalf("default config is invalid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *model.Config)
+		keys   []string
+	}{
+		{"unknown database", func(cfg *model.Config) { cfg.Database.Active = "mongo" }, []string{"database.active"}},
+		{"port out of range", func(cfg *model.Config) { cfg.Server.Port = 99999 }, []string{"server.port"}},
+		{"mysql without host", func(cfg *model.Config) {
+			cfg.Database.Active = "mysql"
+			cfg.Database.MySQL.Host = ""
+			cfg.Database.MySQL.Port = 0
+		}, []string{"database.mysql.host", "database.mysql.port"}},
+		{"inactive database is not checked", func(cfg *model.Config) { cfg.Database.Postgres.SSLMode = "bogus" }, nil},
+		{"idle above open connections", func(cfg *model.Config) {
+			cfg.Database.Pool.MaxOpenConns = 5
+			cfg.Database.Pool.MaxIdleConns = 10
+		}, []string{"database.pool.max_idle_conns"}},
+		{"invalid durations", func(cfg *model.Config) {
+			cfg.Server.ReadTimeout = -1
+			cfg.CORS.MaxAge = "soon"
+		}, []string{"server.read_timeout", "cors.max_age"}},
+		{"s3 without bucket", func(cfg *model.Config) {
+			cfg.Storage.Active = "s3"
+			cfg.Storage.S3.Bucket = ""
+			cfg.Storage.S3.Endpoint = "s3.amazonaws.com"
+		}, []string{"storage.s3.bucket", "storage.s3.endpoint"}},
+		{"log file path", func(cfg *model.Config) { cfg.Log.File.Path = " " }, []string{"log.file.path"}},
+		{"invalid master key", func(cfg *model.Config) { cfg.Storage.Encryption.MasterKey = "c2hvcnQ=" }, []string{"storage.encryption.master_key"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := sync.CheckConfigIntegrity(&model.Config{})
+			tt.modify(cfg)
+			err := Validate(cfg)
+			if tt.keys == nil {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate = %v, want *ValidationError", err)
+			}
+			var keys []string
+			for _, fieldErr := range verr.Errors {
+				keys = append(keys, fieldErr.Key)
+			}
+			if len(keys) != len(tt.keys) {
+				t.Fatalf("invalid keys = %v, want %v (%v)", keys, tt.keys, err)
+			}
+			for i := range keys {
+				if keys[i] != tt.keys[i] {
+					t.Errorf("invalid keys = %v, want %v", keys, tt.keys)
+				}
+			}
+		})
+	}
+}
@@ -27,4 +27,5 @@ var ConfigEnvVars = []string{
 	"CORS_ALLOWED_ORIGINS",
 	"CORS_ALLOWED_METHODS",
 	"CORS_ALLOWED_HEADERS",
+	"FIREWALL_DEFAULT_ACTION",
 }
@@ -2,11 +2,11 @@ package watcher
 
 import (
 	"log"
+	"path/filepath"
 	"time"
 
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/loader"
 	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/model"
-	"github.com/fishdivinity/BeeCount-Cloud/services/config/internal/sync"
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -24,8 +24,8 @@ func NewFileWatcher(configPath string, onChange func(*model.Config)) (*FileWatch
 		return nil, err
 	}
 
-	// 监听配置文件
-	if err := watcher.Add(configPath); err != nil {
+	// 监听配置文件所在的目录，配置文件通过重命名替换后仍能收到事件
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
 		return nil, err
 	}
 
@@ -50,6 +50,10 @@ func (fw *FileWatcher) Start() {
 				if !ok {
 					return
 				}
+				// 忽略目录下的其他文件，包括写入配置文件时使用的临时文件
+				if filepath.Clean(event.Name) != filepath.Clean(fw.configPath) {
+					continue
+				}
 
 				// 防抖处理，避免短时间内多次触发
 				if debounceTimer != nil {
@@ -67,16 +71,7 @@ func (fw *FileWatcher) Start() {
 							return
 						}
 
-						// 检查配置完整性
-						cfg = sync.CheckConfigIntegrity(cfg)
-
-						// 同步配置到环境变量
-						if err := sync.SyncConfig(cfg, model.ConfigSourceFile, fw.configPath); err != nil {
-							log.Printf("Failed to sync config: %v", err)
-							return
-						}
-
-						// 触发配置变更回调
+						// 触发配置变更回调，由回调校验配置后再同步到环境变量
 						if fw.onChange != nil {
 							fw.onChange(cfg)
 						}
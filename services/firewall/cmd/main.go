@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/firewall"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
@@ -24,6 +26,19 @@ func main() {
 	// 初始化防火墙服务
 	firewallService := internal.NewFirewallService()
 
+	// 创建通信抽象层实例
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取配置，配置服务不可用时使用默认值
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(), "firewall.default_action")
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+
 	// 配置防火墙规则
 	firewallService.ConfigureFirewallRules(internal.FirewallConfig{
 		DefaultAction: internal.Allow,
@@ -34,6 +49,16 @@ func main() {
 			},
 		},
 	})
+	if err := firewallService.SetDefaultAction(internal.FirewallAction(configClient.String("firewall.default_action", string(internal.Allow)))); err != nil {
+		log.Fatalf("Failed to configure firewall: %v", err)
+	}
+
+	// 默认动作实时生效
+	configClient.OnChange("firewall.default_action", func(value string) error {
+		return firewallService.SetDefaultAction(internal.FirewallAction(value))
+	})
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
 
 	// 创建gRPC服务器
 	grpcServer := grpc.NewServer()
@@ -44,9 +69,6 @@ func main() {
 	// 注册健康检查服务
 	common.RegisterHealthCheckServiceServer(grpcServer, firewallService)
 
-	// 创建通信抽象层实例
-	trans := transport.NewTransportWithFallback()
-
 	// 确定服务地址
 	address := *socketPath
 	if address == "" {
@@ -83,6 +105,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down FirewallService...")
+	cancelWatch()
 	grpcServer.GracefulStop()
 	log.Println("FirewallService exited")
 }
@@ -2,7 +2,7 @@ package internal
 
 import (
 	"context"
-
+	"fmt"
 	"sync"
 
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
@@ -53,6 +53,19 @@ func (s *FirewallService) ConfigureFirewallRules(config FirewallConfig) {
 	s.config = config
 }
 
+// SetDefaultAction 修改没有匹配规则时的默认动作
+func (s *FirewallService) SetDefaultAction(action FirewallAction) error {
+	if action != Allow && action != Deny {
+		return fmt.Errorf("invalid firewall default action: %s", action)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config.DefaultAction = action
+	return nil
+}
+
 // CheckAccess 检查访问权限
 func (s *FirewallService) CheckAccess(ctx context.Context, req *firewall.CheckAccessRequest) (*firewall.CheckAccessResponse, error) {
 	s.mu.RLock()
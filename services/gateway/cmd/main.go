@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
 	"github.com/fishdivinity/BeeCount-Cloud/services/gateway/internal"
 	"github.com/gin-gonic/gin"
@@ -26,7 +27,37 @@ func main() {
 	gateway := internal.NewAPIGateway()
 
 	// 创建通信抽象层实例
-	_ = transport.NewTransportWithFallback()
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取配置，配置服务不可用时使用默认值
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(),
+		append(serverConfigKeys, corsConfigKeys...)...)
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+
+	// 跨域策略实时生效，监听端口、运行模式和超时需要重启服务
+	applyCORS := func(string) error {
+		cors, err := loadCORSConfig(configClient)
+		if err != nil {
+			return err
+		}
+		gateway.ConfigureCORS(cors)
+		return nil
+	}
+	if err := applyCORS(""); err != nil {
+		log.Fatalf("Failed to load CORS config: %v", err)
+	}
+	for _, key := range corsConfigKeys {
+		configClient.OnChange(key, applyCORS)
+	}
+	configClient.RequireRestart(serverConfigKeys...)
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
 
 	// 配置gRPC客户端，优先使用 Unix 域套接字
 	grpcConfig := internal.GRPCClientConfig{}
@@ -42,17 +73,30 @@ func main() {
 		log.Fatalf("Failed to configure gRPC clients: %v", err)
 	}
 
+	port, err := configClient.Int("server.port", 8080)
+	if err != nil {
+		log.Fatalf("Failed to load server config: %v", err)
+	}
+	readTimeout, err := configClient.Duration("server.read_timeout", 60*time.Second)
+	if err != nil {
+		log.Fatalf("Failed to load server config: %v", err)
+	}
+	writeTimeout, err := configClient.Duration("server.write_timeout", 60*time.Second)
+	if err != nil {
+		log.Fatalf("Failed to load server config: %v", err)
+	}
+
 	// 初始化路由
+	gin.SetMode(configClient.String("server.mode", gin.ReleaseMode))
 	router := gin.Default()
 	gateway.SetupRoutes(router)
 
 	// 配置服务器
-	port := 8080
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      router,
-		ReadTimeout:  60 * time.Second,
-		WriteTimeout: 60 * time.Second,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
 	}
 
 	// 启动服务器
@@ -69,6 +113,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down API Gateway...")
+	cancelWatch()
 
 	// 优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -89,3 +134,40 @@ func getSocketPath(serviceName string) string {
 		return fmt.Sprintf("/tmp/beecount_%s.sock", serviceName)
 	}
 }
+
+// serverConfigKeys 网关使用的服务器配置项，只在启动时读取
+var serverConfigKeys = []string{
+	"server.port",
+	"server.mode",
+	"server.read_timeout",
+	"server.write_timeout",
+}
+
+// corsConfigKeys 网关使用的跨域配置项，变化后实时生效
+var corsConfigKeys = []string{
+	"cors.allowed_origins",
+	"cors.allowed_methods",
+	"cors.allowed_headers",
+	"cors.exposed_headers",
+	"cors.allow_credentials",
+	"cors.max_age",
+}
+
+// loadCORSConfig 从配置服务的配置生成跨域配置，未设置时允许所有来源
+func loadCORSConfig(client *configclient.Client) (internal.CORSConfig, error) {
+	cfg := internal.CORSConfig{
+		AllowedOrigins: client.Strings("cors.allowed_origins", []string{"*"}),
+		AllowedMethods: client.Strings("cors.allowed_methods", nil),
+		AllowedHeaders: client.Strings("cors.allowed_headers", nil),
+		ExposedHeaders: client.Strings("cors.exposed_headers", nil),
+	}
+
+	var err error
+	if cfg.AllowCredentials, err = client.Bool("cors.allow_credentials", false); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxAge, err = client.Duration("cors.max_age", 0); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
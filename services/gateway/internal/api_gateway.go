@@ -1,6 +1,16 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/auth"
@@ -11,10 +21,24 @@ import (
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+// maxImportFileSize 导入文件大小上限，留出gRPC默认4MB消息上限的余量
+const maxImportFileSize = 3 << 20
+
+// uploadChunkSize 上传到存储服务时每个分块的大小
+const uploadChunkSize = 1 << 20
+
+// exportInlineMaxTransactions 超过该交易数量的导出先写入存储服务，再通过文件ID下载
+const exportInlineMaxTransactions = 5000
+
 // GRPCClientConfig gRPC客户端配置
 type GRPCClientConfig struct {
 	AuthServiceAddr     string
@@ -42,6 +66,9 @@ type APIGateway struct {
 	storageConn  *grpc.ClientConn
 	configConn   *grpc.ClientConn
 	logConn      *grpc.ClientConn
+
+	// 跨域策略，配置变更时整体替换
+	cors atomic.Pointer[corsHeaders]
 }
 
 // NewAPIGateway 创建API网关实例
@@ -116,18 +143,7 @@ func (g *APIGateway) dialGRPC(addr string) (*grpc.ClientConn, error) {
 // SetupRoutes 设置路由
 func (g *APIGateway) SetupRoutes(router *gin.Engine) {
 	// CORS中间件
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+	router.Use(g.handleCORS)
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
@@ -150,6 +166,11 @@ func (g *APIGateway) SetupRoutes(router *gin.Engine) {
 		})
 	})
 
+	// 签名下载URL，不需要认证，签名和有效期由存储服务校验
+	uploadURLPrefix := g.uploadURLPrefix()
+	router.GET(uploadURLPrefix+"/:file_id", g.handleSignedDownload)
+	router.HEAD(uploadURLPrefix+"/:file_id", g.handleSignedDownload)
+
 	// API v1路由组
 	v1 := router.Group("/api/v1")
 	{
@@ -172,16 +193,48 @@ func (g *APIGateway) SetupRoutes(router *gin.Engine) {
 				ledgers.POST("", g.handleCreateLedger)
 				ledgers.GET("/:id", g.handleGetLedger)
 				ledgers.PUT("/:id", g.handleUpdateLedger)
+				ledgers.PATCH("/:id", g.handlePatchLedger)
 				ledgers.DELETE("/:id", g.handleDeleteLedger)
+				ledgers.POST("/:id/import", g.handleImportTransactions)
+				ledgers.GET("/:id/export", g.handleExportLedger)
+				ledgers.GET("/:id/members", g.handleListMembers)
+				ledgers.POST("/:id/members", g.handleInviteMember)
+				ledgers.DELETE("/:id/members/:user_id", g.handleRemoveMember)
+				ledgers.POST("/:id/accept", g.handleAcceptInvitation)
+				ledgers.POST("/:id/leave", g.handleLeaveLedger)
+				ledgers.GET("/:id/history", g.handleGetHistory("ledger"))
+				ledgers.POST("/:id/archive", g.handleLedgerAction("archive"))
+				ledgers.POST("/:id/unarchive", g.handleLedgerAction("unarchive"))
+				ledgers.POST("/:id/history/:revision/restore", g.handleRestoreRevision("ledger"))
 			}
 
+			// 回收站路由
+			trash := authRequired.Group("/trash")
+			{
+				trash.GET("", g.handleListTrash)
+				trash.POST("/:id/restore", g.handleLedgerAction("restore"))
+				trash.DELETE("/:id", g.handleLedgerAction("purge"))
+			}
+
+			// 账本邀请路由
+			authRequired.GET("/invitations", g.handleListInvitations)
+
 			// 交易相关路由
 			transactions := authRequired.Group("/transactions")
 			{
 				transactions.GET("", g.handleGetTransactions)
 				transactions.POST("", g.handleCreateTransaction)
+				transactions.GET("/search", g.handleSearchTransactions)
+				transactions.POST("/batch", g.handleBatchCreateTransactions)
+				transactions.PATCH("/batch", g.handleBatchUpdateTransactions)
+				transactions.DELETE("/batch", g.handleBatchDeleteTransactions)
 				transactions.GET("/:id", g.handleGetTransaction)
+				transactions.GET("/:id/history", g.handleGetHistory("transaction"))
+				transactions.POST("/:id/history/:revision/restore", g.handleRestoreRevision("transaction"))
 				transactions.PUT("/:id", g.handleUpdateTransaction)
+				transactions.PATCH("/:id", g.handlePatchTransaction)
+				transactions.POST("/:id/attachments", g.handleUploadAttachment)
+				transactions.DELETE("/:id/attachments/:file_id", g.handleDetachAttachment)
 				transactions.DELETE("/:id", g.handleDeleteTransaction)
 			}
 
@@ -196,8 +249,29 @@ func (g *APIGateway) SetupRoutes(router *gin.Engine) {
 			{
 				attachments.POST("", g.handleUploadAttachment)
 				attachments.GET("/:id", g.handleDownloadAttachment)
+				attachments.HEAD("/:id", g.handleDownloadAttachment)
+				attachments.GET("/:id/url", g.handleGetAttachmentURL)
 				attachments.DELETE("/:id", g.handleDeleteAttachment)
 			}
+
+			// 断点续传上传路由（兼容tus协议的核心部分）
+			uploads := authRequired.Group("/uploads")
+			{
+				uploads.POST("", g.handleCreateUpload)
+				uploads.HEAD("/:id", g.handleGetUploadOffset)
+				uploads.PATCH("/:id", g.handleUploadChunk)
+				uploads.POST("/:id/finalize", g.handleFinalizeUpload)
+				uploads.DELETE("/:id", g.handleCancelUpload)
+			}
+
+			// 导出文件路由
+			exports := authRequired.Group("/exports")
+			{
+				exports.GET("/:file_id", g.handleDownloadExport)
+			}
+
+			// 存储用量路由
+			authRequired.GET("/storage/usage", g.handleGetStorageUsage)
 		}
 	}
 }
@@ -213,10 +287,112 @@ func (g *APIGateway) authMiddleware(c *gin.Context) {
 	}
 
 	// 验证JWT令牌
-	// 这里简化实现，实际应调用authClient.ValidateToken
+	token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	resp, err := g.authClient.ValidateToken(c.Request.Context(), &auth.ValidateTokenRequest{Token: token})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		c.Abort()
+		return
+	}
+	if !resp.Valid {
+		c.JSON(401, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	// 保存当前用户，供后续处理函数使用
+	c.Set("user_id", resp.UserId)
 	c.Next()
 }
 
+// writeGRPCError 将gRPC错误转换为HTTP响应
+func (g *APIGateway) writeGRPCError(c *gin.Context, err error) {
+	st, _ := status.FromError(err)
+
+	httpStatus := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		httpStatus = http.StatusBadRequest
+	case codes.Unauthenticated:
+		httpStatus = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		httpStatus = http.StatusForbidden
+	case codes.NotFound:
+		httpStatus = http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		httpStatus = http.StatusConflict
+	case codes.FailedPrecondition:
+		httpStatus = http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		// 超出存储配额与单个文件过大使用不同的状态码，客户端可以提示用户清理空间
+		if isQuotaExceeded(st) {
+			c.JSON(http.StatusInsufficientStorage, gin.H{"error": st.Message(), "code": "quota_exceeded"})
+			return
+		}
+		httpStatus = http.StatusRequestEntityTooLarge
+	case codes.Unimplemented:
+		httpStatus = http.StatusNotImplemented
+	case codes.Unavailable:
+		httpStatus = http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		httpStatus = http.StatusGatewayTimeout
+	}
+
+	c.JSON(httpStatus, gin.H{"error": st.Message()})
+}
+
+// isQuotaExceeded 判断错误是否由超出存储配额引起，存储服务在此类错误中附带QuotaFailure详情
+func isQuotaExceeded(st *status.Status) bool {
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.QuotaFailure); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bindPatch 解析PATCH请求体到target，返回请求体中出现的字段作为更新掩码
+// 请求体无效时写入400响应并返回false
+func bindPatch(c *gin.Context, target interface{}) ([]string, bool) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Failed to read request body"})
+		return nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		c.JSON(400, gin.H{"error": "Request body must be a JSON object"})
+		return nil, false
+	}
+	// ID以路径参数为准
+	delete(fields, "id")
+	if len(fields) == 0 {
+		c.JSON(400, gin.H{"error": "No fields to update"})
+		return nil, false
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	paths := make([]string, 0, len(fields))
+	for field := range fields {
+		paths = append(paths, field)
+	}
+	sort.Strings(paths)
+	return paths, true
+}
+
+// withDeviceID 将请求头中的设备ID通过gRPC元数据传给后端服务，用于记录变更历史
+func withDeviceID(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if deviceID := c.GetHeader("X-Device-ID"); deviceID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-device-id", deviceID)
+	}
+	return ctx
+}
+
 // 处理登录
 func (g *APIGateway) handleLogin(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Login endpoint"})
@@ -252,11 +428,217 @@ func (g *APIGateway) handleUpdateLedger(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Update ledger endpoint"})
 }
 
+// 处理部分更新账本
+// 请求体只包含要修改的字段，例如：{"description": "..."}
+func (g *APIGateway) handlePatchLedger(c *gin.Context) {
+	var ledger business.Ledger
+	paths, ok := bindPatch(c, &ledger)
+	if !ok {
+		return
+	}
+	ledger.Id = c.Param("id")
+	ledger.UserId = c.GetString("user_id")
+	ledger.UpdateMask = &fieldmaskpb.FieldMask{Paths: paths}
+
+	resp, err := g.businessClient.UpdateLedger(withDeviceID(c), &ledger)
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
 // 处理删除账本
 func (g *APIGateway) handleDeleteLedger(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Delete ledger endpoint"})
 }
 
+// 处理获取账本成员
+func (g *APIGateway) handleListMembers(c *gin.Context) {
+	resp, err := g.businessClient.ListMembers(c.Request.Context(), &business.ListMembersRequest{
+		UserId:   c.GetString("user_id"),
+		LedgerId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理邀请成员
+// 请求体：{"user_id": "...", "role": "editor|viewer"}
+func (g *APIGateway) handleInviteMember(c *gin.Context) {
+	var body struct {
+		UserID string `json:"user_id" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.businessClient.InviteMember(c.Request.Context(), &business.InviteMemberRequest{
+		UserId:       c.GetString("user_id"),
+		LedgerId:     c.Param("id"),
+		MemberUserId: body.UserID,
+		Role:         body.Role,
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(201, resp)
+}
+
+// 处理移除成员
+func (g *APIGateway) handleRemoveMember(c *gin.Context) {
+	resp, err := g.businessClient.RemoveMember(c.Request.Context(), &business.RemoveMemberRequest{
+		UserId:       c.GetString("user_id"),
+		LedgerId:     c.Param("id"),
+		MemberUserId: c.Param("user_id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理接受邀请
+func (g *APIGateway) handleAcceptInvitation(c *gin.Context) {
+	resp, err := g.businessClient.AcceptInvitation(c.Request.Context(), &business.AcceptInvitationRequest{
+		UserId:   c.GetString("user_id"),
+		LedgerId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理退出账本（也用于拒绝邀请）
+func (g *APIGateway) handleLeaveLedger(c *gin.Context) {
+	resp, err := g.businessClient.LeaveLedger(c.Request.Context(), &business.LeaveLedgerRequest{
+		UserId:   c.GetString("user_id"),
+		LedgerId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理获取待接受的邀请
+func (g *APIGateway) handleListInvitations(c *gin.Context) {
+	resp, err := g.businessClient.ListInvitations(c.Request.Context(), &business.ListInvitationsRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理获取变更历史
+func (g *APIGateway) handleGetHistory(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.Query("page"))
+		pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+		resp, err := g.businessClient.GetHistory(c.Request.Context(), &business.GetHistoryRequest{
+			UserId:     c.GetString("user_id"),
+			EntityType: entityType,
+			EntityId:   c.Param("id"),
+			Page:       int32(page),
+			PageSize:   int32(pageSize),
+		})
+		if err != nil {
+			g.writeGRPCError(c, err)
+			return
+		}
+
+		c.JSON(200, resp)
+	}
+}
+
+// 处理恢复版本
+func (g *APIGateway) handleRestoreRevision(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revision, err := strconv.Atoi(c.Param("revision"))
+		if err != nil || revision <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid revision"})
+			return
+		}
+
+		resp, err := g.businessClient.RestoreRevision(c.Request.Context(), &business.RestoreRevisionRequest{
+			UserId:     c.GetString("user_id"),
+			DeviceId:   c.GetHeader("X-Device-ID"),
+			EntityType: entityType,
+			EntityId:   c.Param("id"),
+			Revision:   int32(revision),
+		})
+		if err != nil {
+			g.writeGRPCError(c, err)
+			return
+		}
+
+		c.JSON(200, resp)
+	}
+}
+
+// 处理账本操作（归档、取消归档、从回收站恢复、永久删除）
+func (g *APIGateway) handleLedgerAction(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := &business.LedgerActionRequest{
+			UserId:   c.GetString("user_id"),
+			LedgerId: c.Param("id"),
+		}
+
+		var resp interface{}
+		var err error
+		switch action {
+		case "archive":
+			resp, err = g.businessClient.ArchiveLedger(c.Request.Context(), req)
+		case "unarchive":
+			resp, err = g.businessClient.UnarchiveLedger(c.Request.Context(), req)
+		case "restore":
+			resp, err = g.businessClient.RestoreLedger(c.Request.Context(), req)
+		case "purge":
+			resp, err = g.businessClient.PurgeLedger(c.Request.Context(), req)
+		}
+		if err != nil {
+			g.writeGRPCError(c, err)
+			return
+		}
+
+		c.JSON(200, resp)
+	}
+}
+
+// 处理获取回收站
+func (g *APIGateway) handleListTrash(c *gin.Context) {
+	resp, err := g.businessClient.ListTrash(c.Request.Context(), &business.ListTrashRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
 // 处理获取交易列表
 func (g *APIGateway) handleGetTransactions(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Get transactions endpoint"})
@@ -277,27 +659,584 @@ func (g *APIGateway) handleUpdateTransaction(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Update transaction endpoint"})
 }
 
+// 处理部分更新交易
+// 请求体只包含要修改的字段，例如：{"description": "...", "tags": {...}}
+func (g *APIGateway) handlePatchTransaction(c *gin.Context) {
+	var transaction business.Transaction
+	paths, ok := bindPatch(c, &transaction)
+	if !ok {
+		return
+	}
+	transaction.Id = c.Param("id")
+	transaction.UserId = c.GetString("user_id")
+	transaction.UpdateMask = &fieldmaskpb.FieldMask{Paths: paths}
+
+	resp, err := g.businessClient.UpdateTransaction(withDeviceID(c), &transaction)
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
 // 处理删除交易
 func (g *APIGateway) handleDeleteTransaction(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Delete transaction endpoint"})
 }
 
+// 处理批量创建交易
+// 请求体：{"transactions": [{...}, ...]}
+func (g *APIGateway) handleBatchCreateTransactions(c *gin.Context) {
+	var body struct {
+		Transactions []*business.Transaction `json:"transactions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.businessClient.BatchCreateTransactions(c.Request.Context(), &business.BatchCreateTransactionsRequest{
+		UserId:       c.GetString("user_id"),
+		DeviceId:     c.GetHeader("X-Device-ID"),
+		Transactions: body.Transactions,
+	})
+	g.writeBatchResponse(c, resp, err)
+}
+
+// 处理批量更新交易
+// 请求体：{"updates": [{"transaction": {"id": "...", ...}, "update_mask": ["amount", ...]}, ...]}
+// update_mask为空时更新全部可修改字段
+func (g *APIGateway) handleBatchUpdateTransactions(c *gin.Context) {
+	var body struct {
+		Updates []struct {
+			Transaction *business.Transaction `json:"transaction"`
+			UpdateMask  []string              `json:"update_mask"`
+		} `json:"updates" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	req := &business.BatchUpdateTransactionsRequest{
+		UserId:   c.GetString("user_id"),
+		DeviceId: c.GetHeader("X-Device-ID"),
+		Updates:  make([]*business.TransactionUpdate, 0, len(body.Updates)),
+	}
+	for _, update := range body.Updates {
+		req.Updates = append(req.Updates, &business.TransactionUpdate{
+			Transaction: update.Transaction,
+			UpdateMask:  &fieldmaskpb.FieldMask{Paths: update.UpdateMask},
+		})
+	}
+
+	resp, err := g.businessClient.BatchUpdateTransactions(c.Request.Context(), req)
+	g.writeBatchResponse(c, resp, err)
+}
+
+// 处理批量删除交易
+// 请求体：{"ids": ["...", ...]}
+func (g *APIGateway) handleBatchDeleteTransactions(c *gin.Context) {
+	var body struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.businessClient.BatchDeleteTransactions(c.Request.Context(), &business.BatchDeleteTransactionsRequest{
+		UserId:         c.GetString("user_id"),
+		DeviceId:       c.GetHeader("X-Device-ID"),
+		TransactionIds: body.IDs,
+	})
+	g.writeBatchResponse(c, resp, err)
+}
+
+// writeBatchResponse 写入批量操作结果，事务回滚时返回422及每条记录的失败原因
+func (g *APIGateway) writeBatchResponse(c *gin.Context, resp *business.BatchTransactionsResponse, err error) {
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+	if !resp.Committed {
+		c.JSON(http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理搜索交易
+// 查询参数：q，ledger_id，type，category_id（可重复），min_amount/max_amount，
+// start_date/end_date（YYYY-MM-DD），page，page_size
+func (g *APIGateway) handleSearchTransactions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	resp, err := g.businessClient.SearchTransactions(c.Request.Context(), &business.SearchTransactionsRequest{
+		UserId:      c.GetString("user_id"),
+		Query:       c.Query("q"),
+		LedgerId:    c.Query("ledger_id"),
+		Type:        c.Query("type"),
+		CategoryIds: c.QueryArray("category_id"),
+		MinAmount:   c.Query("min_amount"),
+		MaxAmount:   c.Query("max_amount"),
+		StartDate:   c.Query("start_date"),
+		EndDate:     c.Query("end_date"),
+		Page:        int32(page),
+		PageSize:    int32(pageSize),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理导入交易
+// 表单字段：file（CSV/XLSX文件），format（可选，默认按扩展名判断），
+// mapping/category_mapping/account_mapping（JSON），date_format，sheet，
+// default_account_id，default_type，no_header，dry_run
+func (g *APIGateway) handleImportTransactions(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(413, gin.H{"error": "Import file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	req := &business.ImportTransactionsRequest{
+		UserId:           c.GetString("user_id"),
+		LedgerId:         c.Param("id"),
+		DeviceId:         c.GetHeader("X-Device-ID"),
+		Format:           c.PostForm("format"),
+		Data:             data,
+		Mapping:          &business.ImportColumnMapping{},
+		Sheet:            c.PostForm("sheet"),
+		DateFormat:       c.PostForm("date_format"),
+		DefaultAccountId: c.PostForm("default_account_id"),
+		DefaultType:      c.PostForm("default_type"),
+	}
+	if req.Format == "" {
+		req.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	req.NoHeader, _ = strconv.ParseBool(c.PostForm("no_header"))
+	req.DryRun, _ = strconv.ParseBool(c.PostForm("dry_run"))
+
+	// 解析JSON格式的映射字段
+	jsonFields := map[string]interface{}{
+		"mapping":          req.Mapping,
+		"category_mapping": &req.CategoryMapping,
+		"account_mapping":  &req.AccountMapping,
+	}
+	for field, target := range jsonFields {
+		value := c.PostForm(field)
+		if value == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(value), target); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid " + field + ": " + err.Error()})
+			return
+		}
+	}
+
+	resp, err := g.businessClient.ImportTransactions(c.Request.Context(), req)
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理导出账本
+// 查询参数：format（csv/xlsx/json/qif/ofx，默认csv），start_date/end_date（YYYY-MM-DD），
+// store（true时始终写入存储服务并返回文件ID，保存的导出文件在storage.export_retention_hours后删除）
+func (g *APIGateway) handleExportLedger(c *gin.Context) {
+	userID := c.GetString("user_id")
+	stream, err := g.businessClient.ExportLedger(c.Request.Context(), &business.ExportLedgerRequest{
+		UserId:    userID,
+		LedgerId:  c.Param("id"),
+		Format:    c.DefaultQuery("format", "csv"),
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	// 第一条消息包含文件元信息
+	header, err := stream.Recv()
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	store, _ := strconv.ParseBool(c.Query("store"))
+	if !store && header.TransactionCount <= exportInlineMaxTransactions {
+		c.Header("Content-Type", header.ContentType)
+		c.Header("Content-Disposition", contentDisposition("attachment", header.Filename))
+		c.Status(http.StatusOK)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				// 响应头已发送，只能中断连接
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if _, err := c.Writer.Write(resp.Chunk); err != nil {
+				return
+			}
+			if resp.IsLastChunk {
+				return
+			}
+		}
+	}
+
+	// 大文件写入存储服务
+	upload, err := g.storageClient.UploadFile(c.Request.Context())
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+	first := true
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			upload.CloseSend()
+			g.writeGRPCError(c, err)
+			return
+		}
+
+		req := &storage.UploadFileRequest{
+			Chunk:       resp.Chunk,
+			IsLastChunk: resp.IsLastChunk,
+		}
+		if first {
+			req.UserId = userID
+			req.Filename = header.Filename
+			req.ContentType = header.ContentType
+			req.Metadata = map[string]string{
+				"source":    "export",
+				"ledger_id": c.Param("id"),
+			}
+			req.Export = true
+			first = false
+		}
+		if err := upload.Send(req); err != nil {
+			g.writeGRPCError(c, err)
+			return
+		}
+		if resp.IsLastChunk {
+			break
+		}
+	}
+
+	uploadResp, err := upload.CloseAndRecv()
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	fileID := uploadResp.FileInfo.GetId()
+	c.Header("Location", "/api/v1/exports/"+fileID)
+	c.JSON(http.StatusCreated, gin.H{
+		"file_id":           fileID,
+		"filename":          header.Filename,
+		"content_type":      header.ContentType,
+		"size":              uploadResp.UploadedBytes,
+		"transaction_count": header.TransactionCount,
+		"download_url":      "/api/v1/exports/" + fileID,
+	})
+}
+
+// 处理下载导出文件
+func (g *APIGateway) handleDownloadExport(c *gin.Context) {
+	g.streamStorageFile(c, c.Param("file_id"), c.GetString("user_id"), "", "attachment")
+}
+
+// streamStorageFile 从存储服务流式下载文件并写入响应
+// 支持Range分段下载和基于ETag、Last-Modified的条件请求，disposition为inline或attachment
+// variant为图片版本（thumbnail、medium），为空时下载原文件
+func (g *APIGateway) streamStorageFile(c *gin.Context, fileID, ownerID, variant, disposition string) {
+	info, err := g.storageClient.GetFileInfo(c.Request.Context(), &storage.GetFileInfoRequest{
+		FileId:  fileID,
+		UserId:  ownerID,
+		Variant: variant,
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	// 文件内容上传后不再变化，ETag使用内容校验和，修改时间使用上传时间
+	etag := fileETag(info.Checksum)
+	lastModified, _ := time.Parse(time.RFC3339, info.CreatedAt)
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("Cache-Control", "private, max-age=3600")
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	// 解析Range请求头，无效或多个范围时返回完整内容
+	offset, length, partial := int64(0), info.Size, false
+	if header := c.GetHeader("Range"); header != "" && ifRangeMatches(c.Request, etag, lastModified) {
+		start, n, err := parseByteRange(header, info.Size)
+		switch err {
+		case nil:
+			offset, length, partial = start, n, true
+		case errRangeUnsatisfiable:
+			c.Header("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			c.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"error": "Requested range not satisfiable"})
+			return
+		}
+	}
+
+	contentType := info.GetContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", contentDisposition(disposition, info.GetFilename()))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Length", strconv.FormatInt(length, 10))
+	httpStatus := http.StatusOK
+	if partial {
+		c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+		httpStatus = http.StatusPartialContent
+	}
+	if c.Request.Method == http.MethodHead {
+		c.Status(httpStatus)
+		return
+	}
+
+	stream, err := g.storageClient.DownloadFile(c.Request.Context(), &storage.DownloadFileRequest{
+		FileId:  fileID,
+		UserId:  ownerID,
+		Offset:  offset,
+		Length:  length,
+		Variant: variant,
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		c.Header("Content-Length", "")
+		c.Header("Content-Range", "")
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.Status(httpStatus)
+	for {
+		if _, err := c.Writer.Write(resp.Chunk); err != nil {
+			return
+		}
+		if resp.IsLastChunk {
+			return
+		}
+		if resp, err = stream.Recv(); err != nil {
+			if err != io.EOF {
+				c.Error(err)
+				c.Abort()
+			}
+			return
+		}
+	}
+}
+
 // 处理同步数据
 func (g *APIGateway) handleSync(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Sync endpoint"})
 }
 
 // 处理上传附件
+// 表单字段：file，transaction_id（可选，也可以通过/transactions/:id/attachments指定）
 func (g *APIGateway) handleUploadAttachment(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Upload attachment endpoint"})
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "file is required"})
+		return
+	}
+	transactionID := c.Param("id")
+	if transactionID == "" {
+		transactionID = c.PostForm("transaction_id")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	userID := c.GetString("user_id")
+	contentType := fileHeader.Header.Get("Content-Type")
+	info, err := g.uploadToStorage(c.Request.Context(), userID, fileHeader.Filename, contentType, file, map[string]string{
+		"source":         "attachment",
+		"transaction_id": transactionID,
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	g.attachUploadedFile(c, transactionID, info)
+}
+
+// attachUploadedFile 将已上传到存储服务的文件登记为附件，关联失败时删除已上传的文件
+func (g *APIGateway) attachUploadedFile(c *gin.Context, transactionID string, info *storage.FileInfo) {
+	resp, err := g.businessClient.AttachFile(c.Request.Context(), &business.AttachFileRequest{
+		UserId:        info.UserId,
+		DeviceId:      c.GetHeader("X-Device-ID"),
+		TransactionId: transactionID,
+		FileId:        info.Id,
+		Filename:      info.Filename,
+		ContentType:   info.ContentType,
+		Size:          info.Size,
+	})
+	if err != nil {
+		g.storageClient.DeleteFile(c.Request.Context(), &storage.DeleteFileRequest{FileId: info.Id, UserId: info.UserId})
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
 }
 
 // 处理下载附件
+// 附件可能由共享账本的其他成员上传，先校验访问权限并获取文件所有者
+// 查询参数：variant（可选，thumbnail或medium，下载图片的缩略图或预览图）
 func (g *APIGateway) handleDownloadAttachment(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Download attachment endpoint"})
+	attachment, err := g.businessClient.GetAttachment(c.Request.Context(), &business.AttachmentRequest{
+		UserId: c.GetString("user_id"),
+		FileId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	// 使用inline以便浏览器直接预览PDF和图片
+	g.streamStorageFile(c, attachment.FileId, attachment.UserId, c.Query("variant"), "inline")
 }
 
 // 处理删除附件
 func (g *APIGateway) handleDeleteAttachment(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Delete attachment endpoint"})
+	resp, err := g.businessClient.DeleteAttachment(withDeviceID(c), &business.AttachmentRequest{
+		UserId: c.GetString("user_id"),
+		FileId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理获取存储用量
+func (g *APIGateway) handleGetStorageUsage(c *gin.Context) {
+	resp, err := g.storageClient.GetUsage(c.Request.Context(), &storage.GetUsageRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理取消交易与附件的关联
+func (g *APIGateway) handleDetachAttachment(c *gin.Context) {
+	resp, err := g.businessClient.DetachFile(c.Request.Context(), &business.DetachFileRequest{
+		UserId:        c.GetString("user_id"),
+		DeviceId:      c.GetHeader("X-Device-ID"),
+		TransactionId: c.Param("id"),
+		FileId:        c.Param("file_id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// uploadToStorage 将文件分块上传到存储服务
+func (g *APIGateway) uploadToStorage(ctx context.Context, userID, filename, contentType string, r io.Reader, metadata map[string]string) (*storage.FileInfo, error) {
+	upload, err := g.storageClient.UploadFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, uploadChunkSize)
+	first := true
+	for {
+		n, readErr := io.ReadFull(r, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			upload.CloseSend()
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to read uploaded file: %v", readErr)
+		}
+
+		req := &storage.UploadFileRequest{
+			Chunk:       buffer[:n],
+			IsLastChunk: readErr != nil,
+		}
+		if first {
+			req.UserId = userID
+			req.Filename = filename
+			req.ContentType = contentType
+			req.Metadata = metadata
+			first = false
+		}
+		if err := upload.Send(req); err != nil {
+			if err == io.EOF {
+				// 服务端已中止上传，错误原因由CloseAndRecv返回
+				break
+			}
+			return nil, err
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	resp, err := upload.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.FileInfo, nil
 }
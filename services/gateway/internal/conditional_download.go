@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errRangeIgnored Range请求头无法解析或包含多个范围，按规范返回完整内容
+	errRangeIgnored = errors.New("range ignored")
+	// errRangeUnsatisfiable 请求的范围超出文件大小
+	errRangeUnsatisfiable = errors.New("range not satisfiable")
+)
+
+// fileETag 根据文件内容的SHA-256生成强ETag，没有校验和时返回空字符串
+func fileETag(checksum string) string {
+	if checksum == "" {
+		return ""
+	}
+	return `"` + checksum + `"`
+}
+
+// notModified 根据If-None-Match和If-Modified-Since判断客户端缓存是否仍然有效
+// 同时存在时只使用If-None-Match
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// ifRangeMatches 判断If-Range条件是否满足，不满足时忽略Range返回完整内容
+// If-Range只能使用强比较，弱ETag永远不匹配
+func ifRangeMatches(r *http.Request, etag string, lastModified time.Time) bool {
+	header := strings.TrimSpace(r.Header.Get("If-Range"))
+	if header == "" {
+		return true
+	}
+	if strings.HasPrefix(header, `"`) || strings.HasPrefix(header, "W/") {
+		return etag != "" && header == etag
+	}
+	date, err := http.ParseTime(header)
+	return err == nil && lastModified.Truncate(time.Second).Equal(date)
+}
+
+// parseByteRange 解析单个字节范围，返回起始位置和长度
+// 支持"bytes=start-end"、"bytes=start-"和"bytes=-suffix"三种形式
+func parseByteRange(header string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, errRangeIgnored
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, errRangeIgnored
+	}
+
+	// 后缀范围：最后suffix个字节
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, errRangeIgnored
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, errRangeUnsatisfiable
+		}
+		suffix = min(suffix, size)
+		return size - suffix, suffix, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errRangeIgnored
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, errRangeIgnored
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, errRangeUnsatisfiable
+	}
+	return start, end - start + 1, nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// defaultDownloadFilename 文件名清理后为空时使用的下载文件名
+const defaultDownloadFilename = "download"
+
+// contentDisposition 生成Content-Disposition响应头
+// 文件名来自用户上传，先清理路径、控制字符等，再生成只含ASCII的filename参数，
+// 文件名包含非ASCII或需要转义的字符时另外附带RFC 5987编码的filename*参数
+func contentDisposition(disposition, filename string) string {
+	name := dispositionFilename(filename)
+	fallback := asciiFilename(name)
+	header := disposition + `; filename="` + fallback + `"`
+	if fallback != name {
+		header += "; filename*=UTF-8''" + encodeRFC5987(name)
+	}
+	return header
+}
+
+// dispositionFilename 去掉文件名中的路径、无效的UTF-8、控制字符和双向文本控制等格式字符
+func dispositionFilename(filename string) string {
+	filename = strings.ToValidUTF8(filename, "")
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+
+	var b strings.Builder
+	for _, r := range filename {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		if unicode.IsSpace(r) {
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	filename = strings.TrimSpace(b.String())
+	if filename == "" || filename == "." || filename == ".." {
+		return defaultDownloadFilename
+	}
+	return filename
+}
+
+// asciiFilename 将非ASCII字符以及引号、反斜杠和百分号替换为下划线，用于不支持filename*的客户端
+func asciiFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '%' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// encodeRFC5987 按RFC 5987对UTF-8字节做百分号编码，只保留attr-char
+func encodeRFC5987(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x80 && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("!#$&+-.^_`|~", c) >= 0) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"mime"
+	"strings"
+	"testing"
+)
+
+func FuzzContentDisposition(f *testing.F) {
+	for _, seed := range []string{
+		"receipt.jpg", `report "final".pdf`, "../../etc/passwd", `C:\scans\bill.png`, "账单 2024.csv",
+		"a\r\nSet-Cookie: x=y", "invoice\u202egpj.exe", "100%.txt", "", " ", "\xff\xfe.txt",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, filename string) {
+		header := contentDisposition("attachment", filename)
+		if strings.ContainsAny(header, "\r\n\x00") {
+			t.Fatalf("contentDisposition(%q) = %q contains header-breaking characters", filename, header)
+		}
+		for i := 0; i < len(header); i++ {
+			if header[i] < 0x20 || header[i] > 0x7e {
+				t.Fatalf("contentDisposition(%q) = %q contains non-ASCII byte %#x", filename, header, header[i])
+			}
+		}
+
+		disposition, params, err := mime.ParseMediaType(header)
+		if err != nil {
+			t.Fatalf("contentDisposition(%q) = %q cannot be parsed: %v", filename, header, err)
+		}
+		if disposition != "attachment" {
+			t.Errorf("disposition = %q, want attachment", disposition)
+		}
+		want := dispositionFilename(filename)
+		if params["filename"] != want {
+			t.Errorf("contentDisposition(%q) filename = %q, want %q", filename, params["filename"], want)
+		}
+		if strings.ContainsAny(want, `/\`) {
+			t.Errorf("dispositionFilename(%q) = %q contains a path separator", filename, want)
+		}
+	})
+}
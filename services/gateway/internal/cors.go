@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 网关自身的接口需要的跨域方法和请求头，无论配置如何都会允许
+const (
+	corsRequiredMethods = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsRequiredHeaders = "Content-Type, Authorization, X-Device-ID, " + tusRequestHeaders
+	corsExposedHeaders  = "Location, " + tusResponseHeaders
+)
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// corsHeaders 根据跨域配置生成的响应头
+type corsHeaders struct {
+	origins          []string
+	anyOrigin        bool
+	allowCredentials bool
+	methods          string
+	headers          string
+	exposed          string
+	maxAge           string
+}
+
+// defaultCORSHeaders 未配置时允许所有来源
+var defaultCORSHeaders = newCORSHeaders(CORSConfig{AllowedOrigins: []string{"*"}})
+
+// ConfigureCORS 配置跨域策略，可以在运行时调用，新的策略对之后的请求生效
+func (g *APIGateway) ConfigureCORS(cfg CORSConfig) {
+	g.cors.Store(newCORSHeaders(cfg))
+}
+
+// newCORSHeaders 合并配置与网关需要的方法和请求头，生成响应头
+func newCORSHeaders(cfg CORSConfig) *corsHeaders {
+	h := &corsHeaders{
+		origins:          cfg.AllowedOrigins,
+		anyOrigin:        slices.Contains(cfg.AllowedOrigins, "*"),
+		allowCredentials: cfg.AllowCredentials,
+		methods:          joinHeaderList(cfg.AllowedMethods, corsRequiredMethods),
+		headers:          joinHeaderList(cfg.AllowedHeaders, corsRequiredHeaders),
+		exposed:          joinHeaderList(cfg.ExposedHeaders, corsExposedHeaders),
+	}
+	if cfg.MaxAge > 0 {
+		h.maxAge = strconv.Itoa(int(cfg.MaxAge / time.Second))
+	}
+	return h
+}
+
+// joinHeaderList 合并配置的列表与必需的列表，去掉重复项
+func joinHeaderList(configured []string, required string) string {
+	var items []string
+	seen := make(map[string]bool)
+	for _, item := range append(slices.Clone(configured), strings.Split(required, ",")...) {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[strings.ToLower(item)] {
+			continue
+		}
+		seen[strings.ToLower(item)] = true
+		items = append(items, item)
+	}
+	return strings.Join(items, ", ")
+}
+
+// allowOrigin 返回允许的来源，不允许时返回空
+// 允许携带凭证时浏览器不接受通配符，回显请求的来源
+func (h *corsHeaders) allowOrigin(origin string) string {
+	if h.anyOrigin {
+		if h.allowCredentials && origin != "" {
+			return origin
+		}
+		return "*"
+	}
+	if origin != "" && slices.Contains(h.origins, origin) {
+		return origin
+	}
+	return ""
+}
+
+// handleCORS CORS中间件
+func (g *APIGateway) handleCORS(c *gin.Context) {
+	h := g.cors.Load()
+	if h == nil {
+		h = defaultCORSHeaders
+	}
+
+	header := c.Writer.Header()
+	if origin := h.allowOrigin(c.GetHeader("Origin")); origin != "" {
+		header.Set("Access-Control-Allow-Origin", origin)
+		if origin != "*" {
+			header.Add("Vary", "Origin")
+		}
+		if h.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+	header.Set("Access-Control-Allow-Methods", h.methods)
+	header.Set("Access-Control-Allow-Headers", h.headers)
+	header.Set("Access-Control-Expose-Headers", h.exposed)
+	if h.maxAge != "" && c.Request.Method == "OPTIONS" {
+		header.Set("Access-Control-Max-Age", h.maxAge)
+	}
+
+	if c.Request.Method == "OPTIONS" {
+		c.AbortWithStatus(204)
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// tusVersion 支持的tus协议版本
+	tusVersion = "1.0.0"
+	// tusContentType PATCH请求体的内容类型
+	tusContentType = "application/offset+octet-stream"
+	// tusRequestHeaders 断点续传请求使用的请求头
+	tusRequestHeaders = "Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata, Upload-Checksum"
+	// tusResponseHeaders 断点续传响应返回的响应头
+	tusResponseHeaders = "Tus-Resumable, Upload-Offset, Upload-Length, Upload-Expires"
+)
+
+// 处理创建上传会话
+// 请求头：Upload-Length（文件总大小），Upload-Metadata（可选，逗号分隔的"键 base64值"，支持filename、filetype、transaction_id）
+func (g *APIGateway) handleCreateUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		c.JSON(400, gin.H{"error": "Upload-Length header must be a positive integer"})
+		return
+	}
+	meta, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := g.storageClient.CreateUploadSession(c.Request.Context(), &storage.CreateUploadSessionRequest{
+		UserId:      c.GetString("user_id"),
+		Filename:    meta["filename"],
+		ContentType: meta["filetype"],
+		Size:        size,
+		Metadata: map[string]string{
+			"source":         "attachment",
+			"transaction_id": meta["transaction_id"],
+		},
+	})
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/uploads/"+session.Id)
+	writeUploadHeaders(c, session)
+	c.JSON(http.StatusCreated, session)
+}
+
+// 处理查询上传进度
+func (g *APIGateway) handleGetUploadOffset(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	session, err := g.storageClient.GetUploadSession(c.Request.Context(), &storage.UploadSessionRequest{
+		SessionId: c.Param("id"),
+		UserId:    c.GetString("user_id"),
+	})
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	writeUploadHeaders(c, session)
+	c.Status(http.StatusOK)
+}
+
+// 处理上传文件块
+// 请求头：Upload-Offset（必须等于已上传的字节数），请求体为文件内容
+func (g *APIGateway) handleUploadChunk(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	if c.ContentType() != tusContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tusContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(400, gin.H{"error": "Upload-Offset header must be a non-negative integer"})
+		return
+	}
+
+	upload, err := g.storageClient.UploadChunk(c.Request.Context())
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	// 第一条消息携带会话信息，空请求体也发送一次以便返回当前进度
+	req := &storage.UploadChunkRequest{
+		SessionId: c.Param("id"),
+		UserId:    c.GetString("user_id"),
+		Offset:    offset,
+	}
+	buffer := make([]byte, uploadChunkSize)
+	for {
+		n, readErr := io.ReadFull(c.Request.Body, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			// 客户端连接中断，结束发送以提交已接收的内容
+			break
+		}
+		req.Chunk = buffer[:n]
+		if err := upload.Send(req); err != nil {
+			// 服务端已中止写入，错误原因由CloseAndRecv返回
+			break
+		}
+		if readErr != nil {
+			break
+		}
+		req = &storage.UploadChunkRequest{}
+	}
+
+	session, err := upload.CloseAndRecv()
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	writeUploadHeaders(c, session)
+	c.Status(http.StatusNoContent)
+}
+
+// 处理完成上传
+// 请求体：{"sha256": "十六进制校验和"}，也可以使用请求头Upload-Checksum: sha256 <base64校验和>
+func (g *APIGateway) handleFinalizeUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	checksum, err := uploadChecksum(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.storageClient.FinalizeUploadSession(c.Request.Context(), &storage.FinalizeUploadSessionRequest{
+		SessionId: c.Param("id"),
+		UserId:    c.GetString("user_id"),
+		Sha256:    checksum,
+	})
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	g.attachUploadedFile(c, resp.FileInfo.Metadata["transaction_id"], resp.FileInfo)
+}
+
+// 处理取消上传
+func (g *APIGateway) handleCancelUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	_, err := g.storageClient.CancelUploadSession(c.Request.Context(), &storage.UploadSessionRequest{
+		SessionId: c.Param("id"),
+		UserId:    c.GetString("user_id"),
+	})
+	if err != nil {
+		g.writeTusError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeTusError 写入断点续传错误，写入位置不匹配按tus协议返回409
+func (g *APIGateway) writeTusError(c *gin.Context, err error) {
+	if status.Code(err) == codes.FailedPrecondition {
+		c.JSON(http.StatusConflict, gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+	g.writeGRPCError(c, err)
+}
+
+// writeUploadHeaders 写入上传进度相关的响应头
+func writeUploadHeaders(c *gin.Context, session *storage.UploadSession) {
+	c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(session.Size, 10))
+	if expiresAt, err := time.Parse(time.RFC3339, session.ExpiresAt); err == nil {
+		c.Header("Upload-Expires", expiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseUploadMetadata 解析tus协议的Upload-Metadata请求头
+func parseUploadMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("invalid Upload-Metadata header")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("invalid Upload-Metadata value for " + key)
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+// uploadChecksum 读取完成上传时提交的SHA-256校验和，返回十六进制字符串
+func uploadChecksum(c *gin.Context) (string, error) {
+	if header := c.GetHeader("Upload-Checksum"); header != "" {
+		algorithm, encoded, _ := strings.Cut(header, " ")
+		if algorithm != "sha256" {
+			return "", errors.New("only sha256 checksums are supported")
+		}
+		sum, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", errors.New("invalid Upload-Checksum header")
+		}
+		return hex.EncodeToString(sum), nil
+	}
+
+	var body struct {
+		SHA256 string `json:"sha256"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.SHA256 == "" {
+		return "", errors.New("sha256 checksum is required")
+	}
+	return body.SHA256, nil
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/business"
+	configpb "github.com/fishdivinity/BeeCount-Cloud/common/proto/config"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultUploadURLPrefix 配置服务不可用时签名下载URL使用的路径前缀
+	defaultUploadURLPrefix = "/uploads"
+	// configRequestTimeout 从配置服务读取配置的超时时间
+	configRequestTimeout = 5 * time.Second
+)
+
+// uploadURLPrefix 从配置服务读取storage.local.url_prefix，签名下载URL挂载在该路径下
+func (g *APIGateway) uploadURLPrefix() string {
+	if g.configClient == nil {
+		return defaultUploadURLPrefix
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), configRequestTimeout)
+	defer cancel()
+	resp, err := g.configClient.GetConfig(ctx, &configpb.GetConfigRequest{Keys: []string{"storage.local.url_prefix"}})
+	if err != nil {
+		log.Printf("Failed to load storage.local.url_prefix, using %s: %v", defaultUploadURLPrefix, err)
+		return defaultUploadURLPrefix
+	}
+	item, ok := resp.Configs["storage.local.url_prefix"]
+	if !ok || strings.Trim(item.Value, "/") == "" {
+		return defaultUploadURLPrefix
+	}
+	return "/" + strings.Trim(item.Value, "/")
+}
+
+// 处理生成附件的签名下载URL
+// 签名URL无需认证即可在有效期内下载，可直接用于<img>等无法携带Authorization头的场景
+// 查询参数：variant（可选，thumbnail或medium），expires_in（可选，有效期秒数，不能超过配置的有效期）
+func (g *APIGateway) handleGetAttachmentURL(c *gin.Context) {
+	var expiresIn int64
+	if value := c.Query("expires_in"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(400, gin.H{"error": "expires_in must be a positive integer"})
+			return
+		}
+		expiresIn = n
+	}
+
+	// 附件可能由共享账本的其他成员上传，先校验访问权限并获取文件所有者
+	attachment, err := g.businessClient.GetAttachment(c.Request.Context(), &business.AttachmentRequest{
+		UserId: c.GetString("user_id"),
+		FileId: c.Param("id"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	resp, err := g.storageClient.CreateSignedURL(c.Request.Context(), &storage.CreateSignedURLRequest{
+		FileId:    attachment.FileId,
+		UserId:    attachment.UserId,
+		Variant:   c.Query("variant"),
+		ExpiresIn: expiresIn,
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// 处理签名URL下载
+// 不需要认证，由存储服务校验签名和有效期后以文件所有者的身份下载
+// 查询参数：expires（过期时间，Unix秒），signature（签名），variant（可选）
+func (g *APIGateway) handleSignedDownload(c *gin.Context) {
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || c.Query("signature") == "" {
+		c.JSON(403, gin.H{"error": "Missing or invalid URL signature"})
+		return
+	}
+
+	variant := c.Query("variant")
+	info, err := g.storageClient.VerifySignedURL(c.Request.Context(), &storage.VerifySignedURLRequest{
+		FileId:    c.Param("file_id"),
+		Variant:   variant,
+		Expires:   expires,
+		Signature: c.Query("signature"),
+	})
+	if err != nil {
+		g.writeGRPCError(c, err)
+		return
+	}
+
+	g.streamStorageFile(c, info.Id, info.UserId, variant, "inline")
+}
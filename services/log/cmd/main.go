@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	logpb "github.com/fishdivinity/BeeCount-Cloud/common/proto/log"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
@@ -24,23 +26,34 @@ func main() {
 	// 初始化日志服务
 	logService := internal.NewLogService()
 
+	// 创建通信抽象层实例
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取配置，配置服务不可用时使用默认值
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(), logConfigKeys...)
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+	logConfig, err := loadLogConfig(configClient)
+	if err != nil {
+		log.Fatalf("Failed to load log config: %v", err)
+	}
+
 	// 配置日志服务
-	if err := logService.Configure(internal.LogConfig{
-		Level:  "info",
-		Format: "json",
-		Output: "stdout",
-		FileConfig: internal.FileConfig{
-			Path:           "./logs/app.log",
-			MaxSize:        10,
-			MaxBackups:     100,
-			MaxAge:         28,
-			Compress:       true,
-			MaxTotalSizeGB: 1,
-		},
-	}); err != nil {
+	if err := logService.Configure(logConfig); err != nil {
 		log.Fatalf("Failed to configure log service: %v", err)
 	}
 
+	// 日志级别实时生效，输出方式和文件配置需要重启服务
+	configClient.OnChange("log.level", logService.SetLevel)
+	configClient.RequireRestart(logConfigKeys[1:]...)
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
+
 	// 创建gRPC服务器
 	grpcServer := grpc.NewServer()
 
@@ -50,9 +63,6 @@ func main() {
 	// 注册健康检查服务
 	common.RegisterHealthCheckServiceServer(grpcServer, logService)
 
-	// 创建通信抽象层实例
-	trans := transport.NewTransportWithFallback()
-
 	// 确定服务地址
 	address := *socketPath
 	if address == "" {
@@ -89,6 +99,50 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down LogService...")
+	cancelWatch()
 	grpcServer.GracefulStop()
 	log.Println("LogService exited")
 }
+
+// logConfigKeys 日志服务使用的配置项，第一项为可以实时生效的日志级别
+var logConfigKeys = []string{
+	"log.level",
+	"log.format",
+	"log.output",
+	"log.file.path",
+	"log.file.max_size",
+	"log.file.max_backups",
+	"log.file.max_age",
+	"log.file.compress",
+	"log.file.max_total_size_gb",
+}
+
+// loadLogConfig 从配置服务的配置生成日志配置，未设置的配置项使用默认值
+func loadLogConfig(client *configclient.Client) (internal.LogConfig, error) {
+	cfg := internal.LogConfig{
+		Level:  client.String("log.level", "info"),
+		Format: client.String("log.format", "json"),
+		Output: client.String("log.output", "stdout"),
+		FileConfig: internal.FileConfig{
+			Path: client.String("log.file.path", "./logs/app.log"),
+		},
+	}
+
+	var err error
+	if cfg.FileConfig.MaxSize, err = client.Int("log.file.max_size", 10); err != nil {
+		return cfg, err
+	}
+	if cfg.FileConfig.MaxBackups, err = client.Int("log.file.max_backups", 100); err != nil {
+		return cfg, err
+	}
+	if cfg.FileConfig.MaxAge, err = client.Int("log.file.max_age", 28); err != nil {
+		return cfg, err
+	}
+	if cfg.FileConfig.Compress, err = client.Bool("log.file.compress", true); err != nil {
+		return cfg, err
+	}
+	if cfg.FileConfig.MaxTotalSizeGB, err = client.Int("log.file.max_total_size_gb", 1); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
@@ -129,6 +129,22 @@ func (s *LogService) initFileOutput() error {
 	return nil
 }
 
+// SetLevel 修改日志级别，用于配置变更时实时生效
+func (s *LogService) SetLevel(level string) error {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "error", "fatal":
+	default:
+		return fmt.Errorf("invalid log level: %s", level)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config.Level = level
+	setLogLevel(level)
+	return nil
+}
+
 // setLogLevel 设置日志级别
 func setLogLevel(level string) {
 	switch strings.ToLower(level) {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,7 +9,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
 	"github.com/fishdivinity/BeeCount-Cloud/common/transport"
@@ -24,14 +27,69 @@ func main() {
 	// 初始化存储服务
 	storageService := internal.NewStorageService()
 
-	// 配置本地存储
-	if err := storageService.ConfigureLocalStorage(internal.LocalStorageConfig{
-		Path:      "./data/uploads",
-		URLPrefix: "/uploads",
+	// 创建通信抽象层实例
+	trans := transport.NewTransportWithFallback()
+
+	// 从配置服务读取存储配置，配置服务不可用时使用本地存储
+	configClient, err := configclient.Dial(trans.DefaultAddress("config"), trans.NewDialer(), internal.StorageConfigKeys...)
+	if err != nil {
+		log.Fatalf("Failed to create config client: %v", err)
+	}
+	defer configClient.Close()
+	if err := configClient.Load(context.Background()); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+	storageConfig, err := internal.LoadStorageConfig(configClient, internal.StorageConfig{
+		Active: "local",
+		Local: internal.LocalStorageConfig{
+			Path:      "./data/uploads",
+			URLPrefix: "/uploads",
+		},
+		MaxFileSize:      5 << 20,
+		AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		SessionPath:      "./data/upload_sessions",
+		ExportRetention:  7 * 24 * time.Hour,
+		SignedURL:        internal.SignedURLConfig{Expiry: 15 * time.Minute},
+		Scrub:            internal.ScrubConfig{Interval: 24 * time.Hour, Action: "quarantine"},
+	})
+	if err != nil {
+		log.Printf("Failed to load storage config, using defaults: %v", err)
+	}
+
+	// 存储配置只在启动时读取，变化后需要重启服务
+	configClient.RequireRestart(internal.StorageConfigKeys...)
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	go configClient.Watch(watchCtx)
+
+	// 配置存储后端
+	if err := storageService.ConfigureStorage(storageConfig); err != nil {
+		log.Fatalf("Failed to configure storage: %v", err)
+	}
+	if storageConfig.Encryption.MasterKey == "" && storageConfig.Encryption.MasterKeyFile == "" {
+		log.Printf("No storage master key configured, new files will be stored unencrypted")
+	}
+	if storageConfig.SignedURL.Secret == "" {
+		log.Printf("No signed URL secret configured, signed URLs will become invalid after restart")
+	}
+
+	// 配置文件元数据数据库（SQLite3）
+	if err := storageService.ConfigureDatabase(internal.SQLiteConfig{
+		Path: "./data/storage.db",
 	}); err != nil {
-		log.Fatalf("Failed to configure local storage: %v", err)
+		log.Fatalf("Failed to configure database: %v", err)
+	}
+
+	// 初始化数据库
+	if err := storageService.InitDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// 启动后台任务（清理过期的上传会话和导出文件、巡检存储后端）
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	go storageService.RunSessionCleanup(jobCtx)
+	go storageService.RunExportCleanup(jobCtx)
+	go storageService.RunScrubber(jobCtx)
+
 	// 创建gRPC服务器
 	grpcServer := grpc.NewServer()
 
@@ -41,9 +99,6 @@ func main() {
 	// 注册健康检查服务
 	common.RegisterHealthCheckServiceServer(grpcServer, storageService)
 
-	// 创建通信抽象层实例
-	trans := transport.NewTransportWithFallback()
-
 	// 确定服务地址
 	address := *socketPath
 	if address == "" {
@@ -80,6 +135,8 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down StorageService...")
+	cancelJobs()
+	cancelWatch()
 	grpcServer.GracefulStop()
 	log.Println("StorageService exited")
 }
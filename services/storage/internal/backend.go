@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 存储后端类型，对应配置项storage.active
+const (
+	backendLocal = "local"
+	backendS3    = "s3"
+)
+
+// Backend 存储后端接口，key为斜杠分隔的相对路径形式的对象键，例如"blobs/ab/cd/{sha256}"
+// 对象不存在时Get和Delete返回的错误满足errors.Is(err, fs.ErrNotExist)，key无效时返回的错误满足errors.Is(err, errInvalidKey)
+type Backend interface {
+	// Put 从r流式读取内容写入key，返回写入的字节数；失败时不保留部分内容
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get 打开key对应内容从offset开始的读取流，length大于0时最多读取length字节，调用方负责关闭
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete 删除key对应的内容
+	Delete(ctx context.Context, key string) error
+	// Move 将from对应的内容移动到to，to已存在时覆盖
+	Move(ctx context.Context, from, to string) error
+	// List 遍历键以prefix开头的全部对象，prefix为空时遍历全部对象；fn返回错误时停止遍历并返回该错误
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// ObjectInfo 存储后端中对象的基本信息
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// StorageConfig 存储配置
+type StorageConfig struct {
+	Active           string // 新文件写入的后端：local或s3
+	Local            LocalStorageConfig
+	S3               S3Config
+	MaxFileSize      int64         // 上传文件的最大大小（字节），0表示不限制
+	AllowedFileTypes []string      // 允许上传的MIME类型，为空表示不限制
+	SessionPath      string        // 断点续传上传会话的暂存目录，为空时使用系统临时目录
+	UserQuota        int64         // 每个用户的存储配额（字节），0表示不限制
+	ExportRetention  time.Duration // 账本导出文件的保留时间，到期后由后台任务删除，0表示不删除
+	Encryption       EncryptionConfig
+	SignedURL        SignedURLConfig
+	Scrub            ScrubConfig
+}
+
+// localBackend 本地文件系统存储后端
+type localBackend struct {
+	root string // 解析过符号链接的绝对路径
+}
+
+// newLocalBackend 创建本地存储后端，确保存储目录存在
+func newLocalBackend(config LocalStorageConfig) (*localBackend, error) {
+	if err := os.MkdirAll(config.Path, 0755); err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, err
+	}
+	return &localBackend{root: root}, nil
+}
+
+// path 返回key在本地文件系统中的路径，并确认路径位于存储目录之内
+// 存储目录中的符号链接可能指向目录之外，因此检查已存在的最深一级上级目录解析后的位置，且不跟随文件本身的符号链接
+func (b *localBackend) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	if !within(b.root, path) {
+		return "", fmt.Errorf("%w: %q escapes the storage directory", errInvalidKey, key)
+	}
+
+	for dir := filepath.Dir(path); dir != b.root; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			if !within(b.root, resolved) {
+				return "", fmt.Errorf("%w: %q escapes the storage directory", errInvalidKey, key)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("%w: %q is a symbolic link", errInvalidKey, key)
+	}
+	return path, nil
+}
+
+// Put 写入文件
+func (b *localBackend) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return n, nil
+}
+
+// Get 打开文件并定位到offset
+func (b *localBackend) Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if length <= 0 {
+		return file, nil
+	}
+	return limitedReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// limitedReadCloser 限制读取长度并保留底层的Close
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Delete 删除文件
+func (b *localBackend) Delete(ctx context.Context, key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Move 重命名文件，目标目录不存在时自动创建
+func (b *localBackend) Move(ctx context.Context, from, to string) error {
+	source, err := b.path(from)
+	if err != nil {
+		return err
+	}
+	target, err := b.path(to)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.Rename(source, target)
+}
+
+// List 遍历存储目录中的文件，不跟随符号链接
+func (b *localBackend) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	return filepath.WalkDir(b.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		return fn(ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+	})
+}
+
+// isNotExist 判断后端返回的错误是否表示对象不存在
+func isNotExist(err error) bool {
+	return err != nil && errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// blobPrefix 内容对象的键前缀，对象位于存储目录的子目录中，不会被当作历史文件导入
+const blobPrefix = "blobs"
+
+// Blob 按内容SHA-256寻址的存储对象，内容相同的文件共用一个对象
+type Blob struct {
+	Checksum    string    `gorm:"type:varchar(64);primaryKey"`
+	Backend     string    `gorm:"type:varchar(20);not null"`
+	StoragePath string    `gorm:"type:varchar(512);not null"`
+	Size        int64     `gorm:"not null"`
+	RefCount    int64     `gorm:"not null;default:0"` // 引用该对象的文件数，减到0时删除对象
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// blobKey 返回内容对象的键，按校验和的前两级分目录，避免单个目录下的文件过多
+func blobKey(checksum string) string {
+	return path.Join(blobPrefix, checksum[:2], checksum[2:4], checksum)
+}
+
+// stagingKey 返回上传过程中暂存内容的键，写入完成得到校验和后再移动到blobKey
+func stagingKey() string {
+	return path.Join(blobPrefix, "staging", uuid.New().String())
+}
+
+// commitFile 将暂存的上传内容保存为文件，fileInfo.Checksum必须是暂存内容明文的SHA-256
+// 相同内容的对象已存在时删除暂存内容并引用已有对象，否则将暂存内容移动为新对象，key不为空时记录加密该对象的数据密钥
+// 保存前按当前用量再次检查存储配额，避免同一用户的并发上传超出配额
+// extra不为空时在保存文件元数据的同一事务中执行
+func (s *StorageService) commitFile(ctx context.Context, fileInfo *FileInfo, backend Backend, staged string, key *objectKey, extra func(tx *gorm.DB) error) error {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	discard := func() {
+		backend.Delete(context.Background(), staged)
+	}
+
+	policy, err := s.uploadPolicyFor(fileInfo.UserID, fileInfo.Export)
+	if err == nil {
+		err = policy.checkSize(fileInfo.Size)
+	}
+	if err != nil {
+		discard()
+		return err
+	}
+
+	var blob Blob
+	created := false
+	err = s.db.First(&blob, "checksum = ?", fileInfo.Checksum).Error
+	switch err {
+	case nil:
+		discard()
+	case gorm.ErrRecordNotFound:
+		blob = Blob{
+			Checksum:    fileInfo.Checksum,
+			Backend:     fileInfo.Backend,
+			StoragePath: blobKey(fileInfo.Checksum),
+			Size:        fileInfo.Size,
+		}
+		if err := backend.Move(ctx, staged, blob.StoragePath); err != nil {
+			discard()
+			return status.Errorf(codes.Internal, "Failed to write file: %v", err)
+		}
+		created = true
+	default:
+		discard()
+		return status.Errorf(codes.Internal, "Failed to query blob: %v", err)
+	}
+
+	// 记录文件元数据，失败时删除新写入的对象
+	fileInfo.Backend = blob.Backend
+	fileInfo.StoragePath = blob.StoragePath
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := acquireBlob(tx, &blob, created); err != nil {
+			return err
+		}
+		if created {
+			if err := key.save(tx, blob.Backend, blob.StoragePath); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(fileInfo).Error; err != nil {
+			return err
+		}
+		if extra != nil {
+			return extra(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		if created {
+			backend.Delete(context.Background(), blob.StoragePath)
+		}
+		return status.Errorf(codes.Internal, "Failed to save file metadata: %v", err)
+	}
+	return nil
+}
+
+// acquireBlob 增加对象的引用计数，created为true时创建对象记录
+func acquireBlob(tx *gorm.DB, blob *Blob, created bool) error {
+	if created {
+		blob.RefCount = 1
+		return tx.Create(blob).Error
+	}
+	return tx.Model(&Blob{}).Where("checksum = ?", blob.Checksum).
+		Update("ref_count", gorm.Expr("ref_count + 1")).Error
+}
+
+// deleteFileContent 删除文件元数据并释放引用的对象，最后一个引用删除后才删除对象
+// 内容寻址之前保存且未能迁移的文件没有对象记录，直接删除
+func (s *StorageService) deleteFileContent(ctx context.Context, fileInfo *FileInfo, backend Backend) error {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	var blob Blob
+	err := s.db.First(&blob, "checksum = ? AND storage_path = ?", fileInfo.Checksum, fileInfo.StoragePath).Error
+	if err == gorm.ErrRecordNotFound {
+		deleteErr := backend.Delete(ctx, fileInfo.StoragePath)
+		if deleteErr != nil && !isNotExist(deleteErr) {
+			return status.Errorf(codes.Internal, "Failed to delete file: %v", deleteErr)
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := deleteDataKey(tx, fileInfo.Backend, fileInfo.StoragePath); err != nil {
+				return err
+			}
+			return tx.Delete(fileInfo).Error
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "Failed to delete file metadata: %v", err)
+		}
+		return missingContentError(deleteErr)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to query blob: %v", err)
+	}
+
+	blob.RefCount--
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(fileInfo).Error; err != nil {
+			return err
+		}
+		if blob.RefCount > 0 {
+			return tx.Model(&Blob{}).Where("checksum = ?", blob.Checksum).
+				Update("ref_count", gorm.Expr("ref_count - 1")).Error
+		}
+		if err := deleteDataKey(tx, blob.Backend, blob.StoragePath); err != nil {
+			return err
+		}
+		return tx.Delete(&blob).Error
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to delete file metadata: %v", err)
+	}
+
+	// 元数据已删除，对象删除失败时只会留下不再被引用的对象，由巡检清理
+	if blob.RefCount <= 0 {
+		err := backend.Delete(ctx, blob.StoragePath)
+		if err != nil && !isNotExist(err) {
+			log.Printf("Failed to delete blob %s: %v", blob.Checksum, err)
+		}
+		return missingContentError(err)
+	}
+	return nil
+}
+
+// missingContentError 删除文件时内容对象已不存在，元数据已删除，但不能报告删除成功
+func missingContentError(err error) error {
+	if isNotExist(err) {
+		return status.Errorf(codes.NotFound, "File content not found, metadata removed")
+	}
+	return nil
+}
+
+// migrateBlobs 将内容寻址之前保存的文件迁移为内容对象，内容相同的文件只保留一份
+func (s *StorageService) migrateBlobs(ctx context.Context) error {
+	var files []FileInfo
+	if err := s.db.Where("checksum <> '' AND storage_path NOT LIKE ?", blobPrefix+"/%").
+		Order("created_at").Find(&files).Error; err != nil {
+		return err
+	}
+
+	migrated := 0
+	for i := range files {
+		file := &files[i]
+		backend, err := s.backendFor(file)
+		if err != nil {
+			log.Printf("Skipping blob migration for file %s: %v", file.ID, err)
+			continue
+		}
+
+		var blob Blob
+		created := false
+		err = s.db.First(&blob, "checksum = ?", file.Checksum).Error
+		switch err {
+		case nil:
+		case gorm.ErrRecordNotFound:
+			blob = Blob{
+				Checksum:    file.Checksum,
+				Backend:     file.Backend,
+				StoragePath: blobKey(file.Checksum),
+				Size:        file.Size,
+			}
+			if err := backend.Move(ctx, file.StoragePath, blob.StoragePath); err != nil {
+				log.Printf("Failed to migrate file %s: %v", file.ID, err)
+				continue
+			}
+			created = true
+		default:
+			return err
+		}
+
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := acquireBlob(tx, &blob, created); err != nil {
+				return err
+			}
+			return tx.Model(&FileInfo{}).Where("id = ?", file.ID).Updates(map[string]interface{}{
+				"backend":      blob.Backend,
+				"storage_path": blob.StoragePath,
+			}).Error
+		})
+		if err != nil {
+			if created {
+				backend.Move(ctx, blob.StoragePath, file.StoragePath)
+			}
+			return err
+		}
+		// 内容已由已有对象保存，删除重复的副本
+		if !created {
+			if err := backend.Delete(ctx, file.StoragePath); err != nil && !isNotExist(err) {
+				log.Printf("Failed to delete duplicate of file %s: %v", file.ID, err)
+			}
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d files to content-addressed storage", migrated)
+	}
+	return nil
+}
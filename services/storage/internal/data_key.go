@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataKey 存储对象的数据密钥，由主密钥包装后保存
+// 数据密钥保存在元数据数据库中而不是对象里，轮换主密钥时只需重新包装数据密钥，不需要改写本地文件或S3对象
+// 没有数据密钥记录的对象是在启用加密之前以明文保存的
+type DataKey struct {
+	Backend     string    `gorm:"type:varchar(20);primaryKey"`
+	StoragePath string    `gorm:"type:varchar(512);primaryKey"`
+	KeyID       string    `gorm:"type:varchar(16);not null;index"` // 包装数据密钥的主密钥ID
+	WrappedKey  []byte    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// objectKey 写入单个对象时使用的数据密钥，nil表示以明文写入
+type objectKey struct {
+	plain   []byte
+	keyID   string
+	wrapped []byte
+}
+
+// newObjectKey 生成新的数据密钥并用当前主密钥包装，未配置主密钥时返回nil
+func (s *StorageService) newObjectKey() (*objectKey, error) {
+	ring := s.currentKeyring()
+	if ring == nil {
+		return nil, nil
+	}
+	plain := make([]byte, dataKeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, err
+	}
+	keyID, wrapped, err := ring.wrap(plain)
+	if err != nil {
+		return nil, err
+	}
+	return &objectKey{plain: plain, keyID: keyID, wrapped: wrapped}, nil
+}
+
+// currentKeyring 返回当前的主密钥集合
+func (s *StorageService) currentKeyring() *keyring {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keyring
+}
+
+// seal 返回写入存储后端的内容，k为nil时原样返回
+func (k *objectKey) seal(r io.Reader) (io.Reader, error) {
+	if k == nil {
+		return r, nil
+	}
+	return newEncryptReader(r, k.plain)
+}
+
+// save 在事务中记录保存在backend中storagePath处对象的数据密钥，k为nil时不做任何操作
+func (k *objectKey) save(tx *gorm.DB, backend, storagePath string) error {
+	if k == nil {
+		return nil
+	}
+	return tx.Create(&DataKey{
+		Backend:     backend,
+		StoragePath: storagePath,
+		KeyID:       k.keyID,
+		WrappedKey:  k.wrapped,
+	}).Error
+}
+
+// deleteDataKey 在事务中删除对象的数据密钥记录
+func deleteDataKey(tx *gorm.DB, backend, storagePath string) error {
+	return tx.Where("backend = ? AND storage_path = ?", backend, storagePath).Delete(&DataKey{}).Error
+}
+
+// openObject 打开文件内容从offset开始的读取流，length大于0时最多读取length字节
+// 加密的对象只读取覆盖请求范围的分块并在读取时解密
+func (s *StorageService) openObject(ctx context.Context, fileInfo *FileInfo, offset, length int64) (io.ReadCloser, error) {
+	backend, err := s.backendFor(fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var record DataKey
+	err = s.db.First(&record, "backend = ? AND storage_path = ?", fileInfo.Backend, fileInfo.StoragePath).Error
+	if err == gorm.ErrRecordNotFound {
+		return backend.Get(ctx, fileInfo.StoragePath, offset, length)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data key: %w", err)
+	}
+	ring := s.currentKeyring()
+	if ring == nil {
+		return nil, errors.New("file is encrypted but no master key is configured")
+	}
+	dataKey, err := ring.unwrap(record.KeyID, record.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	// 计算覆盖请求范围的分块
+	size := fileInfo.Size
+	if length <= 0 {
+		length = size - offset
+	}
+	first := offset / encChunkSize
+	end := first
+	if length > 0 {
+		end = (offset + length - 1) / encChunkSize
+	}
+	chunkStart := int64(encHeaderSize) + first*(encChunkSize+encTagSize)
+	chunkEnd := min(int64(encHeaderSize)+(end+1)*(encChunkSize+encTagSize), encryptedSize(size))
+
+	// 从第一块开始读取时文件头与分块一起读取，否则单独读取文件头
+	start := chunkStart
+	if first == 0 {
+		start = 0
+	}
+	reader, err := backend.Get(ctx, fileInfo.StoragePath, start, chunkEnd-start)
+	if err != nil {
+		return nil, err
+	}
+	var prefix []byte
+	if first == 0 {
+		prefix, err = readEncryptionHeader(reader)
+	} else {
+		prefix, err = s.readObjectHeader(ctx, backend, fileInfo.StoragePath)
+	}
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	decrypted, err := newDecryptReader(reader, dataKey, prefix, size, first, end)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, decrypted, offset-first*encChunkSize); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return limitedReadCloser{Reader: io.LimitReader(decrypted, length), Closer: reader}, nil
+}
+
+// readObjectHeader 单独读取加密对象的文件头
+func (s *StorageService) readObjectHeader(ctx context.Context, backend Backend, key string) ([]byte, error) {
+	reader, err := backend.Get(ctx, key, 0, int64(encHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return readEncryptionHeader(reader)
+}
+
+// readEncryptionHeader 读取加密对象的文件头，返回nonce前缀
+func readEncryptionHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, encHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if string(header[:len(encMagic)]) != encMagic {
+		return nil, errors.New("object is not in the encrypted format")
+	}
+	return header[len(encMagic):], nil
+}
+
+// rewrapDataKeys 用当前主密钥重新包装由轮换前的主密钥包装的数据密钥，对象内容不需要重新加密
+// 无法解开的数据密钥保持不变，配置了对应的主密钥后下次启动时再处理
+func (s *StorageService) rewrapDataKeys() error {
+	ring := s.currentKeyring()
+	if ring == nil {
+		return nil
+	}
+
+	var records []DataKey
+	if err := s.db.Where("key_id <> ?", ring.current).Find(&records).Error; err != nil {
+		return err
+	}
+	rewrapped, failed := 0, 0
+	for _, record := range records {
+		dataKey, err := ring.unwrap(record.KeyID, record.WrappedKey)
+		if err != nil {
+			failed++
+			continue
+		}
+		keyID, wrapped, err := ring.wrap(dataKey)
+		if err != nil {
+			return err
+		}
+		if err := s.db.Model(&DataKey{}).
+			Where("backend = ? AND storage_path = ? AND key_id = ?", record.Backend, record.StoragePath, record.KeyID).
+			Updates(map[string]interface{}{"key_id": keyID, "wrapped_key": wrapped}).Error; err != nil {
+			return err
+		}
+		rewrapped++
+	}
+
+	if rewrapped > 0 {
+		log.Printf("Re-wrapped %d data keys with master key %s", rewrapped, ring.current)
+	}
+	if failed > 0 {
+		log.Printf("Failed to unwrap %d data keys: their master keys are not configured", failed)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 加密对象的格式：文件头（魔数 + 随机的nonce前缀），之后是按encChunkSize分块加密的内容
+// 每块使用AES-256-GCM加密，nonce由nonce前缀、块序号和末块标记组成，
+// 因此分块不能被重排、截断或替换到其他对象中
+const (
+	// encMagic 加密对象的魔数，包含格式版本
+	encMagic = "BCE1"
+	// encPrefixSize nonce前缀的字节数
+	encPrefixSize = 7
+	// encHeaderSize 加密对象文件头的字节数
+	encHeaderSize = len(encMagic) + encPrefixSize
+	// encChunkSize 每块明文的字节数
+	encChunkSize = 64 << 10
+	// encTagSize 每块的GCM认证标签字节数
+	encTagSize = 16
+	// dataKeySize 数据密钥和主密钥的字节数（AES-256）
+	dataKeySize = 32
+)
+
+// dataKeyAAD 包装数据密钥时使用的附加认证数据
+var dataKeyAAD = []byte("beecount-storage-data-key")
+
+// EncryptionConfig 存储加密配置，未配置主密钥时以明文保存文件
+type EncryptionConfig struct {
+	MasterKey     string   // base64编码的32字节主密钥
+	MasterKeyFile string   // 主密钥文件，MasterKey为空时使用；第一行为当前主密钥，其余各行为轮换前的主密钥
+	PreviousKeys  []string // 轮换前的主密钥（base64），仅用于解开尚未重新包装的数据密钥
+}
+
+// keyring 主密钥集合，新的数据密钥只用当前主密钥包装
+type keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// loadKeyring 加载主密钥，未配置主密钥时返回nil
+func loadKeyring(config EncryptionConfig) (*keyring, error) {
+	encoded := []string{config.MasterKey}
+	if config.MasterKey == "" && config.MasterKeyFile != "" {
+		lines, err := readKeyFile(config.MasterKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = lines
+	}
+	if encoded[0] == "" {
+		return nil, nil
+	}
+	encoded = append(encoded, config.PreviousKeys...)
+
+	ring := &keyring{keys: make(map[string]cipher.AEAD)}
+	for i, value := range encoded {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(key) != dataKeySize {
+			return nil, fmt.Errorf("master key must be %d bytes encoded in base64", dataKeySize)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		id := masterKeyID(key)
+		if i == 0 {
+			ring.current = id
+		}
+		ring.keys[id] = aead
+	}
+	return ring, nil
+}
+
+// readKeyFile 读取主密钥文件中的非空行
+func readKeyFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("master key file %s is empty", path)
+	}
+	return lines, nil
+}
+
+// masterKeyID 主密钥的标识，取SHA-256的前8字节，不泄露密钥本身
+func masterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// newGCM 创建AES-GCM实例
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// wrap 用当前主密钥加密数据密钥，返回主密钥ID和nonce与密文的拼接
+func (k *keyring) wrap(dataKey []byte) (string, []byte, error) {
+	aead := k.keys[k.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return k.current, aead.Seal(nonce, nonce, dataKey, dataKeyAAD), nil
+}
+
+// unwrap 用keyID对应的主密钥解开数据密钥
+func (k *keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("master key %s is not configured", keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped data key is too short")
+	}
+	nonceSize := aead.NonceSize()
+	return aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], dataKeyAAD)
+}
+
+// encryptedSize 返回明文大小为size的加密对象大小，空内容也包含一个空的末块
+func encryptedSize(size int64) int64 {
+	chunks := max(1, (size+encChunkSize-1)/encChunkSize)
+	return int64(encHeaderSize) + size + chunks*encTagSize
+}
+
+// chunkNonce 返回第index块的nonce
+func chunkNonce(prefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encPrefixSize:], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptReader 从明文读取流生成加密对象的读取流
+type encryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	index   uint32
+	plain   []byte
+	peek    []byte // 预读的1字节，用于判断当前块是否为末块
+	out     []byte // 待输出的文件头或密文
+	sealed  []byte
+	started bool
+	done    bool
+}
+
+// newEncryptReader 使用数据密钥加密src
+func newEncryptReader(src io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, encPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		src:    src,
+		aead:   aead,
+		prefix: prefix,
+		plain:  make([]byte, encChunkSize),
+		peek:   make([]byte, 0, 1),
+		out:    append([]byte(encMagic), prefix...),
+		sealed: make([]byte, 0, encChunkSize+encTagSize),
+	}, nil
+}
+
+// Read 实现io.Reader
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// sealChunk 读取并加密下一块明文
+func (r *encryptReader) sealChunk() error {
+	n := copy(r.plain, r.peek)
+	m, err := io.ReadFull(r.src, r.plain[n:])
+	n += m
+
+	last := false
+	switch err {
+	case nil:
+		// 块已读满，预读1字节判断后面是否还有内容
+		k, err := io.ReadFull(r.src, r.peek[:1])
+		if err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+		r.peek = r.peek[:k]
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+		r.peek = r.peek[:0]
+	default:
+		return err
+	}
+
+	r.out = r.aead.Seal(r.sealed[:0], chunkNonce(r.prefix, r.index, last), r.plain[:n], nil)
+	r.index++
+	r.done = last
+	return nil
+}
+
+// decryptReader 解密加密对象中从第index块开始的连续分块
+type decryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	index  int64
+	end    int64 // 读取到的最后一块（包含）
+	last   int64 // 整个对象的末块
+	size   int64 // 整个对象的明文大小
+	buf    []byte
+	out    []byte
+}
+
+// newDecryptReader 创建解密读取流，src从第first块的密文开始，读取到第end块为止
+func newDecryptReader(src io.Reader, dataKey, prefix []byte, size, first, end int64) (*decryptReader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:    src,
+		aead:   aead,
+		prefix: prefix,
+		index:  first,
+		end:    end,
+		last:   max(1, (size+encChunkSize-1)/encChunkSize) - 1,
+		size:   size,
+		buf:    make([]byte, encChunkSize+encTagSize),
+	}, nil
+}
+
+// Read 实现io.Reader
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.index > r.end {
+			return 0, io.EOF
+		}
+		if err := r.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// openChunk 读取并解密下一块，密文长度由明文大小确定
+func (r *decryptReader) openChunk() error {
+	plainSize := int64(encChunkSize)
+	if r.index == r.last {
+		plainSize = r.size - r.index*encChunkSize
+	}
+	chunk := r.buf[:plainSize+encTagSize]
+	if _, err := io.ReadFull(r.src, chunk); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	plain, err := r.aead.Open(chunk[:0], chunkNonce(r.prefix, uint32(r.index), r.index == r.last), chunk, nil)
+	if err != nil {
+		return fmt.Errorf("encrypted chunk %d failed authentication", r.index)
+	}
+	r.out = plain
+	r.index++
+	return nil
+}
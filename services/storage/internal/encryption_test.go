@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+)
+
+func TestEncryptReaderRoundTrip(t *testing.T) {
+	dataKey := make([]byte, dataKeySize)
+	rand.Read(dataKey)
+
+	for _, size := range []int{0, 1, encChunkSize - 1, encChunkSize, encChunkSize + 1, 3*encChunkSize + 5} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		reader, err := newEncryptReader(bytes.NewReader(plain), dataKey)
+		if err != nil {
+			t.Fatalf("newEncryptReader: %v", err)
+		}
+		sealed, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("encrypt %d bytes: %v", size, err)
+		}
+		if int64(len(sealed)) != encryptedSize(int64(size)) {
+			t.Errorf("encrypted %d bytes into %d, want %d", size, len(sealed), encryptedSize(int64(size)))
+		}
+
+		prefix, err := readEncryptionHeader(bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatalf("readEncryptionHeader: %v", err)
+		}
+		last := max(1, (int64(size)+encChunkSize-1)/encChunkSize) - 1
+		decrypted, err := newDecryptReader(bytes.NewReader(sealed[encHeaderSize:]), dataKey, prefix, int64(size), 0, last)
+		if err != nil {
+			t.Fatalf("newDecryptReader: %v", err)
+		}
+		got, err := io.ReadAll(decrypted)
+		if err != nil {
+			t.Fatalf("decrypt %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("decrypted %d bytes do not match", size)
+		}
+
+		// 截断末块后无法通过认证
+		if size > 0 {
+			truncated := sealed[:len(sealed)-1]
+			decrypted, _ := newDecryptReader(bytes.NewReader(truncated[encHeaderSize:]), dataKey, prefix, int64(size), 0, last)
+			if _, err := io.ReadAll(decrypted); err == nil {
+				t.Errorf("truncated %d byte object decrypted without error", size)
+			}
+		}
+	}
+}
+
+func TestStorageServiceEncryptsAndRotatesKeys(t *testing.T) {
+	oldKey, newKey := testMasterKey(), testMasterKey()
+	root := filepath.Join(t.TempDir(), "uploads")
+	dbPath := filepath.Join(t.TempDir(), "storage.db")
+	newService := func(encryption EncryptionConfig) *StorageService {
+		t.Helper()
+		service := NewStorageService()
+		if err := service.ConfigureStorage(StorageConfig{
+			Active:     backendLocal,
+			Local:      LocalStorageConfig{Path: root},
+			Encryption: encryption,
+		}); err != nil {
+			t.Fatalf("ConfigureStorage: %v", err)
+		}
+		if err := service.ConfigureDatabase(SQLiteConfig{Path: dbPath}); err != nil {
+			t.Fatalf("ConfigureDatabase: %v", err)
+		}
+		if err := service.InitDatabase(); err != nil {
+			t.Fatalf("InitDatabase: %v", err)
+		}
+		return service
+	}
+	download := func(service *StorageService, info *storage.FileInfo, offset, length int64) ([]byte, error) {
+		stream := &fakeDownloadStream{ctx: context.Background()}
+		err := service.DownloadFile(&storage.DownloadFileRequest{FileId: info.Id, UserId: info.UserId, Offset: offset, Length: length}, stream)
+		return stream.data.Bytes(), err
+	}
+
+	content := append([]byte("%PDF-1.4\n"), make([]byte, 3*encChunkSize)...)
+	rand.Read(content[16:])
+	service := newService(EncryptionConfig{MasterKey: oldKey})
+	upload := &fakeUploadStream{ctx: context.Background(), requests: []*storage.UploadFileRequest{
+		{Filename: "receipt.pdf", UserId: "user-1", Chunk: content},
+	}}
+	if err := service.UploadFile(upload); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	info := upload.response.FileInfo
+
+	stored, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(info.StoragePath)))
+	if err != nil {
+		t.Fatalf("read stored object: %v", err)
+	}
+	if bytes.Contains(stored, content[16:1024]) || !bytes.HasPrefix(stored, []byte(encMagic)) {
+		t.Error("stored object is not encrypted")
+	}
+
+	// 轮换主密钥后重新包装数据密钥，对象内容保持不变
+	service = newService(EncryptionConfig{MasterKey: newKey, PreviousKeys: []string{oldKey}})
+	if after, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(info.StoragePath))); !bytes.Equal(after, stored) {
+		t.Error("rotation rewrote the stored object")
+	}
+	service = newService(EncryptionConfig{MasterKey: newKey})
+	got, err := download(service, info, 0, 0)
+	if err != nil {
+		t.Fatalf("DownloadFile after rotation: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("downloaded content does not match uploaded content")
+	}
+
+	// 跨越分块边界的范围读取，以及从中间分块开始的范围读取
+	for _, r := range [][2]int64{{encChunkSize - 10, encChunkSize + 20}, {2*encChunkSize + 5, 0}} {
+		got, err = download(service, info, r[0], r[1])
+		if err != nil {
+			t.Fatalf("DownloadFile range %v: %v", r, err)
+		}
+		end := int64(len(content))
+		if r[1] > 0 {
+			end = r[0] + r[1]
+		}
+		if !bytes.Equal(got, content[r[0]:end]) {
+			t.Errorf("downloaded range %v does not match uploaded content", r)
+		}
+	}
+
+	service = newService(EncryptionConfig{MasterKey: oldKey})
+	if _, err := download(service, info, 0, 0); err == nil {
+		t.Error("DownloadFile succeeded with a retired master key")
+	}
+}
+
+// testMasterKey 生成base64编码的随机主密钥
+func testMasterKey() string {
+	key := make([]byte, dataKeySize)
+	rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// exportCleanupInterval 清理过期导出文件的间隔
+const exportCleanupInterval = time.Hour
+
+// RunExportCleanup 定期删除超过保留时间的账本导出文件，直到ctx取消
+func (s *StorageService) RunExportCleanup(ctx context.Context) {
+	s.mu.RLock()
+	retention := s.config.ExportRetention
+	s.mu.RUnlock()
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(exportCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanupExpiredExports(ctx, time.Now().Add(-retention))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanupExpiredExports 删除before之前保存的导出文件
+func (s *StorageService) cleanupExpiredExports(ctx context.Context, before time.Time) {
+	var files []FileInfo
+	if err := s.db.Where("export = ? AND created_at < ?", true, before).Find(&files).Error; err != nil {
+		log.Printf("Failed to query expired exports: %v", err)
+		return
+	}
+
+	removed := 0
+	for i := range files {
+		backend, err := s.backendFor(&files[i])
+		if err == nil {
+			err = s.deleteFileContent(ctx, &files[i], backend)
+		}
+		if err != nil {
+			log.Printf("Failed to remove expired export %s: %v", files[i].ID, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("Removed %d expired exports", removed)
+	}
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCleanupExpiredExports(t *testing.T) {
+	ctx := context.Background()
+	service := newPolicyTestService(t)
+	upload := func(request *storage.UploadFileRequest) *storage.FileInfo {
+		t.Helper()
+		stream := &fakeUploadStream{ctx: ctx, requests: []*storage.UploadFileRequest{request}}
+		if err := service.UploadFile(stream); err != nil {
+			t.Fatalf("UploadFile %s: %v", request.Filename, err)
+		}
+		return stream.response.FileInfo
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	export := upload(&storage.UploadFileRequest{UserId: "user-1", Filename: "ledger.csv", Chunk: []byte("date,amount\n"), Export: true})
+	photo := upload(&storage.UploadFileRequest{UserId: "user-1", Filename: "photo.png", Chunk: png})
+
+	// 未到期的导出文件保留
+	service.cleanupExpiredExports(ctx, time.Now().Add(-time.Hour))
+	if _, err := service.GetFileInfo(ctx, &storage.GetFileInfoRequest{FileId: export.Id, UserId: "user-1"}); err != nil {
+		t.Fatalf("export removed before it expired: %v", err)
+	}
+
+	service.cleanupExpiredExports(ctx, time.Now().Add(time.Minute))
+	if _, err := service.GetFileInfo(ctx, &storage.GetFileInfoRequest{FileId: export.Id, UserId: "user-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expired export lookup = %v, want NotFound", err)
+	}
+	if _, err := service.GetFileInfo(ctx, &storage.GetFileInfoRequest{FileId: photo.Id, UserId: "user-1"}); err != nil {
+		t.Errorf("regular file removed with expired exports: %v", err)
+	}
+}
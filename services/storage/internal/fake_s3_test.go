@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 进程内的S3兼容服务，仅实现存储后端使用的路径风格接口
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	nextID  int
+
+	// 记录完成的分段上传次数，用于确认大文件走了分段上传
+	multipartCompleted int
+}
+
+// newFakeS3 启动fakeS3并返回指向它的S3配置
+func newFakeS3(t *testing.T) (*fakeS3, S3Config) {
+	t.Helper()
+	fake := &fakeS3{
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, S3Config{
+		Region:          "us-east-1",
+		Bucket:          "beecount",
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+		Endpoint:        server.URL,
+	}
+}
+
+// object 返回对象内容
+func (f *fakeS3) object(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	return data, ok
+}
+
+// objectCount 返回对象数
+func (f *fakeS3) objectCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.objects)
+}
+
+// pendingUploads 返回未完成的分段上传数
+func (f *fakeS3) pendingUploads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.uploads)
+}
+
+// ServeHTTP 实现http.Handler，路径格式为/{bucket}/{key}
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query())
+		return
+	}
+	if len(parts) != 2 || parts[1] == "" {
+		writeS3Error(w, http.StatusBadRequest, "InvalidRequest")
+		return
+	}
+	key := parts[1]
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		uploadID := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[uploadID] = make(map[int][]byte)
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, parts[0], key, uploadID)
+
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(r.Body)
+		upload[partNumber] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, partNumber))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(upload))
+		for number := range upload {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var buf bytes.Buffer
+		for _, number := range numbers {
+			buf.Write(upload[number])
+		}
+		f.objects[key] = buf.Bytes()
+		delete(f.uploads, query.Get("uploadId"))
+		f.multipartCompleted++
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>"complete"</ETag></CompleteMultipartUploadResult>`, parts[0], key)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		_, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+		data, ok := f.objects[sourceKey]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		f.objects[key] = data
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"object"</ETag></CopyObjectResult>`)
+
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		w.Header().Set("ETag", `"object"`)
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		statusCode := http.StatusOK
+		if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+			first, last, _ := strings.Cut(spec, "-")
+			start, _ := strconv.Atoi(first)
+			end := len(data) - 1
+			if last != "" {
+				end, _ = strconv.Atoi(last)
+				end = min(end, len(data)-1)
+			}
+			if start >= len(data) {
+				writeS3Error(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data = data[start : end+1]
+			statusCode = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(statusCode)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// list 实现ListObjectsV2，max-keys限制每页的对象数，continuation-token为上一页最后一个对象的键
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+	if err != nil || maxKeys <= 0 {
+		maxKeys = 1000
+	}
+	truncated := len(keys) > maxKeys
+	if truncated {
+		keys = keys[:maxKeys]
+	}
+
+	fmt.Fprintf(w, `<ListBucketResult><KeyCount>%d</KeyCount><IsTruncated>%t</IsTruncated>`, len(keys), truncated)
+	for _, key := range keys {
+		fmt.Fprint(w, `<Contents><Key>`)
+		xml.EscapeText(w, []byte(key))
+		fmt.Fprintf(w, `</Key><Size>%d</Size><LastModified>%s</LastModified></Contents>`, len(f.objects[key]), time.Now().UTC().Format(time.RFC3339))
+	}
+	if truncated {
+		fmt.Fprint(w, `<NextContinuationToken>`)
+		xml.EscapeText(w, []byte(keys[len(keys)-1]))
+		fmt.Fprint(w, `</NextContinuationToken>`)
+	}
+	fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+// writeS3Error 写入S3格式的错误响应
+func writeS3Error(w http.ResponseWriter, statusCode int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: code})
+}
@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sniffLength 检测文件类型时读取的字节数
+const sniffLength = 512
+
+// SQLiteConfig SQLite配置
+type SQLiteConfig struct {
+	Path string
+}
+
+// FileInfo 文件元数据模型
+type FileInfo struct {
+	ID          string            `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Filename    string            `gorm:"type:varchar(255)" json:"filename"` // 上传时的原始文件名
+	ContentType string            `gorm:"type:varchar(100)" json:"content_type"`
+	Size        int64             `gorm:"not null;default:0" json:"size"`
+	UserID      string            `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	Backend     string            `gorm:"type:varchar(20);not null;default:'local'" json:"backend"` // 保存文件的存储后端
+	StoragePath string            `gorm:"type:varchar(512);not null" json:"storage_path"`           // 存储后端中的对象键
+	Checksum    string            `gorm:"type:varchar(64);index" json:"checksum"`                   // 文件内容的SHA-256
+	Metadata    map[string]string `gorm:"type:json;serializer:json" json:"metadata"`
+	Export      bool              `gorm:"not null;default:false;index" json:"export"` // 网关生成的账本导出文件
+	CreatedAt   time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ConfigureDatabase 配置文件元数据数据库
+func (s *StorageService) ConfigureDatabase(config SQLiteConfig) error {
+	// 确保数据目录存在
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(sqlite.Open(config.Path), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.db = db
+	s.mu.Unlock()
+	return nil
+}
+
+// InitDatabase 初始化数据库，为引入元数据表之前上传的文件补充记录并迁移为内容对象，
+// 然后用当前主密钥重新包装轮换前的数据密钥
+func (s *StorageService) InitDatabase() error {
+	if err := s.db.AutoMigrate(&FileInfo{}, &UploadSession{}, &FileVariant{}, &Blob{}, &DataKey{}); err != nil {
+		return err
+	}
+	if err := s.relativizeStoragePaths(); err != nil {
+		return err
+	}
+	if err := s.importLegacyFiles(); err != nil {
+		return err
+	}
+	if err := s.migrateBlobs(context.Background()); err != nil {
+		return err
+	}
+	return s.rewrapDataKeys()
+}
+
+// relativizeStoragePaths 将早期记录的本地文件路径转换为相对于存储目录的对象键
+// 早期记录保存的是存储目录与对象键拼接后的路径，存储目录可能是绝对路径或相对路径
+func (s *StorageService) relativizeStoragePaths() error {
+	s.mu.RLock()
+	root := s.config.Local.Path
+	s.mu.RUnlock()
+	if root == "" {
+		return nil
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	prefixes := []string{filepath.Clean(root) + string(filepath.Separator), absRoot + string(filepath.Separator)}
+
+	var files []FileInfo
+	if err := s.db.Where("backend = ?", backendLocal).Find(&files).Error; err != nil {
+		return err
+	}
+	for _, file := range files {
+		storagePath := filepath.Clean(file.StoragePath)
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(storagePath, prefix) {
+				continue
+			}
+			key := filepath.ToSlash(strings.TrimPrefix(storagePath, prefix))
+			if err := s.db.Model(&FileInfo{}).Where("id = ?", file.ID).Update("storage_path", key).Error; err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// importLegacyFiles 扫描存储目录，为没有元数据记录的文件补充记录
+// 旧文件的原始文件名已丢失，使用存储文件名代替
+func (s *StorageService) importLegacyFiles() error {
+	s.mu.RLock()
+	root := s.config.Local.Path
+	s.mu.RUnlock()
+	if root == "" {
+		return nil
+	}
+
+	userDirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	imported := 0
+	for _, userDir := range userDirs {
+		if !userDir.IsDir() || userDir.Name() == blobPrefix {
+			continue
+		}
+		if err := validateUserID(userDir.Name()); err != nil {
+			log.Printf("Skipping legacy directory %s: %v", userDir.Name(), err)
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(root, userDir.Name()))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			fileID := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+
+			var count int64
+			if err := s.db.Model(&FileInfo{}).Where("id = ?", fileID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			info, err := describeFile(filepath.Join(root, userDir.Name(), file.Name()))
+			if err != nil {
+				log.Printf("Failed to read legacy file %s: %v", file.Name(), err)
+				continue
+			}
+			info.ID = fileID
+			info.UserID = userDir.Name()
+			info.Filename = sanitizeFilename(file.Name())
+			info.Backend = backendLocal
+			info.StoragePath = path.Join(userDir.Name(), file.Name())
+			if err := s.db.Create(info).Error; err != nil {
+				return err
+			}
+			imported++
+		}
+	}
+
+	if imported > 0 {
+		log.Printf("Imported metadata for %d legacy files", imported)
+	}
+	return nil
+}
+
+// describeFile 读取本地文件计算大小、校验和并检测类型
+func describeFile(filePath string) (*FileInfo, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	sniff := &sniffWriter{}
+	if _, err := io.Copy(io.MultiWriter(hasher, sniff), file); err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{
+		ContentType: detectContentType(sniff.data, filePath),
+		Size:        stat.Size(),
+		Checksum:    hex.EncodeToString(hasher.Sum(nil)),
+		CreatedAt:   stat.ModTime(),
+	}, nil
+}
+
+// sniffWriter 保留写入内容的前sniffLength字节，用于检测文件类型
+type sniffWriter struct {
+	data []byte
+}
+
+// Write 实现io.Writer
+func (w *sniffWriter) Write(p []byte) (int, error) {
+	if remaining := sniffLength - len(w.data); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		w.data = append(w.data, p[:remaining]...)
+	}
+	return len(p), nil
+}
+
+// detectContentType 根据文件头检测MIME类型，无法识别时按扩展名推断
+func detectContentType(head []byte, filename string) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExt != "" {
+			return byExt
+		}
+	}
+	return contentType
+}
+
+// fileInfoToProto 将文件元数据模型转换为proto消息
+func fileInfoToProto(info *FileInfo) *storage.FileInfo {
+	metadata := info.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return &storage.FileInfo{
+		Id:          info.ID,
+		Filename:    info.Filename,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		UserId:      info.UserID,
+		StoragePath: info.StoragePath,
+		CreatedAt:   info.CreatedAt.Format(time.RFC3339),
+		Metadata:    metadata,
+		Checksum:    info.Checksum,
+	}
+}
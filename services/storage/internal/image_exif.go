@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const (
+	// exifHeadLength 上传JPEG时暂存的文件头长度，EXIF段位于文件开头且不超过64KB
+	exifHeadLength = 128 << 10
+
+	// EXIF标签
+	exifTagOrientation = 0x0112
+	exifTagGPSInfo     = 0x8825
+)
+
+// exifTypeSizes EXIF各数据类型的单个值字节数
+var exifTypeSizes = map[uint16]int64{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8,
+}
+
+// headLength 上传时需要暂存的文件头长度，JPEG需要包含完整的EXIF段
+func headLength(head []byte) int {
+	if len(head) >= sniffLength && isJPEG(head) {
+		return exifHeadLength
+	}
+	return sniffLength
+}
+
+// sanitizeHead 清除文件头中的隐私信息，不改变数据长度
+func sanitizeHead(head []byte) {
+	stripExifGPS(head)
+}
+
+// isJPEG 判断内容是否以JPEG文件头开始
+func isJPEG(data []byte) bool {
+	return len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF
+}
+
+// jpegExif 在JPEG文件头中查找EXIF段，返回其中TIFF格式的数据
+// 返回的切片与data共享内存，修改会直接作用于data
+func jpegExif(data []byte) []byte {
+	if !isJPEG(data) {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// 填充字节
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// 图像数据开始或文件结束，之后不会再有EXIF段
+			return nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// 没有长度字段的标记
+			i += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		i += 2 + length
+	}
+	return nil
+}
+
+// tiffData TIFF格式的EXIF数据
+type tiffData struct {
+	data  []byte
+	order binary.ByteOrder
+	ifd0  int
+}
+
+// ifdEntry IFD中的一个条目
+type ifdEntry struct {
+	offset int // 条目在数据中的位置
+	tag    uint16
+	typ    uint16
+	count  uint32
+}
+
+// valueOffset 条目值字段的位置，值不超过4字节时直接存放在值字段中
+func (e ifdEntry) valueOffset() int {
+	return e.offset + 8
+}
+
+// parseTIFF 解析TIFF头
+func parseTIFF(data []byte) (*tiffData, bool) {
+	if len(data) < 8 {
+		return nil, false
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, false
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return nil, false
+	}
+	return &tiffData{data: data, order: order, ifd0: int(order.Uint32(data[4:]))}, true
+}
+
+// entries 读取IFD中的条目，IFD超出数据范围时返回false
+func (t *tiffData) entries(ifd int) ([]ifdEntry, bool) {
+	if ifd < 8 || ifd+2 > len(t.data) {
+		return nil, false
+	}
+	count := int(t.order.Uint16(t.data[ifd:]))
+	if ifd+2+12*count+4 > len(t.data) {
+		return nil, false
+	}
+	entries := make([]ifdEntry, count)
+	for i := range entries {
+		offset := ifd + 2 + 12*i
+		entries[i] = ifdEntry{
+			offset: offset,
+			tag:    t.order.Uint16(t.data[offset:]),
+			typ:    t.order.Uint16(t.data[offset+2:]),
+			count:  t.order.Uint32(t.data[offset+4:]),
+		}
+	}
+	return entries, true
+}
+
+// exifOrientation 读取JPEG的EXIF方向，没有方向信息时返回1（正常方向）
+func exifOrientation(data []byte) int {
+	tiff, ok := parseTIFF(jpegExif(data))
+	if !ok {
+		return 1
+	}
+	entries, ok := tiff.entries(tiff.ifd0)
+	if !ok {
+		return 1
+	}
+	for _, entry := range entries {
+		if entry.tag == exifTagOrientation && entry.typ == 3 {
+			if orientation := int(tiff.order.Uint16(tiff.data[entry.valueOffset():])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+		}
+	}
+	return 1
+}
+
+// stripExifGPS 原地清除JPEG文件头中的GPS信息，不改变数据长度，返回是否有修改
+// 清除GPS IFD及其引用的数据，并从IFD0中移除指向GPS IFD的条目
+func stripExifGPS(data []byte) bool {
+	tiff, ok := parseTIFF(jpegExif(data))
+	if !ok {
+		return false
+	}
+	entries, ok := tiff.entries(tiff.ifd0)
+	if !ok {
+		return false
+	}
+
+	for _, entry := range entries {
+		if entry.tag != exifTagGPSInfo {
+			continue
+		}
+
+		// 清除GPS IFD引用的数据和GPS IFD本身
+		gpsIFD := int(tiff.order.Uint32(tiff.data[entry.valueOffset():]))
+		if gpsEntries, ok := tiff.entries(gpsIFD); ok {
+			for _, gpsEntry := range gpsEntries {
+				size := exifTypeSizes[gpsEntry.typ] * int64(gpsEntry.count)
+				if size <= 4 {
+					continue
+				}
+				start := int64(tiff.order.Uint32(tiff.data[gpsEntry.valueOffset():]))
+				if start >= 8 && start+size <= int64(len(tiff.data)) {
+					clear(tiff.data[start : start+size])
+				}
+			}
+			clear(tiff.data[gpsIFD : gpsIFD+2+12*len(gpsEntries)+4])
+		}
+
+		// 移除IFD0中的GPS条目，后续条目和下一个IFD的指针前移
+		end := tiff.ifd0 + 2 + 12*len(entries) + 4
+		copy(tiff.data[entry.offset:], tiff.data[entry.offset+12:end])
+		clear(tiff.data[end-12 : end])
+		tiff.order.PutUint16(tiff.data[tiff.ifd0:], uint16(len(entries)-1))
+		return true
+	}
+	return false
+}
+
+// orientImage 按EXIF方向旋转或翻转图像
+func orientImage(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// 方向5-8需要交换宽高
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	}
+	origin := rgba.Bounds().Min
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转180度
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转90度
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转90度
+				dx, dy = y, w-1-x
+			}
+			si := rgba.PixOffset(origin.X+x, origin.Y+y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], rgba.Pix[si:si+4])
+		}
+	}
+	return dst
+}
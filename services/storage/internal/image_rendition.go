@@ -0,0 +1,278 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 图片版本
+const (
+	variantThumbnail = "thumbnail"
+	variantMedium    = "medium"
+)
+
+const (
+	// maxRenditionSourceSize 生成图片版本时原图的最大字节数
+	maxRenditionSourceSize = 64 << 20
+	// maxRenditionPixels 生成图片版本时原图的最大像素数，防止解码超大图片耗尽内存
+	maxRenditionPixels = 50_000_000
+	// renditionQuality 图片版本的JPEG压缩质量
+	renditionQuality = 80
+)
+
+// renditionSizes 各图片版本最长边的像素数
+var renditionSizes = map[string]int{
+	variantThumbnail: 256,
+	variantMedium:    1024,
+}
+
+// renderSlots 限制同时生成图片版本的数量，避免并发解码大图占用过多内存
+var renderSlots = make(chan struct{}, 2)
+
+// FileVariant 图片版本模型，与原图保存在同一存储后端
+type FileVariant struct {
+	FileID      string    `gorm:"type:varchar(36);primaryKey"`
+	Variant     string    `gorm:"type:varchar(20);primaryKey"`
+	Backend     string    `gorm:"type:varchar(20);not null"`
+	StoragePath string    `gorm:"type:varchar(512);not null"`
+	ContentType string    `gorm:"type:varchar(100)"`
+	Size        int64     `gorm:"not null"`
+	Checksum    string    `gorm:"type:varchar(64)"`
+	Width       int       `gorm:"not null"`
+	Height      int       `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// isRenderable 判断是否可以为该类型的文件生成图片版本
+func isRenderable(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// resolveFile 查询文件元数据，variant不为空时返回对应图片版本的元数据
+// 图片版本沿用原文件的ID和所有者，内容相关的字段使用图片版本的值
+func (s *StorageService) resolveFile(ctx context.Context, fileID, userID, variant string) (*FileInfo, error) {
+	fileInfo, err := s.findFile(fileID, userID)
+	if err != nil || variant == "" {
+		return fileInfo, err
+	}
+
+	rendition, err := s.rendition(ctx, fileInfo, variant)
+	if err != nil {
+		return nil, err
+	}
+	variantInfo := *fileInfo
+	variantInfo.Filename = strings.TrimSuffix(fileInfo.Filename, filepath.Ext(fileInfo.Filename)) + "_" + variant + ".jpg"
+	variantInfo.ContentType = rendition.ContentType
+	variantInfo.Size = rendition.Size
+	variantInfo.Checksum = rendition.Checksum
+	variantInfo.Backend = rendition.Backend
+	variantInfo.StoragePath = rendition.StoragePath
+	variantInfo.CreatedAt = rendition.CreatedAt
+	return &variantInfo, nil
+}
+
+// rendition 返回图片版本，尚未生成时从原图生成
+func (s *StorageService) rendition(ctx context.Context, fileInfo *FileInfo, variant string) (*FileVariant, error) {
+	size, ok := renditionSizes[variant]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown variant: %s", variant)
+	}
+	if !isRenderable(fileInfo.ContentType) {
+		return nil, status.Errorf(codes.InvalidArgument, "Variant %s is only available for images", variant)
+	}
+
+	var rendition FileVariant
+	err := s.db.First(&rendition, "file_id = ? AND variant = ?", fileInfo.ID, variant).Error
+	if err == nil {
+		return &rendition, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, status.Errorf(codes.Internal, "Failed to query file variant: %v", err)
+	}
+	return s.generateRendition(ctx, fileInfo, variant, size)
+}
+
+// generateRendition 从原图生成图片版本并写入存储后端
+func (s *StorageService) generateRendition(ctx context.Context, fileInfo *FileInfo, variant string, size int) (*FileVariant, error) {
+	backend, err := s.backendFor(fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	renderSlots <- struct{}{}
+	defer func() { <-renderSlots }()
+
+	reader, err := s.openObject(ctx, fileInfo, 0, 0)
+	if err != nil {
+		if isNotExist(err) {
+			return nil, status.Errorf(codes.NotFound, "File not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to open file: %v", err)
+	}
+	data, width, height, err := renderImage(reader, size)
+	reader.Close()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Failed to render image: %v", err)
+	}
+
+	// 图片版本放在用户目录的子目录中，不会被当作历史文件导入
+	// 每次生成使用不同的对象键，并发生成时各自的数据密钥不会互相覆盖
+	checksum := sha256.Sum256(data)
+	rendition := FileVariant{
+		FileID:      fileInfo.ID,
+		Variant:     variant,
+		Backend:     fileInfo.Backend,
+		StoragePath: path.Join(fileInfo.UserID, "variants", fileInfo.ID+"_"+variant+"_"+uuid.New().String()[:8]+".jpg"),
+		ContentType: "image/jpeg",
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		Width:       width,
+		Height:      height,
+	}
+	key, err := s.newObjectKey()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to generate data key: %v", err)
+	}
+	content, err := key.seal(bytes.NewReader(data))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to encrypt file variant: %v", err)
+	}
+	if _, err := backend.Put(ctx, rendition.StoragePath, content); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to write file variant: %v", err)
+	}
+
+	// 并发生成时保留先写入的记录，删除其余的副本
+	saved := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rendition)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		saved = true
+		return key.save(tx, rendition.Backend, rendition.StoragePath)
+	})
+	if err != nil || !saved {
+		backend.Delete(context.Background(), rendition.StoragePath)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to save file variant: %v", err)
+	}
+	if !saved {
+		if err := s.db.First(&rendition, "file_id = ? AND variant = ?", fileInfo.ID, variant).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to query file variant: %v", err)
+		}
+	}
+	return &rendition, nil
+}
+
+// generateRenditions 在后台为新上传的图片生成全部图片版本，失败时等到首次请求再生成
+func (s *StorageService) generateRenditions(fileInfo FileInfo) {
+	if !isRenderable(fileInfo.ContentType) {
+		return
+	}
+	go func() {
+		for variant := range renditionSizes {
+			if _, err := s.rendition(context.Background(), &fileInfo, variant); err != nil {
+				log.Printf("Failed to generate %s for file %s: %v", variant, fileInfo.ID, err)
+			}
+		}
+	}()
+}
+
+// deleteRenditions 删除文件的全部图片版本
+func (s *StorageService) deleteRenditions(ctx context.Context, fileInfo *FileInfo) error {
+	var renditions []FileVariant
+	if err := s.db.Where("file_id = ?", fileInfo.ID).Find(&renditions).Error; err != nil {
+		return err
+	}
+	for _, rendition := range renditions {
+		backend, err := s.backendFor(&FileInfo{Backend: rendition.Backend})
+		if err != nil {
+			return err
+		}
+		if err := backend.Delete(ctx, rendition.StoragePath); err != nil && !isNotExist(err) {
+			return err
+		}
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, rendition := range renditions {
+			if err := deleteDataKey(tx, rendition.Backend, rendition.StoragePath); err != nil {
+				return err
+			}
+		}
+		return tx.Where("file_id = ?", fileInfo.ID).Delete(&FileVariant{}).Error
+	})
+}
+
+// renderImage 解码图片，缩放到最长边不超过size并按EXIF方向旋转，编码为JPEG
+// 重新编码的图片不包含原图的EXIF信息
+func renderImage(r io.Reader, size int) ([]byte, int, int, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxRenditionSourceSize+1))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(data) > maxRenditionSourceSize {
+		return nil, 0, 0, errors.New("image is too large")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if int64(config.Width)*int64(config.Height) > maxRenditionPixels {
+		return nil, 0, 0, fmt.Errorf("image dimensions %dx%d are too large", config.Width, config.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// 按比例缩小，小图保持原尺寸
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	// JPEG不支持透明，透明区域填充白色
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(scaled, scaled.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, bounds, draw.Over, nil)
+	oriented := orientImage(scaled, exifOrientation(data))
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, oriented, &jpeg.Options{Quality: renditionQuality}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), oriented.Bounds().Dx(), oriented.Bounds().Dy(), nil
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// s3PartSize 分段上传时每段的大小，超过该大小的文件使用分段上传
+const s3PartSize = 8 << 20
+
+// S3Config S3存储配置，兼容MinIO等S3协议的对象存储
+type S3Config struct {
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string // 为空时使用AWS默认地址；非AWS地址使用路径风格访问
+}
+
+// s3Backend S3存储后端
+type s3Backend struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+}
+
+// newS3Backend 创建S3存储后端
+func newS3Backend(config S3Config) (*s3Backend, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	options := s3.Options{
+		Region: region,
+		Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     config.AccessKeyID,
+				SecretAccessKey: config.SecretAccessKey,
+				Source:          "BeeCountStorageConfig",
+			}, nil
+		})),
+		// 仅在接口要求时计算校验和，兼容不支持新版校验和的S3兼容存储
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	if config.Endpoint != "" {
+		endpoint, err := url.Parse(config.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+		}
+		options.BaseEndpoint = aws.String(config.Endpoint)
+		options.UsePathStyle = !strings.HasSuffix(endpoint.Hostname(), "amazonaws.com")
+	}
+
+	client := s3.New(options)
+	return &s3Backend{
+		client: client,
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = s3PartSize
+		}),
+		bucket: config.Bucket,
+	}, nil
+}
+
+// Put 上传对象，大文件自动使用分段上传，失败时中止分段上传
+func (b *s3Backend) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := validateKey(key); err != nil {
+		return 0, err
+	}
+	counter := &countingReader{r: r}
+	if _, err := b.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+		Body:   counter,
+	}); err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// Get 获取对象的读取流，读取部分内容时使用Range请求
+func (b *s3Backend) Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	}
+	if length > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	out, err := b.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return out.Body, nil
+}
+
+// Delete 删除对象，S3删除不存在的对象不会报错，因此先确认对象存在
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if _, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return s3Error(err)
+	}
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// Move 复制对象后删除源对象，S3不支持重命名
+// 单次复制的对象不能超过5GB，上传文件的大小远小于该限制
+func (b *s3Backend) Move(ctx context.Context, from, to string) error {
+	if err := validateKey(from); err != nil {
+		return err
+	}
+	if err := validateKey(to); err != nil {
+		return err
+	}
+	if _, err := b.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(b.bucket),
+		Key:        aws.String(to),
+		CopySource: aws.String((&url.URL{Path: b.bucket + "/" + from}).EscapedPath()),
+	}); err != nil {
+		return s3Error(err)
+	}
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(from),
+	})
+	return err
+}
+
+// List 分页列出对象
+func (b *s3Backend) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			if err := fn(ObjectInfo{
+				Key:     aws.ToString(object.Key),
+				Size:    aws.ToInt64(object.Size),
+				ModTime: aws.ToTime(object.LastModified),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// s3Error 将对象不存在的S3错误转换为fs.ErrNotExist
+func s3Error(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound":
+			return fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+		}
+	}
+	return err
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read 实现io.Reader
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
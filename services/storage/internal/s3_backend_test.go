@@ -0,0 +1,361 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestS3Backend(t *testing.T) (*fakeS3, *s3Backend) {
+	t.Helper()
+	fake, config := newFakeS3(t)
+	backend, err := newS3Backend(config)
+	if err != nil {
+		t.Fatalf("newS3Backend: %v", err)
+	}
+	return fake, backend
+}
+
+func TestS3BackendPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	fake, backend := newTestS3Backend(t)
+
+	content := []byte("hello storage")
+	n, err := backend.Put(ctx, "user-1/file.txt", bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("Put wrote %d bytes, want %d", n, len(content))
+	}
+	if stored, _ := fake.object("user-1/file.txt"); !bytes.Equal(stored, content) {
+		t.Errorf("stored object = %q, want %q", stored, content)
+	}
+
+	reader, err := backend.Get(ctx, "user-1/file.txt", 0, 0)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("read object: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	if err := backend.Delete(ctx, "user-1/file.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.object("user-1/file.txt"); ok {
+		t.Error("object still exists after Delete")
+	}
+}
+
+func TestS3BackendGetRange(t *testing.T) {
+	ctx := context.Background()
+	_, backend := newTestS3Backend(t)
+
+	if _, err := backend.Put(ctx, "user-1/range.txt", bytes.NewReader([]byte("0123456789"))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	tests := []struct {
+		offset, length int64
+		want           string
+	}{
+		{0, 0, "0123456789"},
+		{3, 0, "3456789"},
+		{3, 4, "3456"},
+		{8, 10, "89"},
+	}
+	for _, tt := range tests {
+		reader, err := backend.Get(ctx, "user-1/range.txt", tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("Get(%d, %d): %v", tt.offset, tt.length, err)
+		}
+		got, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("read range: %v", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Get(%d, %d) = %q, want %q", tt.offset, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestS3BackendMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	fake, backend := newTestS3Backend(t)
+
+	content := make([]byte, 2*s3PartSize+1024)
+	rand.Read(content)
+	n, err := backend.Put(ctx, "user-1/large.bin", bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("Put wrote %d bytes, want %d", n, len(content))
+	}
+	if fake.multipartCompleted != 1 {
+		t.Errorf("multipart uploads completed = %d, want 1", fake.multipartCompleted)
+	}
+	if stored, _ := fake.object("user-1/large.bin"); !bytes.Equal(stored, content) {
+		t.Error("stored object does not match uploaded content")
+	}
+}
+
+func TestS3BackendAbortsFailedMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	fake, backend := newTestS3Backend(t)
+
+	readErr := errors.New("client disconnected")
+	body := io.MultiReader(bytes.NewReader(make([]byte, 2*s3PartSize)), &failingReader{err: readErr})
+	if _, err := backend.Put(ctx, "user-1/broken.bin", body); err == nil {
+		t.Fatal("Put succeeded with a failing reader")
+	}
+	if _, ok := fake.object("user-1/broken.bin"); ok {
+		t.Error("partial object was stored")
+	}
+	if pending := fake.pendingUploads(); pending != 0 {
+		t.Errorf("pending multipart uploads = %d, want 0", pending)
+	}
+}
+
+func TestS3BackendNotFound(t *testing.T) {
+	ctx := context.Background()
+	_, backend := newTestS3Backend(t)
+
+	if _, err := backend.Get(ctx, "missing", 0, 0); !isNotExist(err) {
+		t.Errorf("Get missing object error = %v, want not exist", err)
+	}
+	if err := backend.Delete(ctx, "missing"); !isNotExist(err) {
+		t.Errorf("Delete missing object error = %v, want not exist", err)
+	}
+}
+
+func TestS3BackendList(t *testing.T) {
+	ctx := context.Background()
+	_, backend := newTestS3Backend(t)
+
+	for _, key := range []string{"blobs/ab/cd/abcd", "blobs/staging/upload", "user-1/variants/a.jpg"} {
+		if _, err := backend.Put(ctx, key, bytes.NewReader([]byte(key))); err != nil {
+			t.Fatalf("Put %s: %v", key, err)
+		}
+	}
+	var keys []string
+	err := backend.List(ctx, "blobs/", func(object ObjectInfo) error {
+		if object.Size != int64(len(object.Key)) {
+			t.Errorf("List %s size = %d, want %d", object.Key, object.Size, len(object.Key))
+		}
+		keys = append(keys, object.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if strings.Join(keys, ",") != "blobs/ab/cd/abcd,blobs/staging/upload" {
+		t.Errorf("List keys = %v", keys)
+	}
+}
+
+func TestStorageServiceWithS3Backend(t *testing.T) {
+	fake, s3Config := newFakeS3(t)
+	service := NewStorageService()
+	if err := service.ConfigureStorage(StorageConfig{
+		Active: backendS3,
+		Local:  LocalStorageConfig{Path: filepath.Join(t.TempDir(), "uploads")},
+		S3:     s3Config,
+	}); err != nil {
+		t.Fatalf("ConfigureStorage: %v", err)
+	}
+	if err := service.ConfigureDatabase(SQLiteConfig{Path: filepath.Join(t.TempDir(), "storage.db")}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := service.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+
+	content := make([]byte, s3PartSize+4096)
+	copy(content, "%PDF-1.4\n")
+	upload := &fakeUploadStream{ctx: context.Background()}
+	for offset := 0; offset < len(content); offset += 1 << 20 {
+		end := min(offset+1<<20, len(content))
+		req := &storage.UploadFileRequest{Chunk: content[offset:end]}
+		if offset == 0 {
+			req.Filename = "report.pdf"
+			req.UserId = "user-1"
+		}
+		upload.requests = append(upload.requests, req)
+	}
+	if err := service.UploadFile(upload); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	info := upload.response.FileInfo
+	if info.Size != int64(len(content)) || info.ContentType != "application/pdf" {
+		t.Errorf("uploaded file info = %+v", info)
+	}
+	if stored, _ := fake.object(info.StoragePath); !bytes.Equal(stored, content) {
+		t.Error("object in S3 does not match uploaded content")
+	}
+
+	download := &fakeDownloadStream{ctx: context.Background()}
+	if err := service.DownloadFile(&storage.DownloadFileRequest{FileId: info.Id, UserId: "user-1"}, download); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if !bytes.Equal(download.data.Bytes(), content) {
+		t.Error("downloaded content does not match uploaded content")
+	}
+
+	ranged := &fakeDownloadStream{ctx: context.Background()}
+	if err := service.DownloadFile(&storage.DownloadFileRequest{FileId: info.Id, UserId: "user-1", Offset: s3PartSize, Length: 2048}, ranged); err != nil {
+		t.Fatalf("DownloadFile range: %v", err)
+	}
+	if !bytes.Equal(ranged.data.Bytes(), content[s3PartSize:s3PartSize+2048]) {
+		t.Error("downloaded range does not match uploaded content")
+	}
+
+	if _, err := service.DeleteFile(context.Background(), &storage.DeleteFileRequest{FileId: info.Id, UserId: "user-1"}); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, ok := fake.object(info.StoragePath); ok {
+		t.Error("object still exists in S3 after DeleteFile")
+	}
+	if _, err := service.GetFileInfo(context.Background(), &storage.GetFileInfoRequest{FileId: info.Id, UserId: "user-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetFileInfo after delete error = %v, want NotFound", err)
+	}
+}
+
+func TestStorageServiceDeduplicatesUploads(t *testing.T) {
+	fake, s3Config := newFakeS3(t)
+	service := NewStorageService()
+	if err := service.ConfigureStorage(StorageConfig{
+		Active:    backendS3,
+		S3:        s3Config,
+		UserQuota: 1 << 20,
+	}); err != nil {
+		t.Fatalf("ConfigureStorage: %v", err)
+	}
+	if err := service.ConfigureDatabase(SQLiteConfig{Path: filepath.Join(t.TempDir(), "storage.db")}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := service.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+
+	upload := func(userID string, content []byte) (*storage.FileInfo, error) {
+		stream := &fakeUploadStream{ctx: context.Background(), requests: []*storage.UploadFileRequest{
+			{Filename: "receipt.pdf", UserId: userID, Chunk: content},
+		}}
+		if err := service.UploadFile(stream); err != nil {
+			return nil, err
+		}
+		return stream.response.FileInfo, nil
+	}
+	deleteFile := func(info *storage.FileInfo) {
+		t.Helper()
+		if _, err := service.DeleteFile(context.Background(), &storage.DeleteFileRequest{FileId: info.Id, UserId: info.UserId}); err != nil {
+			t.Fatalf("DeleteFile: %v", err)
+		}
+	}
+
+	content := append([]byte("%PDF-1.4\n"), make([]byte, 600<<10)...)
+	first, err := upload("user-1", content)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	second, err := upload("user-2", content)
+	if err != nil {
+		t.Fatalf("UploadFile duplicate: %v", err)
+	}
+	if first.StoragePath != second.StoragePath || first.StoragePath != blobKey(first.Checksum) {
+		t.Errorf("storage paths = %q and %q, want shared blob %q", first.StoragePath, second.StoragePath, blobKey(first.Checksum))
+	}
+	if objects := fake.objectCount(); objects != 1 {
+		t.Errorf("S3 holds %d objects, want 1", objects)
+	}
+
+	// 重复的内容也计入用量，第二次上传超出配额
+	if _, err := upload("user-1", content); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("UploadFile over quota error = %v, want ResourceExhausted", err)
+	} else if details := status.Convert(err).Details(); len(details) != 1 {
+		t.Errorf("quota error details = %v, want QuotaFailure", details)
+	}
+	usage, err := service.GetUsage(context.Background(), &storage.GetUsageRequest{UserId: "user-1"})
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if usage.UsedBytes != int64(len(content)) || usage.FileCount != 1 || usage.QuotaBytes != 1<<20 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	deleteFile(first)
+	if _, ok := fake.object(second.StoragePath); !ok {
+		t.Fatal("shared object deleted while still referenced")
+	}
+	deleteFile(second)
+	if _, ok := fake.object(second.StoragePath); ok {
+		t.Error("object still exists after last reference was deleted")
+	}
+	if objects := fake.objectCount(); objects != 0 {
+		t.Errorf("S3 holds %d objects after deletes, want 0", objects)
+	}
+}
+
+// failingReader 始终返回指定错误
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// fakeUploadStream 模拟上传文件的客户端流
+type fakeUploadStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*storage.UploadFileRequest
+	response *storage.UploadFileResponse
+}
+
+func (s *fakeUploadStream) Context() context.Context { return s.ctx }
+
+func (s *fakeUploadStream) Recv() (*storage.UploadFileRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeUploadStream) SendAndClose(resp *storage.UploadFileResponse) error {
+	s.response = resp
+	return nil
+}
+
+// fakeDownloadStream 模拟下载文件的服务端流
+type fakeDownloadStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	data bytes.Buffer
+}
+
+func (s *fakeDownloadStream) Context() context.Context { return s.ctx }
+
+func (s *fakeDownloadStream) Send(resp *storage.DownloadFileResponse) error {
+	s.data.Write(resp.Chunk)
+	return nil
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultURLPrefix 未配置storage.local.url_prefix时签名URL使用的路径前缀
+	defaultURLPrefix = "/uploads"
+	// defaultSignedURLExpiry 未配置有效期时签名URL的有效期
+	defaultSignedURLExpiry = 15 * time.Minute
+)
+
+// SignedURLConfig 签名URL配置
+type SignedURLConfig struct {
+	Secret string        // HMAC签名密钥，为空时启动时随机生成，重启后之前生成的URL失效
+	Expiry time.Duration // 签名URL的默认有效期，也是请求可以指定的最长有效期
+}
+
+// urlSigner 生成和校验签名URL
+type urlSigner struct {
+	prefix string
+	secret []byte
+	expiry time.Duration
+}
+
+// newURLSigner 创建签名URL的签名器
+func newURLSigner(prefix string, config SignedURLConfig) (*urlSigner, error) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		prefix = defaultURLPrefix
+	}
+	secret := []byte(config.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, sha256.Size)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	expiry := config.Expiry
+	if expiry <= 0 {
+		expiry = defaultSignedURLExpiry
+	}
+	return &urlSigner{prefix: prefix, secret: secret, expiry: expiry}, nil
+}
+
+// sign 计算文件ID、图片版本和过期时间的HMAC-SHA256签名
+func (u *urlSigner) sign(fileID, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(fileID + "\n" + variant + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// url 生成签名URL：{prefix}/{fileID}?expires=...&signature=...[&variant=...]
+func (u *urlSigner) url(fileID, variant string, expires int64) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", u.sign(fileID, variant, expires))
+	if variant != "" {
+		query.Set("variant", variant)
+	}
+	return u.prefix + "/" + url.PathEscape(fileID) + "?" + query.Encode()
+}
+
+// verify 校验签名和过期时间
+func (u *urlSigner) verify(fileID, variant string, expires int64, signature string, now time.Time) error {
+	if !hmac.Equal([]byte(signature), []byte(u.sign(fileID, variant, expires))) {
+		return status.Errorf(codes.PermissionDenied, "Invalid URL signature")
+	}
+	if now.Unix() >= expires {
+		return status.Errorf(codes.PermissionDenied, "Signed URL has expired")
+	}
+	return nil
+}
+
+// currentSigner 返回当前的签名URL签名器
+func (s *StorageService) currentSigner() *urlSigner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signer
+}
+
+// CreateSignedURL 生成带HMAC签名、限时有效的下载URL，由网关在URL前缀下提供下载
+// 签名只覆盖文件ID、图片版本和过期时间，持有URL的任何人都可以在有效期内下载
+func (s *StorageService) CreateSignedURL(ctx context.Context, req *storage.CreateSignedURLRequest) (*storage.SignedURL, error) {
+	signer := s.currentSigner()
+	expiresIn := time.Duration(req.ExpiresIn) * time.Second
+	switch {
+	case req.ExpiresIn < 0 || expiresIn > signer.expiry:
+		return nil, status.Errorf(codes.InvalidArgument, "Expiry must be between 1 and %d seconds", int64(signer.expiry/time.Second))
+	case expiresIn == 0:
+		expiresIn = signer.expiry
+	}
+	if req.Variant != "" {
+		if _, ok := renditionSizes[req.Variant]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "Unknown variant: %s", req.Variant)
+		}
+	}
+	if _, err := s.findFile(req.FileId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiresIn).Truncate(time.Second)
+	return &storage.SignedURL{
+		Url:       signer.url(req.FileId, req.Variant, expiresAt.Unix()),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// VerifySignedURL 校验签名URL，通过时返回原文件信息，网关据此以文件所有者的身份下载文件
+func (s *StorageService) VerifySignedURL(ctx context.Context, req *storage.VerifySignedURLRequest) (*storage.FileInfo, error) {
+	if err := s.currentSigner().verify(req.FileId, req.Variant, req.Expires, req.Signature, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var fileInfo FileInfo
+	if err := s.db.First(&fileInfo, "id = ?", req.FileId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "File not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query file: %v", err)
+	}
+	return fileInfoToProto(&fileInfo), nil
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSignedURL(t *testing.T) {
+	ctx := context.Background()
+	service := NewStorageService()
+	if err := service.ConfigureStorage(StorageConfig{
+		Active:    backendLocal,
+		Local:     LocalStorageConfig{Path: filepath.Join(t.TempDir(), "uploads"), URLPrefix: "/files/"},
+		SignedURL: SignedURLConfig{Secret: "test-secret", Expiry: time.Hour},
+	}); err != nil {
+		t.Fatalf("ConfigureStorage: %v", err)
+	}
+	if err := service.ConfigureDatabase(SQLiteConfig{Path: filepath.Join(t.TempDir(), "storage.db")}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := service.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	upload := &fakeUploadStream{ctx: ctx, requests: []*storage.UploadFileRequest{
+		{Filename: "receipt.txt", UserId: "user-1", Chunk: []byte("receipt")},
+	}}
+	if err := service.UploadFile(upload); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	info := upload.response.FileInfo
+
+	signed, err := service.CreateSignedURL(ctx, &storage.CreateSignedURLRequest{FileId: info.Id, UserId: "user-1", Variant: variantThumbnail})
+	if err != nil {
+		t.Fatalf("CreateSignedURL: %v", err)
+	}
+	u, err := url.Parse(signed.Url)
+	if err != nil {
+		t.Fatalf("parse signed URL %q: %v", signed.Url, err)
+	}
+	if u.Path != "/files/"+info.Id {
+		t.Errorf("signed URL path = %q, want /files/%s", u.Path, info.Id)
+	}
+	query := u.Query()
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if remaining := time.Until(time.Unix(expires, 0)); remaining <= 59*time.Minute || remaining > time.Hour {
+		t.Errorf("signed URL expires in %v, want the configured hour", remaining)
+	}
+	verify := func(fileID, variant string, expires int64, signature string) error {
+		_, err := service.VerifySignedURL(ctx, &storage.VerifySignedURLRequest{FileId: fileID, Variant: variant, Expires: expires, Signature: signature})
+		return err
+	}
+	verified, err := service.VerifySignedURL(ctx, &storage.VerifySignedURLRequest{
+		FileId: info.Id, Variant: query.Get("variant"), Expires: expires, Signature: query.Get("signature"),
+	})
+	if err != nil {
+		t.Fatalf("VerifySignedURL: %v", err)
+	}
+	if verified.Id != info.Id || verified.UserId != "user-1" {
+		t.Errorf("VerifySignedURL = %s owned by %s, want %s owned by user-1", verified.Id, verified.UserId, info.Id)
+	}
+
+	// 修改文件ID、图片版本、过期时间或签名都会使校验失败
+	for name, err := range map[string]error{
+		"variant":   verify(info.Id, "", expires, query.Get("signature")),
+		"expires":   verify(info.Id, variantThumbnail, expires+3600, query.Get("signature")),
+		"file":      verify(strings.Repeat("0", len(info.Id)), variantThumbnail, expires, query.Get("signature")),
+		"signature": verify(info.Id, variantThumbnail, expires, strings.ToUpper(query.Get("signature"))),
+	} {
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("tampered %s: error = %v, want PermissionDenied", name, err)
+		}
+	}
+
+	// 过期的签名即使签名正确也会被拒绝
+	past := time.Now().Add(-time.Minute).Unix()
+	if err := verify(info.Id, "", past, service.currentSigner().sign(info.Id, "", past)); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expired URL: error = %v, want PermissionDenied", err)
+	}
+
+	// 只有文件所有者可以生成签名URL，有效期不能超过配置的有效期
+	if _, err := service.CreateSignedURL(ctx, &storage.CreateSignedURLRequest{FileId: info.Id, UserId: "user-2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("CreateSignedURL for another user: error = %v, want NotFound", err)
+	}
+	if _, err := service.CreateSignedURL(ctx, &storage.CreateSignedURLRequest{FileId: info.Id, UserId: "user-1", ExpiresIn: 7200}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateSignedURL beyond the configured expiry: error = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/configclient"
+)
+
+// StorageConfigKeys 存储服务使用的配置项
+var StorageConfigKeys = []string{
+	"storage.active",
+	"storage.local.path",
+	"storage.local.url_prefix",
+	"storage.s3.region",
+	"storage.s3.bucket",
+	"storage.s3.access_key_id",
+	"storage.s3.secret_access_key",
+	"storage.s3.endpoint",
+	"storage.max_file_size",
+	"storage.allowed_file_types",
+	"storage.user_quota",
+	"storage.export_retention_hours",
+	"storage.encryption.master_key",
+	"storage.encryption.master_key_file",
+	"storage.encryption.previous_keys",
+	"storage.signed_url.secret",
+	"storage.signed_url.expire_minutes",
+	"storage.scrub.interval_hours",
+	"storage.scrub.action",
+}
+
+// LoadStorageConfig 从配置服务的配置生成存储配置，未设置的配置项保留defaults中的值
+func LoadStorageConfig(client *configclient.Client, defaults StorageConfig) (StorageConfig, error) {
+	cfg := defaults
+	fields := map[string]*string{
+		"storage.active":               &cfg.Active,
+		"storage.local.path":           &cfg.Local.Path,
+		"storage.local.url_prefix":     &cfg.Local.URLPrefix,
+		"storage.s3.region":            &cfg.S3.Region,
+		"storage.s3.bucket":            &cfg.S3.Bucket,
+		"storage.s3.access_key_id":     &cfg.S3.AccessKeyID,
+		"storage.s3.secret_access_key": &cfg.S3.SecretAccessKey,
+		"storage.s3.endpoint":          &cfg.S3.Endpoint,
+
+		"storage.encryption.master_key":      &cfg.Encryption.MasterKey,
+		"storage.encryption.master_key_file": &cfg.Encryption.MasterKeyFile,
+		"storage.signed_url.secret":          &cfg.SignedURL.Secret,
+		"storage.scrub.action":               &cfg.Scrub.Action,
+	}
+	for key, field := range fields {
+		*field = client.String(key, *field)
+	}
+
+	var err error
+	if cfg.MaxFileSize, err = client.Int64("storage.max_file_size", defaults.MaxFileSize); err != nil {
+		return defaults, err
+	}
+	if cfg.UserQuota, err = client.Int64("storage.user_quota", defaults.UserQuota); err != nil {
+		return defaults, err
+	}
+	retention, err := client.Int("storage.export_retention_hours", int(defaults.ExportRetention/time.Hour))
+	if err != nil {
+		return defaults, err
+	}
+	cfg.ExportRetention = time.Duration(retention) * time.Hour
+	minutes, err := client.Int("storage.signed_url.expire_minutes", int(defaults.SignedURL.Expiry/time.Minute))
+	if err != nil {
+		return defaults, err
+	}
+	cfg.SignedURL.Expiry = time.Duration(minutes) * time.Minute
+	hours, err := client.Int("storage.scrub.interval_hours", int(defaults.Scrub.Interval/time.Hour))
+	if err != nil {
+		return defaults, err
+	}
+	cfg.Scrub.Interval = time.Duration(hours) * time.Hour
+	cfg.AllowedFileTypes = client.Strings("storage.allowed_file_types", defaults.AllowedFileTypes)
+	cfg.Encryption.PreviousKeys = client.Strings("storage.encryption.previous_keys", defaults.Encryption.PreviousKeys)
+	return cfg, nil
+}
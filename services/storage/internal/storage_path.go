@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxUserIDLength 用户ID的最大长度，与元数据表的字段长度一致
+	maxUserIDLength = 36
+	// maxFilenameLength 文件名的最大字节数
+	maxFilenameLength = 255
+	// maxExtensionLength 扩展名（不含点）的最大长度
+	maxExtensionLength = 16
+	// defaultFilename 文件名清理后为空时使用的文件名
+	defaultFilename = "file"
+)
+
+// errInvalidKey 对象键不是存储目录内的相对路径
+var errInvalidKey = errors.New("invalid storage key")
+
+// normalizeUpload 校验上传的用户ID和扩展名，返回规范化后的文件名
+func normalizeUpload(userID, filename string) (string, error) {
+	if err := validateUserID(userID); err != nil {
+		return "", err
+	}
+	filename = sanitizeFilename(filename)
+	if err := validateExtension(filename); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// validateUserID 检查用户ID只包含字母、数字、短横线和下划线，用户ID会作为存储路径的一部分
+func validateUserID(userID string) error {
+	if userID == "" {
+		return status.Errorf(codes.InvalidArgument, "User ID is required")
+	}
+	if len(userID) > maxUserIDLength {
+		return status.Errorf(codes.InvalidArgument, "User ID must not exceed %d characters", maxUserIDLength)
+	}
+	for _, r := range userID {
+		if !isASCIIAlnum(r) && r != '-' && r != '_' {
+			return status.Errorf(codes.InvalidArgument, "User ID contains invalid characters")
+		}
+	}
+	return nil
+}
+
+// validateExtension 检查扩展名只包含字母和数字，没有扩展名时通过
+func validateExtension(filename string) error {
+	ext := path.Ext(filename)
+	if ext == "" {
+		return nil
+	}
+	if len(ext) > maxExtensionLength+1 {
+		return status.Errorf(codes.InvalidArgument, "File extension must not exceed %d characters", maxExtensionLength)
+	}
+	for _, r := range ext[1:] {
+		if !isASCIIAlnum(r) {
+			return status.Errorf(codes.InvalidArgument, "File extension contains invalid characters")
+		}
+	}
+	return nil
+}
+
+// isASCIIAlnum 判断是否为ASCII字母或数字
+func isASCIIAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// sanitizeFilename 规范化上传时的原始文件名
+// 去掉客户端路径，移除控制字符和双向文本控制等格式字符，替换Windows文件名中不允许的字符，统一为NFC形式，
+// 去掉首尾的空格和点，并在不破坏扩展名和UTF-8编码的前提下截断到maxFilenameLength字节
+func sanitizeFilename(filename string) string {
+	filename = strings.ToValidUTF8(filename, "")
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+
+	var b strings.Builder
+	for _, r := range filename {
+		switch {
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		case strings.ContainsRune(`<>:"|?*`, r):
+			b.WriteRune('_')
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	// 先移除字符再做NFC，避免被移除的字符隔开的组合字符在再次清理时才合成
+	filename = strings.Trim(norm.NFC.String(b.String()), " .")
+
+	if len(filename) > maxFilenameLength {
+		ext := path.Ext(filename)
+		if len(ext) > maxExtensionLength+1 {
+			ext = ""
+		}
+		stem := filename[:maxFilenameLength-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		filename = strings.TrimRight(stem, " .") + ext
+	}
+	if strings.TrimSuffix(filename, path.Ext(filename)) == "" {
+		filename = defaultFilename + filename
+	}
+	return filename
+}
+
+// validateKey 检查对象键是否为斜杠分隔的相对路径，且不包含"."、".."或反斜杠
+func validateKey(key string) error {
+	if !fs.ValidPath(key) || key == "." || strings.Contains(key, `\`) || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return fmt.Errorf("%w: %q", errInvalidKey, key)
+	}
+	return nil
+}
+
+// within 判断target是否位于root之内，两者都必须是清理过的绝对路径
+func within(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != "." && filepath.IsLocal(rel)
+}
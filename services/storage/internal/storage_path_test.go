@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func FuzzValidateUserID(f *testing.F) {
+	for _, seed := range []string{"user-1", "0f8fad5b-d9cb-469f-a165-70867728950e", "../../etc", "..", "a/b", `a\b`, "user\x00", ""} {
+		f.Add(seed)
+	}
+	root := f.TempDir()
+	f.Fuzz(func(t *testing.T, userID string) {
+		if validateUserID(userID) != nil {
+			return
+		}
+		// 通过校验的用户ID作为单独一级目录，不会离开存储目录
+		target := filepath.Join(root, userID, "variants", "file.jpg")
+		if !within(root, target) || filepath.Dir(filepath.Dir(target)) != filepath.Join(root, userID) {
+			t.Errorf("user ID %q escapes the storage directory: %s", userID, target)
+		}
+		if err := validateKey(path.Join(userID, "variants", "file.jpg")); err != nil && runtimeAllowsName(userID) {
+			t.Errorf("valid user ID %q produced an invalid key: %v", userID, err)
+		}
+	})
+}
+
+func FuzzSanitizeFilename(f *testing.F) {
+	for _, seed := range []string{
+		"receipt.jpg", "../../etc/passwd", `C:\Users\me\scan.pdf`, " .hidden. ", "名片.png",
+		"invoice\u202egpj.exe", "a\r\nb.txt", "e\u0301.txt", strings.Repeat("x", 300) + ".jpeg", "", "...",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, filename string) {
+		got := sanitizeFilename(filename)
+		if got == "" || len(got) > maxFilenameLength || !utf8.ValidString(got) {
+			t.Fatalf("sanitizeFilename(%q) = %q", filename, got)
+		}
+		if strings.ContainsAny(got, `/\<>:"|?*`) || got == "." || got == ".." {
+			t.Errorf("sanitizeFilename(%q) = %q contains path or reserved characters", filename, got)
+		}
+		if strings.HasPrefix(got, " ") || strings.HasSuffix(got, " ") || strings.HasPrefix(got, ".") || strings.HasSuffix(got, ".") {
+			t.Errorf("sanitizeFilename(%q) = %q has leading or trailing spaces or dots", filename, got)
+		}
+		for _, r := range got {
+			if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+				t.Errorf("sanitizeFilename(%q) = %q contains control character %U", filename, got, r)
+			}
+		}
+		if again := sanitizeFilename(got); again != got {
+			t.Errorf("sanitizeFilename is not idempotent: %q -> %q -> %q", filename, got, again)
+		}
+	})
+}
+
+func FuzzLocalBackendPath(f *testing.F) {
+	for _, seed := range []string{
+		"user-1/file.jpg", "blobs/ab/cd/abcd", "../outside", "user/../../outside", "/etc/passwd", `..\outside`,
+		"a//b", "a/./b", "", ".", "link/file", "link", "CON/file",
+	} {
+		f.Add(seed)
+	}
+	base := f.TempDir()
+	backend, err := newLocalBackend(LocalStorageConfig{Path: filepath.Join(base, "uploads")})
+	if err != nil {
+		f.Fatalf("newLocalBackend: %v", err)
+	}
+	// 指向存储目录之外的符号链接
+	outside := filepath.Join(base, "outside")
+	os.MkdirAll(outside, 0755)
+	symlinks := os.Symlink(outside, filepath.Join(backend.root, "link")) == nil
+
+	f.Fuzz(func(t *testing.T, key string) {
+		target, err := backend.path(key)
+		if err != nil {
+			if !errors.Is(err, errInvalidKey) {
+				t.Errorf("path(%q) error = %v, want errInvalidKey", key, err)
+			}
+			return
+		}
+		if !within(backend.root, target) {
+			t.Errorf("path(%q) = %s escapes %s", key, target, backend.root)
+		}
+		if symlinks && (key == "link" || strings.HasPrefix(key, "link/")) {
+			t.Errorf("path(%q) followed a symbolic link out of the storage directory", key)
+		}
+	})
+}
+
+func TestLocalBackendRejectsEscapingKeys(t *testing.T) {
+	backend, err := newLocalBackend(LocalStorageConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newLocalBackend: %v", err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"../escape", "user/../../escape", "/etc/passwd", "", "."} {
+		if _, err := backend.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, errInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want errInvalidKey", key, err)
+		}
+		if _, err := backend.Get(ctx, key, 0, 0); !errors.Is(err, errInvalidKey) {
+			t.Errorf("Get(%q) error = %v, want errInvalidKey", key, err)
+		}
+	}
+	if _, err := normalizeUpload("../../etc", "receipt.jpg"); err == nil {
+		t.Error("normalizeUpload accepted a user ID with path separators")
+	}
+	if _, err := normalizeUpload("user-1", "receipt.j$g"); err == nil {
+		t.Error("normalizeUpload accepted an extension with invalid characters")
+	}
+	if got, err := normalizeUpload("user-1", `..\..\scan.pdf`); err != nil || got != "scan.pdf" {
+		t.Errorf("normalizeUpload filename = %q, %v, want scan.pdf", got, err)
+	}
+}
+
+// runtimeAllowsName 判断当前平台是否允许该名称作为路径中的一级，Windows保留CON、NUL等设备名
+func runtimeAllowsName(name string) bool {
+	return filepath.IsLocal(name)
+}
@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 孤立对象的处理方式，对应配置项storage.scrub.action
+const (
+	scrubReport     = "report"     // 只报告，不处理
+	scrubQuarantine = "quarantine" // 移动到隔离目录
+	scrubRemove     = "remove"     // 删除
+)
+
+// 巡检发现的问题类型
+const (
+	findingOrphan           = "orphan_object"
+	findingMissing          = "missing_object"
+	findingChecksumMismatch = "checksum_mismatch"
+	findingUnreadable       = "unreadable_object"
+)
+
+const (
+	// quarantinePrefix 隔离目录的键前缀，隔离的对象按巡检时间分目录保存原来的键
+	quarantinePrefix = blobPrefix + "/quarantine"
+	// scrubGracePeriod 最近修改过的对象可能属于尚未保存元数据的上传，不视为孤立对象
+	scrubGracePeriod = time.Hour
+)
+
+// ScrubConfig 后台巡检配置
+type ScrubConfig struct {
+	Interval time.Duration // 后台巡检的间隔，0表示不在后台巡检
+	Action   string        // 后台巡检对孤立对象的处理：report、quarantine或remove
+}
+
+// scrubOptions 一次巡检的选项
+type scrubOptions struct {
+	dryRun bool
+	action string
+	verify bool          // 读取对象内容校验SHA-256
+	minAge time.Duration // 修改时间早于该时长的对象才会被视为孤立对象
+}
+
+// scrubRef 元数据对对象的引用
+type scrubRef struct {
+	name     string // blob:{checksum}、file:{id}或variant:{file_id}/{variant}
+	checksum string // 对象明文的SHA-256，为空时不校验
+	size     int64
+	seen     bool
+}
+
+// Scrub 巡检存储后端，报告孤立对象、缺失对象和内容损坏的对象，非dry-run时隔离或删除孤立对象
+func (s *StorageService) Scrub(ctx context.Context, req *storage.ScrubRequest) (*storage.ScrubReport, error) {
+	action := req.Action
+	switch action {
+	case "":
+		action = scrubQuarantine
+	case scrubQuarantine, scrubRemove:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Scrub action must be %s or %s", scrubQuarantine, scrubRemove)
+	}
+	return s.scrub(ctx, scrubOptions{dryRun: req.DryRun, action: action, verify: req.VerifyChecksums, minAge: scrubGracePeriod})
+}
+
+// GetScrubReport 获取最近一次巡检的报告
+func (s *StorageService) GetScrubReport(ctx context.Context, req *storage.GetScrubReportRequest) (*storage.ScrubReport, error) {
+	s.mu.RLock()
+	report := s.lastScrub
+	s.mu.RUnlock()
+	if report == nil {
+		return nil, status.Errorf(codes.NotFound, "No scrub has been run")
+	}
+	return report, nil
+}
+
+// RunScrubber 按配置的间隔在后台巡检存储后端，直到ctx取消
+func (s *StorageService) RunScrubber(ctx context.Context) {
+	s.mu.RLock()
+	config := s.config.Scrub
+	s.mu.RUnlock()
+	if config.Interval <= 0 {
+		return
+	}
+
+	options := scrubOptions{action: config.Action, verify: true, minAge: scrubGracePeriod}
+	if options.action == "" || options.action == scrubReport {
+		options.dryRun, options.action = true, scrubQuarantine
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := s.scrub(ctx, options)
+		if err != nil {
+			log.Printf("Storage scrub failed: %v", err)
+			continue
+		}
+		if len(report.Findings) > 0 {
+			log.Printf("Storage scrub found %d problems in %d objects", len(report.Findings), report.ObjectsScanned)
+		}
+	}
+}
+
+// scrub 执行一次巡检，同一时间只运行一次
+func (s *StorageService) scrub(ctx context.Context, options scrubOptions) (*storage.ScrubReport, error) {
+	if !s.scrubMu.TryLock() {
+		return nil, status.Errorf(codes.Aborted, "A scrub is already running")
+	}
+	defer s.scrubMu.Unlock()
+
+	report := &storage.ScrubReport{
+		DryRun:    options.dryRun,
+		Action:    options.action,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	refs, err := s.scrubReferences()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to load file metadata: %v", err)
+	}
+
+	s.mu.RLock()
+	backends := make(map[string]Backend, len(s.backends))
+	for name, backend := range s.backends {
+		backends[name] = backend
+	}
+	s.mu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	quarantine := path.Join(quarantinePrefix, time.Now().UTC().Format("20060102T150405Z"))
+	for _, name := range names {
+		backend := backends[name]
+		backendRefs := refs[name]
+		report.ReferencesChecked += int64(len(backendRefs))
+
+		// 比对存储后端中的对象与元数据引用
+		var orphans []ObjectInfo
+		err := backend.List(ctx, "", func(object ObjectInfo) error {
+			if strings.HasPrefix(object.Key, quarantinePrefix+"/") {
+				return nil
+			}
+			report.ObjectsScanned++
+			if ref, ok := backendRefs[object.Key]; ok {
+				ref.seen = true
+				return nil
+			}
+			if time.Since(object.ModTime) >= options.minAge {
+				orphans = append(orphans, object)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to list %s storage: %v", name, err)
+		}
+
+		for _, object := range orphans {
+			finding, ok := s.handleOrphan(ctx, name, backend, object, quarantine, options)
+			if ok {
+				report.Findings = append(report.Findings, finding)
+			}
+		}
+
+		keys := make([]string, 0, len(backendRefs))
+		for key := range backendRefs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ref := backendRefs[key]
+			finding := &storage.ScrubFinding{Backend: name, StoragePath: key, Size: ref.size, Reference: ref.name}
+			if !ref.seen {
+				// 列出对象之后元数据可能已被删除，确认引用仍然存在且对象确实不存在
+				if referenced, _ := s.isReferenced(name, key); !referenced {
+					continue
+				}
+				if reader, err := backend.Get(ctx, key, 0, 1); err == nil {
+					reader.Close()
+					continue
+				} else if !isNotExist(err) {
+					finding.Kind, finding.Detail = findingUnreadable, err.Error()
+					report.Findings = append(report.Findings, finding)
+					continue
+				}
+				finding.Kind = findingMissing
+				report.Findings = append(report.Findings, finding)
+				continue
+			}
+			if options.verify && ref.checksum != "" {
+				if kind, detail := s.verifyObject(ctx, name, key, ref); kind != "" {
+					finding.Kind, finding.Detail = kind, detail
+					report.Findings = append(report.Findings, finding)
+				}
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	s.mu.Lock()
+	s.lastScrub = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// scrubReferences 按存储后端和对象键汇总内容对象、图片版本和文件元数据对对象的引用
+func (s *StorageService) scrubReferences() (map[string]map[string]*scrubRef, error) {
+	refs := make(map[string]map[string]*scrubRef)
+	add := func(backend, key string, ref *scrubRef) {
+		if refs[backend] == nil {
+			refs[backend] = make(map[string]*scrubRef)
+		}
+		if _, ok := refs[backend][key]; !ok {
+			refs[backend][key] = ref
+		}
+	}
+
+	var blobs []Blob
+	if err := s.db.Find(&blobs).Error; err != nil {
+		return nil, err
+	}
+	for _, blob := range blobs {
+		add(blob.Backend, blob.StoragePath, &scrubRef{name: "blob:" + blob.Checksum, checksum: blob.Checksum, size: blob.Size})
+	}
+	var renditions []FileVariant
+	if err := s.db.Find(&renditions).Error; err != nil {
+		return nil, err
+	}
+	for _, rendition := range renditions {
+		add(rendition.Backend, rendition.StoragePath, &scrubRef{
+			name:     "variant:" + rendition.FileID + "/" + rendition.Variant,
+			checksum: rendition.Checksum,
+			size:     rendition.Size,
+		})
+	}
+	// 内容对象已由对象记录覆盖，这里补充未迁移的历史文件和缺少对象记录的文件
+	var files []FileInfo
+	if err := s.db.Select("id", "backend", "storage_path", "checksum", "size").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		add(file.Backend, file.StoragePath, &scrubRef{name: "file:" + file.ID, checksum: file.Checksum, size: file.Size})
+	}
+	return refs, nil
+}
+
+// isReferenced 判断对象是否仍被元数据引用
+func (s *StorageService) isReferenced(backend, key string) (bool, error) {
+	for _, model := range []interface{}{&Blob{}, &FileVariant{}, &FileInfo{}} {
+		var count int64
+		if err := s.db.Model(model).Where("backend = ? AND storage_path = ?", backend, key).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// handleOrphan 确认对象仍未被引用后按选项隔离或删除，返回的ok为false表示对象已不再是孤立对象
+// 与保存文件使用同一把锁，避免处理刚移动到内容对象位置、尚未写入元数据的对象
+func (s *StorageService) handleOrphan(ctx context.Context, name string, backend Backend, object ObjectInfo, quarantine string, options scrubOptions) (*storage.ScrubFinding, bool) {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	finding := &storage.ScrubFinding{Kind: findingOrphan, Backend: name, StoragePath: object.Key, Size: object.Size}
+	referenced, err := s.isReferenced(name, object.Key)
+	if err != nil {
+		finding.Detail = fmt.Sprintf("failed to check references: %v", err)
+		return finding, true
+	}
+	if referenced {
+		return nil, false
+	}
+	if options.dryRun {
+		return finding, true
+	}
+
+	switch options.action {
+	case scrubRemove:
+		if err := backend.Delete(ctx, object.Key); err != nil && !isNotExist(err) {
+			finding.Detail = fmt.Sprintf("failed to remove: %v", err)
+			return finding, true
+		}
+		if err := deleteDataKey(s.db, name, object.Key); err != nil {
+			log.Printf("Failed to delete data key of %s: %v", object.Key, err)
+		}
+		finding.Action = "removed"
+	default:
+		// 隔离的对象保留数据密钥，加密的对象仍可恢复
+		target := path.Join(quarantine, object.Key)
+		if err := backend.Move(ctx, object.Key, target); err != nil {
+			finding.Detail = fmt.Sprintf("failed to quarantine: %v", err)
+			return finding, true
+		}
+		if err := s.db.Model(&DataKey{}).Where("backend = ? AND storage_path = ?", name, object.Key).
+			Update("storage_path", target).Error; err != nil {
+			log.Printf("Failed to move data key of %s: %v", object.Key, err)
+		}
+		finding.Action = "quarantined"
+		finding.Detail = "moved to " + target
+	}
+	return finding, true
+}
+
+// verifyObject 读取对象内容校验大小和SHA-256，加密的对象解密后校验
+// 返回问题类型和说明，内容正确时问题类型为空
+func (s *StorageService) verifyObject(ctx context.Context, backend, key string, ref *scrubRef) (string, string) {
+	reader, err := s.openObject(ctx, &FileInfo{Backend: backend, StoragePath: key, Size: ref.size}, 0, 0)
+	if err != nil {
+		return findingUnreadable, err.Error()
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, reader)
+	if err != nil {
+		return findingUnreadable, err.Error()
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != ref.checksum {
+		return findingChecksumMismatch, fmt.Sprintf("sha256 %s, want %s", sum, ref.checksum)
+	}
+	if n != ref.size {
+		return findingChecksumMismatch, fmt.Sprintf("size %d, want %d", n, ref.size)
+	}
+	return "", ""
+}
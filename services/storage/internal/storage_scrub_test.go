@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStorageScrub(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "uploads")
+	service := NewStorageService()
+	if err := service.ConfigureStorage(StorageConfig{Active: backendLocal, Local: LocalStorageConfig{Path: root}}); err != nil {
+		t.Fatalf("ConfigureStorage: %v", err)
+	}
+	if err := service.ConfigureDatabase(SQLiteConfig{Path: filepath.Join(t.TempDir(), "storage.db")}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := service.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	upload := func(content string) *storage.FileInfo {
+		t.Helper()
+		stream := &fakeUploadStream{ctx: ctx, requests: []*storage.UploadFileRequest{
+			{Filename: "note.txt", UserId: "user-1", Chunk: []byte(content)},
+		}}
+		if err := service.UploadFile(stream); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		return stream.response.FileInfo
+	}
+	objectPath := func(key string) string {
+		return filepath.Join(root, filepath.FromSlash(key))
+	}
+	write := func(key, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(objectPath(key)), 0755)
+		if err := os.WriteFile(objectPath(key), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", key, err)
+		}
+	}
+
+	healthy := upload("healthy")
+	missing := upload("missing")
+	corrupt := upload("corrupt")
+	os.Remove(objectPath(missing.StoragePath))
+	write(corrupt.StoragePath, "tampered")
+	write("blobs/ab/cd/abcd", "orphan blob")
+	write("blobs/staging/interrupted", "orphan staging")
+
+	kinds := func(report *storage.ScrubReport) map[string]string {
+		found := make(map[string]string)
+		for _, finding := range report.Findings {
+			found[finding.StoragePath] = finding.Kind
+		}
+		return found
+	}
+
+	// 默认的宽限期内刚写入的对象不视为孤立对象
+	report, err := service.Scrub(ctx, &storage.ScrubRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("Scrub: %v", err)
+	}
+	if kind, ok := kinds(report)["blobs/ab/cd/abcd"]; ok {
+		t.Errorf("recent object reported as %s during the grace period", kind)
+	}
+
+	report, err = service.scrub(ctx, scrubOptions{dryRun: true, action: scrubQuarantine, verify: true})
+	if err != nil {
+		t.Fatalf("scrub: %v", err)
+	}
+	want := map[string]string{
+		missing.StoragePath:         findingMissing,
+		corrupt.StoragePath:         findingChecksumMismatch,
+		"blobs/ab/cd/abcd":          findingOrphan,
+		"blobs/staging/interrupted": findingOrphan,
+	}
+	found := kinds(report)
+	for key, kind := range want {
+		if found[key] != kind {
+			t.Errorf("finding for %s = %q, want %q", key, found[key], kind)
+		}
+	}
+	if len(found) != len(want) {
+		t.Errorf("scrub reported %d findings, want %d: %v", len(found), len(want), found)
+	}
+	if _, ok := found[healthy.StoragePath]; ok {
+		t.Errorf("healthy file %s was reported", healthy.StoragePath)
+	}
+	if _, err := os.Stat(objectPath("blobs/ab/cd/abcd")); err != nil {
+		t.Errorf("dry run touched the orphan: %v", err)
+	}
+	if last, err := service.GetScrubReport(ctx, &storage.GetScrubReportRequest{}); err != nil || last != report {
+		t.Errorf("GetScrubReport = %v, %v, want the last report", last, err)
+	}
+
+	// 隔离孤立对象后再次巡检不会重复报告
+	report, err = service.scrub(ctx, scrubOptions{action: scrubQuarantine})
+	if err != nil {
+		t.Fatalf("scrub: %v", err)
+	}
+	var quarantined []string
+	for _, finding := range report.Findings {
+		if finding.Kind == findingOrphan && finding.Action != "quarantined" {
+			t.Errorf("orphan %s was not quarantined: %s", finding.StoragePath, finding.Detail)
+		}
+		if finding.Action == "quarantined" {
+			quarantined = append(quarantined, strings.TrimPrefix(finding.Detail, "moved to "))
+		}
+	}
+	sort.Strings(quarantined)
+	if len(quarantined) != 2 || !strings.HasPrefix(quarantined[0], quarantinePrefix+"/") {
+		t.Fatalf("quarantined = %v, want both orphans under %s", quarantined, quarantinePrefix)
+	}
+	if _, err := os.Stat(objectPath(quarantined[0])); err != nil {
+		t.Errorf("quarantined object is missing: %v", err)
+	}
+	report, err = service.scrub(ctx, scrubOptions{action: scrubRemove})
+	if err != nil {
+		t.Fatalf("scrub: %v", err)
+	}
+	for _, finding := range report.Findings {
+		if finding.Kind == findingOrphan {
+			t.Errorf("orphan %s reported again after quarantine", finding.StoragePath)
+		}
+	}
+
+	// 删除内容已丢失的文件时删除元数据，但不报告成功
+	_, err = service.DeleteFile(ctx, &storage.DeleteFileRequest{FileId: missing.Id, UserId: "user-1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteFile with missing content: error = %v, want NotFound", err)
+	}
+	if _, err := service.GetFileInfo(ctx, &storage.GetFileInfoRequest{FileId: missing.Id, UserId: "user-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("metadata of the deleted file still exists: %v", err)
+	}
+	if _, err := service.DeleteFile(ctx, &storage.DeleteFileRequest{FileId: healthy.Id, UserId: "user-1"}); err != nil {
+		t.Errorf("DeleteFile: %v", err)
+	}
+
+	if _, err := service.Scrub(ctx, &storage.ScrubRequest{Action: "shred"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Scrub with unknown action: error = %v, want InvalidArgument", err)
+	}
+}
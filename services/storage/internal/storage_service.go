@@ -2,46 +2,44 @@ package internal
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"strings"
 	"sync"
-	"time"
 
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
 	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
+// downloadChunkSize 下载时每个分块的大小
+const downloadChunkSize = 1 << 20
+
 // LocalStorageConfig 本地存储配置
 type LocalStorageConfig struct {
 	Path      string
 	URLPrefix string
 }
 
-// FileInfo 文件信息模型
-type FileInfo struct {
-	ID          string            `json:"id"`
-	Filename    string            `json:"filename"`
-	ContentType string            `json:"content_type"`
-	Size        int64             `json:"size"`
-	UserID      string            `json:"user_id"`
-	StoragePath string            `json:"storage_path"`
-	CreatedAt   string            `json:"created_at"`
-	Metadata    map[string]string `json:"metadata"`
-}
-
 // StorageService 存储服务实现
 type StorageService struct {
 	storage.UnimplementedStorageServiceServer
 	common.UnimplementedHealthCheckServiceServer
 
-	config LocalStorageConfig
-	mu     sync.RWMutex
+	config   StorageConfig
+	backends map[string]Backend
+	keyring  *keyring // 未配置主密钥时为nil，新文件以明文保存
+	signer   *urlSigner
+	db       *gorm.DB
+	mu       sync.RWMutex
+	blobMu   sync.Mutex // 串行化内容对象引用计数的变更和配额检查
+	scrubMu  sync.Mutex // 同一时间只运行一次巡检
+
+	lastScrub *storage.ScrubReport // 最近一次巡检的报告
 }
 
 // NewStorageService 创建存储服务实例
@@ -51,103 +49,207 @@ func NewStorageService() *StorageService {
 
 // ConfigureLocalStorage 配置本地存储
 func (s *StorageService) ConfigureLocalStorage(config LocalStorageConfig) error {
+	return s.ConfigureStorage(StorageConfig{Active: backendLocal, Local: config})
+}
+
+// ConfigureStorage 配置存储后端，新文件写入Active指定的后端
+// 配置了本地路径时始终启用本地后端，以便读取切换后端之前上传的文件
+func (s *StorageService) ConfigureStorage(config StorageConfig) error {
+	if config.Active == "" {
+		config.Active = backendLocal
+	}
+
+	backends := make(map[string]Backend)
+	if config.Local.Path != "" {
+		local, err := newLocalBackend(config.Local)
+		if err != nil {
+			return err
+		}
+		backends[backendLocal] = local
+	}
+	if config.Active == backendS3 {
+		s3, err := newS3Backend(config.S3)
+		if err != nil {
+			return err
+		}
+		backends[backendS3] = s3
+	}
+	if _, ok := backends[config.Active]; !ok {
+		return fmt.Errorf("unsupported storage backend: %s", config.Active)
+	}
+	switch config.Scrub.Action {
+	case "", scrubReport, scrubQuarantine, scrubRemove:
+	default:
+		return fmt.Errorf("unsupported scrub action: %s", config.Scrub.Action)
+	}
+	ring, err := loadKeyring(config.Encryption)
+	if err != nil {
+		return err
+	}
+	signer, err := newURLSigner(config.Local.URLPrefix, config.SignedURL)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
 	s.config = config
+	s.backends = backends
+	s.keyring = ring
+	s.signer = signer
+	return nil
+}
+
+// activeBackend 返回写入新文件的后端
+func (s *StorageService) activeBackend() (string, Backend) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.Active, s.backends[s.config.Active]
+}
 
-	// 确保存储目录存在
-	return os.MkdirAll(config.Path, 0755)
+// backendFor 返回保存文件的后端
+func (s *StorageService) backendFor(fileInfo *FileInfo) (Backend, error) {
+	s.mu.RLock()
+	backend, ok := s.backends[fileInfo.Backend]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "Storage backend %s is not configured", fileInfo.Backend)
+	}
+	return backend, nil
 }
 
 // UploadFile 上传文件（支持流式上传）
+// 文件块通过管道流式写入存储后端，不在内存或本地磁盘中缓存整个文件
 func (s *StorageService) UploadFile(stream storage.StorageService_UploadFileServer) error {
-	// 初始化文件信息
-	var fileInfo FileInfo
-	var fileID string
-	var file *os.File
-	var totalSize int64
-	var isFirstChunk = true
-
-	defer func() {
-		if file != nil {
-			file.Close()
-		}
-	}()
+	ctx := stream.Context()
+	backendName, backend := s.activeBackend()
 
-	// 接收文件流
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			// 文件接收完成
-			break
+	// 第一个文件块包含文件信息
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Errorf(codes.InvalidArgument, "No file data received")
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to receive file chunk: %v", err)
+	}
+	filename, err := normalizeUpload(req.UserId, req.Filename)
+	if err != nil {
+		return err
+	}
+
+	// 初始化文件信息，内容先写入暂存位置，得到校验和后再保存为内容对象
+	fileInfo := FileInfo{
+		ID:       uuid.New().String(),
+		Filename: filename,
+		UserID:   req.UserId,
+		Backend:  backendName,
+		Metadata: req.Metadata,
+		Export:   req.Export,
+	}
+	staged := stagingKey()
+	key, err := s.newObjectKey()
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to generate data key: %v", err)
+	}
+
+	// 文件类型确认之前暂存文件头，通过检查后才开始写入存储后端
+	// JPEG暂存到包含完整EXIF段，清除GPS信息后再写入，校验和按写入的内容计算
+	policy, err := s.uploadPolicyFor(req.UserId, req.Export)
+	if err != nil {
+		return err
+	}
+	hasher := sha256.New()
+	sniff := &sniffWriter{}
+	var (
+		pending []byte
+		writer  io.Writer
+		pw      *io.PipeWriter
+		putDone chan error
+	)
+	// startPut 检查文件类型并启动后端写入，后端在独立的goroutine中从管道读取内容
+	startPut := func() error {
+		if err := policy.checkType(sniff.data); err != nil {
+			return err
 		}
+		var pr *io.PipeReader
+		pr, pw = io.Pipe()
+		content, err := key.seal(pr)
 		if err != nil {
-			return status.Errorf(codes.Internal, "Failed to receive file chunk: %v", err)
+			return status.Errorf(codes.Internal, "Failed to encrypt file: %v", err)
 		}
+		putDone = make(chan error, 1)
+		go func() {
+			_, err := backend.Put(ctx, staged, content)
+			pr.CloseWithError(err)
+			putDone <- err
+		}()
+		writer = io.MultiWriter(pw, hasher)
+		sanitizeHead(pending)
+		writer.Write(pending)
+		pending = nil
+		return nil
+	}
+	// abort 中止上传，后端收到错误后删除已写入的部分内容
+	abort := func(err error) error {
+		if pw != nil {
+			pw.CloseWithError(err)
+			<-putDone
+		}
+		return err
+	}
 
-		if isFirstChunk {
-			// 第一次接收，初始化文件信息
-			fileID = uuid.New().String()
-			fileInfo = FileInfo{
-				ID:          fileID,
-				Filename:    req.Filename,
-				ContentType: req.ContentType,
-				UserID:      req.UserId,
-				Metadata:    req.Metadata,
-				CreatedAt:   time.Now().Format(time.RFC3339),
-			}
-
-			// 创建存储目录结构（按用户ID和日期）
-			userDir := filepath.Join(s.config.Path, req.UserId)
-			if err := os.MkdirAll(userDir, 0755); err != nil {
-				return status.Errorf(codes.Internal, "Failed to create user directory: %v", err)
+	// 写入文件内容，同时保留文件头用于类型检测
+	for {
+		fileInfo.Size += int64(len(req.Chunk))
+		if err := policy.checkSize(fileInfo.Size); err != nil {
+			return abort(err)
+		}
+		sniff.Write(req.Chunk)
+
+		if writer == nil {
+			pending = append(pending, req.Chunk...)
+			if len(pending) >= headLength(pending) {
+				if err := startPut(); err != nil {
+					return err
+				}
 			}
+		} else if _, err := writer.Write(req.Chunk); err != nil {
+			// 后端写入失败，错误原因由putDone返回
+			break
+		}
 
-			// 生成文件存储路径
-			fileExt := filepath.Ext(req.Filename)
-			storagePath := filepath.Join(userDir, fmt.Sprintf("%s%s", fileID, fileExt))
-			fileInfo.StoragePath = storagePath
-
-			// 创建文件
-			file, err = os.Create(storagePath)
-			if err != nil {
-				return status.Errorf(codes.Internal, "Failed to create file: %v", err)
+		// 接收下一个文件块
+		if req, err = stream.Recv(); err != nil {
+			if err != io.EOF {
+				return abort(status.Errorf(codes.Internal, "Failed to receive file chunk: %v", err))
 			}
-
-			isFirstChunk = false
+			break
 		}
-
-		// 写入文件内容
-		if _, err := file.Write(req.Chunk); err != nil {
-			return status.Errorf(codes.Internal, "Failed to write file chunk: %v", err)
+	}
+	// 文件小于暂存长度时，在接收完成后检查
+	if writer == nil {
+		if err := startPut(); err != nil {
+			return err
 		}
-
-		totalSize += int64(len(req.Chunk))
+	}
+	pw.Close()
+	if err := <-putDone; err != nil {
+		return status.Errorf(codes.Internal, "Failed to write file: %v", err)
 	}
 
-	// 更新文件大小
-	fileInfo.Size = totalSize
-
-	// 关闭文件
-	if err := file.Close(); err != nil {
-		return status.Errorf(codes.Internal, "Failed to close file: %v", err)
+	// 保存为内容对象并记录文件元数据
+	fileInfo.ContentType = detectContentType(sniff.data, fileInfo.Filename)
+	fileInfo.Checksum = hex.EncodeToString(hasher.Sum(nil))
+	if err := s.commitFile(ctx, &fileInfo, backend, staged, key, nil); err != nil {
+		return err
 	}
-	file = nil
+
+	s.generateRenditions(fileInfo)
 
 	// 返回上传结果
 	return stream.SendAndClose(&storage.UploadFileResponse{
-		FileInfo: &storage.FileInfo{
-			Id:          fileInfo.ID,
-			Filename:    fileInfo.Filename,
-			ContentType: fileInfo.ContentType,
-			Size:        fileInfo.Size,
-			UserId:      fileInfo.UserID,
-			StoragePath: fileInfo.StoragePath,
-			CreatedAt:   fileInfo.CreatedAt,
-			Metadata:    fileInfo.Metadata,
-		},
-		UploadedBytes: totalSize,
+		FileInfo:      fileInfoToProto(&fileInfo),
+		UploadedBytes: fileInfo.Size,
 		Completed:     true,
 	})
 }
@@ -155,85 +257,79 @@ func (s *StorageService) UploadFile(stream storage.StorageService_UploadFileServ
 // DownloadFile 下载文件（支持流式下载）
 func (s *StorageService) DownloadFile(req *storage.DownloadFileRequest, stream storage.StorageService_DownloadFileServer) error {
 	// 获取文件信息
-	fileInfo, err := s.GetFileInfo(context.Background(), &storage.GetFileInfoRequest{
-		FileId: req.FileId,
-		UserId: req.UserId,
-	})
+	fileInfo, err := s.resolveFile(stream.Context(), req.FileId, req.UserId, req.Variant)
 	if err != nil {
 		return err
 	}
 
-	// 打开文件
-	file, err := os.Open(fileInfo.StoragePath)
+	// 计算读取范围，length为0时读取到文件末尾
+	if req.Offset < 0 || req.Length < 0 {
+		return status.Errorf(codes.InvalidArgument, "Offset and length must not be negative")
+	}
+	if req.Offset > fileInfo.Size {
+		return status.Errorf(codes.OutOfRange, "Offset %d exceeds file size %d", req.Offset, fileInfo.Size)
+	}
+	length := fileInfo.Size - req.Offset
+	if req.Length > 0 && req.Length < length {
+		length = req.Length
+	}
+
+	info := fileInfoToProto(fileInfo)
+	if length == 0 {
+		return stream.Send(&storage.DownloadFileResponse{IsLastChunk: true, FileInfo: info})
+	}
+
+	// 打开文件，加密的文件在读取时解密
+	reader, err := s.openObject(stream.Context(), fileInfo, req.Offset, length)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if isNotExist(err) {
 			return status.Errorf(codes.NotFound, "File not found")
 		}
 		return status.Errorf(codes.Internal, "Failed to open file: %v", err)
 	}
-	defer file.Close()
+	defer reader.Close()
 
 	// 分块读取文件并发送
-	chunkSize := 1024 * 1024 // 1MB
-	buffer := make([]byte, chunkSize)
-	isLastChunk := false
-
-	for {
-		// 读取文件块
-		n, err := file.Read(buffer)
+	buffer := make([]byte, downloadChunkSize)
+	for remaining := length; remaining > 0; {
+		n, err := io.ReadFull(reader, buffer[:min(int64(len(buffer)), remaining)])
 		if err != nil {
-			if err == io.EOF {
-				isLastChunk = true
-				break
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return status.Errorf(codes.DataLoss, "File is shorter than its recorded size")
 			}
 			return status.Errorf(codes.Internal, "Failed to read file: %v", err)
 		}
+		remaining -= int64(n)
 
-		// 发送文件块
 		if err := stream.Send(&storage.DownloadFileResponse{
 			Chunk:       buffer[:n],
-			IsLastChunk: n < chunkSize,
-			FileInfo:    fileInfo,
+			IsLastChunk: remaining == 0,
+			FileInfo:    info,
 		}); err != nil {
 			return status.Errorf(codes.Internal, "Failed to send file chunk: %v", err)
 		}
 	}
-
-	// 确保最后一块被发送
-	if !isLastChunk {
-		if err := stream.Send(&storage.DownloadFileResponse{
-			Chunk:       []byte{},
-			IsLastChunk: true,
-			FileInfo:    fileInfo,
-		}); err != nil {
-			return status.Errorf(codes.Internal, "Failed to send final file chunk: %v", err)
-		}
-	}
-
 	return nil
 }
 
 // DeleteFile 删除文件
 func (s *StorageService) DeleteFile(ctx context.Context, req *storage.DeleteFileRequest) (*common.Response, error) {
 	// 获取文件信息
-	fileInfo, err := s.GetFileInfo(ctx, &storage.GetFileInfoRequest{
-		FileId: req.FileId,
-		UserId: req.UserId,
-	})
+	fileInfo, err := s.findFile(req.FileId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := s.backendFor(fileInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	// 删除文件
-	if err := os.Remove(fileInfo.StoragePath); err != nil {
-		if os.IsNotExist(err) {
-			return &common.Response{
-				Success: true,
-				Message: "File not found, considered deleted",
-				Code:    200,
-			}, nil
-		}
-		return nil, status.Errorf(codes.Internal, "Failed to delete file: %v", err)
+	// 删除图片版本，再删除元数据并释放内容对象，内容对象没有其他引用时才会删除
+	if err := s.deleteRenditions(ctx, fileInfo); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to delete file variants: %v", err)
+	}
+	if err := s.deleteFileContent(ctx, fileInfo, backend); err != nil {
+		return nil, err
 	}
 
 	return &common.Response{
@@ -245,59 +341,23 @@ func (s *StorageService) DeleteFile(ctx context.Context, req *storage.DeleteFile
 
 // GetFileInfo 获取文件信息
 func (s *StorageService) GetFileInfo(ctx context.Context, req *storage.GetFileInfoRequest) (*storage.FileInfo, error) {
-	s.mu.RLock()
-	config := s.config
-	s.mu.RUnlock()
-
-	// 搜索文件
-	userDir := filepath.Join(config.Path, req.UserId)
-
-	// 遍历用户目录下的所有文件，查找匹配的文件ID
-	files, err := os.ReadDir(userDir)
+	fileInfo, err := s.resolveFile(ctx, req.FileId, req.UserId, req.Variant)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, status.Errorf(codes.NotFound, "User directory not found")
-		}
-		return nil, status.Errorf(codes.Internal, "Failed to read user directory: %v", err)
+		return nil, err
 	}
+	return fileInfoToProto(fileInfo), nil
+}
 
-	var foundFile string
-	for _, file := range files {
-		if !file.IsDir() {
-			filename := file.Name()
-			fileID := strings.TrimSuffix(filename, filepath.Ext(filename))
-			if fileID == req.FileId {
-				foundFile = filename
-				break
-			}
+// findFile 按文件ID和所有者查询文件元数据
+func (s *StorageService) findFile(fileID, userID string) (*FileInfo, error) {
+	var fileInfo FileInfo
+	if err := s.db.First(&fileInfo, "id = ? AND user_id = ?", fileID, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "File not found")
 		}
+		return nil, status.Errorf(codes.Internal, "Failed to query file: %v", err)
 	}
-
-	if foundFile == "" {
-		return nil, status.Errorf(codes.NotFound, "File not found")
-	}
-
-	// 获取文件信息
-	filePath := filepath.Join(userDir, foundFile)
-	fileStat, err := os.Stat(filePath)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to get file stat: %v", err)
-	}
-
-	// 解析文件名获取原始文件名（简单实现，实际可能需要存储在数据库中）
-	// 这里假设文件名格式为：{fileID}.{original_extension}
-	originalFilename := fmt.Sprintf("file_%s%s", req.FileId, filepath.Ext(foundFile))
-
-	return &storage.FileInfo{
-		Id:          req.FileId,
-		Filename:    originalFilename,
-		ContentType: "application/octet-stream", // 简单实现，实际应根据文件类型设置
-		Size:        fileStat.Size(),
-		UserId:      req.UserId,
-		StoragePath: filePath,
-		CreatedAt:   fileStat.ModTime().Format(time.RFC3339),
-		Metadata:    map[string]string{},
-	}, nil
+	return &fileInfo, nil
 }
 
 // Check 健康检查
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// usage 统计用户文件的总大小和数量
+// 内容相同的文件在存储后端只保存一份，但分别计入各自所有者的用量，图片版本不计入用量
+// 账本导出文件由服务端生成并在保留时间到期后删除，不计入用量，避免在用户不知情时占满配额
+func (s *StorageService) usage(userID string) (int64, int64, error) {
+	var result struct {
+		Used  int64
+		Count int64
+	}
+	err := s.db.Model(&FileInfo{}).
+		Select("COALESCE(SUM(size), 0) AS used, COUNT(*) AS count").
+		Where("user_id = ? AND export = ?", userID, false).
+		Scan(&result).Error
+	return result.Used, result.Count, err
+}
+
+// GetUsage 获取用户的存储用量和配额
+func (s *StorageService) GetUsage(ctx context.Context, req *storage.GetUsageRequest) (*storage.StorageUsage, error) {
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "User ID is required")
+	}
+	used, count, err := s.usage(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to query storage usage: %v", err)
+	}
+
+	s.mu.RLock()
+	quota := s.config.UserQuota
+	s.mu.RUnlock()
+	return &storage.StorageUsage{
+		UserId:     req.UserId,
+		UsedBytes:  used,
+		QuotaBytes: quota,
+		FileCount:  count,
+	}, nil
+}
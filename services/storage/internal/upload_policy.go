@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadPolicy 上传文件的大小、类型和存储配额限制
+type uploadPolicy struct {
+	maxFileSize      int64    // 最大文件大小（字节），0表示不限制
+	allowedFileTypes []string // 允许的MIME类型，支持"image/*"形式的通配符，为空表示不限制
+	userID           string
+	quota            int64 // 用户的存储配额（字节），0表示不限制
+	used             int64 // 检查时用户已使用的字节数
+}
+
+// uploadPolicyFor 返回适用于该次上传的限制，存储配额按查询时的用量计算
+// 账本导出文件由网关生成，不是用户上传的内容，不受限制；上传元数据由客户端提供，不作为判断依据
+func (s *StorageService) uploadPolicyFor(userID string, export bool) (uploadPolicy, error) {
+	if export {
+		return uploadPolicy{}, nil
+	}
+
+	s.mu.RLock()
+	policy := uploadPolicy{
+		maxFileSize:      s.config.MaxFileSize,
+		allowedFileTypes: s.config.AllowedFileTypes,
+		userID:           userID,
+		quota:            s.config.UserQuota,
+	}
+	s.mu.RUnlock()
+
+	if policy.quota > 0 {
+		used, _, err := s.usage(userID)
+		if err != nil {
+			return policy, status.Errorf(codes.Internal, "Failed to query storage usage: %v", err)
+		}
+		policy.used = used
+	}
+	return policy, nil
+}
+
+// checkSize 检查已接收的字节数是否超过大小限制和剩余的存储配额
+func (p uploadPolicy) checkSize(size int64) error {
+	if p.maxFileSize > 0 && size > p.maxFileSize {
+		return status.Errorf(codes.ResourceExhausted, "File exceeds the maximum size of %d bytes", p.maxFileSize)
+	}
+	if p.quota > 0 && p.used+size > p.quota {
+		return quotaExceededError(p.userID, p.used, p.quota)
+	}
+	return nil
+}
+
+// quotaExceededError 返回超出存储配额的错误，附带QuotaFailure详情以便与单个文件过大区分
+func quotaExceededError(userID string, used, quota int64) error {
+	st := status.Newf(codes.ResourceExhausted, "Storage quota exceeded: %d of %d bytes used", used, quota)
+	detailed, err := st.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     "user:" + userID,
+			Description: fmt.Sprintf("Storage quota of %d bytes exceeded", quota),
+		}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// checkType 根据文件头的魔数检测文件类型，不信任客户端声明的类型和扩展名
+func (p uploadPolicy) checkType(head []byte) error {
+	if len(p.allowedFileTypes) == 0 {
+		return nil
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	for _, allowed := range p.allowedFileTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == detected {
+			return nil
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(detected, prefix+"/") {
+			return nil
+		}
+	}
+	return status.Errorf(codes.InvalidArgument, "File type %s is not allowed", detected)
+}
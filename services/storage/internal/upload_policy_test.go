@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newPolicyTestService 创建限制文件大小、类型和配额的存储服务
+func newPolicyTestService(t *testing.T) *StorageService {
+	t.Helper()
+	service := NewStorageService()
+	if err := service.ConfigureStorage(StorageConfig{
+		Active:           backendLocal,
+		Local:            LocalStorageConfig{Path: filepath.Join(t.TempDir(), "uploads")},
+		MaxFileSize:      16,
+		AllowedFileTypes: []string{"image/png"},
+		UserQuota:        32,
+	}); err != nil {
+		t.Fatalf("ConfigureStorage: %v", err)
+	}
+	if err := service.ConfigureDatabase(SQLiteConfig{Path: filepath.Join(t.TempDir(), "storage.db")}); err != nil {
+		t.Fatalf("ConfigureDatabase: %v", err)
+	}
+	if err := service.InitDatabase(); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	return service
+}
+
+func TestUploadPolicyExportExemption(t *testing.T) {
+	service := newPolicyTestService(t)
+	content := []byte(strings.Repeat("date,amount\n", 8))
+
+	tests := []struct {
+		name     string
+		request  *storage.UploadFileRequest
+		wantCode codes.Code
+	}{
+		{
+			name:     "export metadata from a client is not trusted",
+			request:  &storage.UploadFileRequest{UserId: "user-1", Filename: "ledger.csv", Chunk: content, Metadata: map[string]string{"source": "export"}},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "export uploads skip the size and type limits",
+			request:  &storage.UploadFileRequest{UserId: "user-1", Filename: "ledger.csv", Chunk: content, Export: true},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeUploadStream{ctx: context.Background(), requests: []*storage.UploadFileRequest{tt.request}}
+			err := service.UploadFile(stream)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("UploadFile code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+		})
+	}
+
+	// 导出文件不计入存储用量
+	usage, err := service.GetUsage(context.Background(), &storage.GetUsageRequest{UserId: "user-1"})
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if usage.UsedBytes != 0 || usage.FileCount != 0 {
+		t.Errorf("usage = %d bytes in %d files, want exports excluded", usage.UsedBytes, usage.FileCount)
+	}
+}
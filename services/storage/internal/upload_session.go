@@ -0,0 +1,390 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/common"
+	"github.com/fishdivinity/BeeCount-Cloud/common/proto/storage"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	// uploadSessionTTL 上传会话的有效期，每次写入后顺延
+	uploadSessionTTL = 24 * time.Hour
+	// sessionCleanupInterval 清理过期上传会话的间隔
+	sessionCleanupInterval = time.Hour
+)
+
+// UploadSession 上传会话模型，未完成的内容暂存在会话目录中
+type UploadSession struct {
+	ID          string            `gorm:"type:varchar(36);primaryKey"`
+	UserID      string            `gorm:"type:varchar(36);not null;index"`
+	Filename    string            `gorm:"type:varchar(255)"`
+	ContentType string            `gorm:"type:varchar(100)"` // 客户端声明的类型，完成时以检测结果为准
+	Size        int64             `gorm:"not null"`
+	Offset      int64             `gorm:"not null;default:0"`
+	Metadata    map[string]string `gorm:"type:json;serializer:json"`
+	ExpiresAt   time.Time         `gorm:"not null;index"`
+	CreatedAt   time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `gorm:"autoUpdateTime"`
+}
+
+// sessionLocks 正在写入的上传会话，同一会话同时只允许一个写入流
+var sessionLocks sync.Map
+
+// CreateUploadSession 创建上传会话
+func (s *StorageService) CreateUploadSession(ctx context.Context, req *storage.CreateUploadSessionRequest) (*storage.UploadSession, error) {
+	filename, err := normalizeUpload(req.UserId, req.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Upload size must be positive")
+	}
+	// 提前拒绝超过大小限制的上传，避免客户端传输后才失败
+	policy, err := s.uploadPolicyFor(req.UserId, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.checkSize(req.Size); err != nil {
+		return nil, err
+	}
+
+	session := UploadSession{
+		ID:          uuid.New().String(),
+		UserID:      req.UserId,
+		Filename:    filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Metadata:    req.Metadata,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL),
+	}
+	dir := s.sessionDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to create session directory: %v", err)
+	}
+	file, err := os.Create(sessionFilePath(dir, session.ID))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to create session file: %v", err)
+	}
+	file.Close()
+
+	if err := s.db.Create(&session).Error; err != nil {
+		os.Remove(sessionFilePath(dir, session.ID))
+		return nil, status.Errorf(codes.Internal, "Failed to create upload session: %v", err)
+	}
+	return uploadSessionToProto(&session), nil
+}
+
+// UploadChunk 向上传会话写入文件块
+// 写入位置必须等于已提交的字节数；流中断时已写入的内容仍会提交，客户端可从新的位置续传
+func (s *StorageService) UploadChunk(stream storage.StorageService_UploadChunkServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Errorf(codes.InvalidArgument, "No chunk received")
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to receive chunk: %v", err)
+	}
+
+	session, err := s.findUploadSession(req.SessionId, req.UserId)
+	if err != nil {
+		return err
+	}
+	if _, busy := sessionLocks.LoadOrStore(session.ID, struct{}{}); busy {
+		return status.Errorf(codes.Aborted, "Upload session is being written by another request")
+	}
+	defer sessionLocks.Delete(session.ID)
+
+	if req.Offset != session.Offset {
+		return status.Errorf(codes.FailedPrecondition, "Offset mismatch: expected %d, got %d", session.Offset, req.Offset)
+	}
+
+	// 丢弃上次中断时写入但未提交的内容
+	file, err := os.OpenFile(sessionFilePath(s.sessionDir(), session.ID), os.O_WRONLY, 0)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to open session file: %v", err)
+	}
+	defer file.Close()
+	if err := file.Truncate(session.Offset); err != nil {
+		return status.Errorf(codes.Internal, "Failed to truncate session file: %v", err)
+	}
+	if _, err := file.Seek(session.Offset, io.SeekStart); err != nil {
+		return status.Errorf(codes.Internal, "Failed to seek session file: %v", err)
+	}
+
+	offset := session.Offset
+	var streamErr error
+	for {
+		if offset+int64(len(req.Chunk)) > session.Size {
+			streamErr = status.Errorf(codes.InvalidArgument, "Chunk exceeds the declared upload size of %d bytes", session.Size)
+			break
+		}
+		if _, err := file.Write(req.Chunk); err != nil {
+			streamErr = status.Errorf(codes.Internal, "Failed to write chunk: %v", err)
+			break
+		}
+		offset += int64(len(req.Chunk))
+
+		if req, err = stream.Recv(); err != nil {
+			if err != io.EOF {
+				streamErr = status.Errorf(codes.Internal, "Failed to receive chunk: %v", err)
+			}
+			break
+		}
+	}
+
+	// 确保内容落盘后再提交写入位置
+	if err := file.Sync(); err != nil {
+		return status.Errorf(codes.Internal, "Failed to sync session file: %v", err)
+	}
+	session.Offset = offset
+	session.ExpiresAt = time.Now().Add(uploadSessionTTL)
+	if err := s.db.Model(session).Select("offset", "expires_at").Updates(session).Error; err != nil {
+		return status.Errorf(codes.Internal, "Failed to update upload session: %v", err)
+	}
+	if streamErr != nil {
+		return streamErr
+	}
+	return stream.SendAndClose(uploadSessionToProto(session))
+}
+
+// GetUploadSession 获取上传会话
+func (s *StorageService) GetUploadSession(ctx context.Context, req *storage.UploadSessionRequest) (*storage.UploadSession, error) {
+	session, err := s.findUploadSession(req.SessionId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return uploadSessionToProto(session), nil
+}
+
+// FinalizeUploadSession 完成上传会话，校验内容后写入存储后端并记录文件元数据
+func (s *StorageService) FinalizeUploadSession(ctx context.Context, req *storage.FinalizeUploadSessionRequest) (*storage.UploadFileResponse, error) {
+	if req.Sha256 == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "SHA-256 checksum is required")
+	}
+	session, err := s.findUploadSession(req.SessionId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if _, busy := sessionLocks.LoadOrStore(session.ID, struct{}{}); busy {
+		return nil, status.Errorf(codes.Aborted, "Upload session is being written by another request")
+	}
+	defer sessionLocks.Delete(session.ID)
+
+	if session.Offset != session.Size {
+		return nil, status.Errorf(codes.FailedPrecondition, "Upload is incomplete: %d of %d bytes received", session.Offset, session.Size)
+	}
+
+	sessionPath := sessionFilePath(s.sessionDir(), session.ID)
+	file, err := os.Open(sessionPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to open session file: %v", err)
+	}
+	defer file.Close()
+
+	// 校验内容，校验失败的会话无法续传，直接丢弃
+	hasher := sha256.New()
+	sniff := &sniffWriter{}
+	if _, err := io.Copy(io.MultiWriter(hasher, sniff), io.NewSectionReader(file, 0, session.Size)); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to read session file: %v", err)
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if !strings.EqualFold(checksum, req.Sha256) {
+		file.Close()
+		s.removeUploadSession(session)
+		return nil, status.Errorf(codes.InvalidArgument, "Checksum mismatch: expected %s, got %s", req.Sha256, checksum)
+	}
+	policy, err := s.uploadPolicyFor(session.UserID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.checkType(sniff.data); err != nil {
+		file.Close()
+		s.removeUploadSession(session)
+		return nil, err
+	}
+
+	// 清除文件头中的隐私信息后写入存储后端，校验和按写入的内容重新计算
+	head := make([]byte, min(session.Size, exifHeadLength))
+	if _, err := file.ReadAt(head, 0); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to read session file: %v", err)
+	}
+	sanitizeHead(head)
+	hasher.Reset()
+	content := io.TeeReader(io.MultiReader(
+		bytes.NewReader(head),
+		io.NewSectionReader(file, int64(len(head)), session.Size-int64(len(head))),
+	), hasher)
+
+	backendName, backend := s.activeBackend()
+	fileInfo := FileInfo{
+		ID:          uuid.New().String(),
+		Filename:    session.Filename,
+		ContentType: detectContentType(sniff.data, session.Filename),
+		Size:        session.Size,
+		UserID:      session.UserID,
+		Backend:     backendName,
+		Metadata:    session.Metadata,
+	}
+	staged := stagingKey()
+	key, err := s.newObjectKey()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to generate data key: %v", err)
+	}
+	sealed, err := key.seal(content)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to encrypt file: %v", err)
+	}
+	if _, err := backend.Put(ctx, staged, sealed); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to write file: %v", err)
+	}
+	fileInfo.Checksum = hex.EncodeToString(hasher.Sum(nil))
+
+	// 保存为内容对象，在同一事务中记录文件元数据并删除会话
+	err = s.commitFile(ctx, &fileInfo, backend, staged, key, func(tx *gorm.DB) error {
+		return tx.Delete(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Windows上无法删除仍处于打开状态的文件
+	file.Close()
+	os.Remove(sessionPath)
+	s.generateRenditions(fileInfo)
+
+	return &storage.UploadFileResponse{
+		FileInfo:      fileInfoToProto(&fileInfo),
+		UploadedBytes: fileInfo.Size,
+		Completed:     true,
+	}, nil
+}
+
+// CancelUploadSession 取消上传会话
+func (s *StorageService) CancelUploadSession(ctx context.Context, req *storage.UploadSessionRequest) (*common.Response, error) {
+	session, err := s.findUploadSession(req.SessionId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if _, busy := sessionLocks.LoadOrStore(session.ID, struct{}{}); busy {
+		return nil, status.Errorf(codes.Aborted, "Upload session is being written by another request")
+	}
+	defer sessionLocks.Delete(session.ID)
+
+	if err := s.removeUploadSession(session); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to cancel upload session: %v", err)
+	}
+	return &common.Response{
+		Success: true,
+		Message: "Upload session cancelled",
+		Code:    200,
+	}, nil
+}
+
+// RunSessionCleanup 定期清理过期的上传会话，直到ctx取消
+func (s *StorageService) RunSessionCleanup(ctx context.Context) {
+	ticker := time.NewTicker(sessionCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanupExpiredSessions()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanupExpiredSessions 删除过期的上传会话及其暂存内容
+func (s *StorageService) cleanupExpiredSessions() {
+	var sessions []UploadSession
+	if err := s.db.Where("expires_at < ?", time.Now()).Find(&sessions).Error; err != nil {
+		log.Printf("Failed to query expired upload sessions: %v", err)
+		return
+	}
+	for i := range sessions {
+		if _, busy := sessionLocks.LoadOrStore(sessions[i].ID, struct{}{}); busy {
+			continue
+		}
+		if err := s.removeUploadSession(&sessions[i]); err != nil {
+			log.Printf("Failed to remove expired upload session %s: %v", sessions[i].ID, err)
+		}
+		sessionLocks.Delete(sessions[i].ID)
+	}
+	if len(sessions) > 0 {
+		log.Printf("Removed %d expired upload sessions", len(sessions))
+	}
+}
+
+// findUploadSession 按会话ID和所有者查询未过期的上传会话
+func (s *StorageService) findUploadSession(sessionID, userID string) (*UploadSession, error) {
+	var session UploadSession
+	err := s.db.First(&session, "id = ? AND user_id = ? AND expires_at >= ?", sessionID, userID, time.Now()).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Errorf(codes.NotFound, "Upload session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to query upload session: %v", err)
+	}
+	return &session, nil
+}
+
+// removeUploadSession 删除上传会话记录及暂存内容
+func (s *StorageService) removeUploadSession(session *UploadSession) error {
+	if err := s.db.Delete(session).Error; err != nil {
+		return err
+	}
+	if err := os.Remove(sessionFilePath(s.sessionDir(), session.ID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// sessionDir 返回上传会话暂存目录
+func (s *StorageService) sessionDir() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.config.SessionPath != "" {
+		return s.config.SessionPath
+	}
+	return filepath.Join(os.TempDir(), "beecount-upload-sessions")
+}
+
+// sessionFilePath 返回会话暂存文件的路径
+func sessionFilePath(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".part")
+}
+
+// uploadSessionToProto 将上传会话模型转换为proto消息
+func uploadSessionToProto(session *UploadSession) *storage.UploadSession {
+	metadata := session.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return &storage.UploadSession{
+		Id:          session.ID,
+		UserId:      session.UserID,
+		Filename:    session.Filename,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		Offset:      session.Offset,
+		Metadata:    metadata,
+		CreatedAt:   session.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:   session.ExpiresAt.Format(time.RFC3339),
+	}
+}